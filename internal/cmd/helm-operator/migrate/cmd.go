@@ -0,0 +1,205 @@
+// Copyright 2026 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	rspb "helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	apitypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+
+	"github.com/operator-framework/operator-sdk/internal/helm/storage"
+	"github.com/operator-framework/operator-sdk/internal/helm/watches"
+)
+
+type migrateCmd struct {
+	from, to                     string
+	fromConnection, toConnection string
+	namespace                    string
+	watchesFile                  string
+	deleteSource                 bool
+
+	// clients returns the clients of the cluster containing the custom
+	// resources and the release records.
+	clients func() (client.Client, kubernetes.Interface, error)
+}
+
+func NewCmd() *cobra.Command {
+	return newCmd(&migrateCmd{clients: newClients})
+}
+
+func newCmd(c *migrateCmd) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate-storage",
+		Short: "Migrate Helm release records between release storage backends",
+		Long: `Copy the Helm release records of a namespace, or of all namespaces, from one
+release storage backend to another. Only the records of the releases of the
+custom resources watched by the operator are copied. Records that already exist
+in the target backend are overwritten. Stop the operator before migrating its
+release records, and restart it with the new backend once they are migrated.
+
+Release records stored in a database are not owned by their custom resource, so
+that records copied from Secrets or ConfigMaps to the sql or sqlite backends
+lose their owner references, and records copied to Secrets or ConfigMaps have none.
+`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			n, err := c.run(cmd.Context())
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Migrated %d release records from %q to %q\n", n, c.from, c.to)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&c.from, "from", string(storage.BackendSecret),
+		`Backend from which release records are copied: "secret", "configmap", "sql" or "sqlite"`)
+	cmd.Flags().StringVar(&c.to, "to", "",
+		`Backend to which release records are copied: "secret", "configmap", "sql" or "sqlite"`)
+	cmd.Flags().StringVar(&c.fromConnection, "from-connection", "",
+		"Connection string of the database of a sql or sqlite source backend. "+
+			"Defaults to the value of the "+storage.ConnectionStringEnvVar+" environment variable.")
+	cmd.Flags().StringVar(&c.toConnection, "to-connection", "",
+		"Connection string of the database of a sql or sqlite target backend. "+
+			"Defaults to the value of the "+storage.ConnectionStringEnvVar+" environment variable.")
+	cmd.Flags().StringVar(&c.namespace, "namespace", "",
+		"Namespace of the release records to migrate. Defaults to all namespaces.")
+	cmd.Flags().StringVar(&c.watchesFile, "watches-file", "./watches.yaml",
+		"Path to the watches file of the operator, whose custom resources own the release records to migrate")
+	cmd.Flags().BoolVar(&c.deleteSource, "delete-source", false,
+		"Delete release records from the source backend once copied")
+	return cmd
+}
+
+func (c *migrateCmd) run(ctx context.Context) (int, error) {
+	from, to := storage.Backend(c.from), storage.Backend(c.to)
+	if to == "" {
+		return 0, errors.New("--to is required")
+	}
+	if err := from.Validate(); err != nil {
+		return 0, fmt.Errorf("invalid --from: %w", err)
+	}
+	if err := to.Validate(); err != nil {
+		return 0, fmt.Errorf("invalid --to: %w", err)
+	}
+	if from == to && c.fromConnection == c.toConnection {
+		return 0, errors.New("--from and --to must be different backends")
+	}
+
+	ws, err := watches.Load(c.watchesFile)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load watches: %w", err)
+	}
+	cl, clientset, err := c.clients()
+	if err != nil {
+		return 0, err
+	}
+	owned, err := ownedReleases(ctx, cl, ws)
+	if err != nil {
+		return 0, err
+	}
+
+	source, err := drivers(from, c.fromConnection, clientset)
+	if err != nil {
+		return 0, err
+	}
+	target, err := drivers(to, c.toConnection, clientset)
+	if err != nil {
+		return 0, err
+	}
+	return storage.Migrate(source, target, c.namespace, owned, c.deleteSource)
+}
+
+// newClients returns the clients of the cluster of the kubeconfig.
+func newClients() (client.Client, kubernetes.Interface, error) {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get Kubernetes config: %w", err)
+	}
+	cl, err := client.New(cfg, client.Options{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+	return cl, clientset, nil
+}
+
+// ownedReleases returns a filter matching the release records of the
+// releases of the custom resources of ws.
+func ownedReleases(ctx context.Context, cl client.Client, ws []watches.Watch) (func(*rspb.Release) bool, error) {
+	releases := map[apitypes.NamespacedName]struct{}{}
+	for _, w := range ws {
+		naming, err := w.ReleaseNaming()
+		if err != nil {
+			return nil, err
+		}
+		crs := &unstructured.UnstructuredList{}
+		crs.SetGroupVersionKind(w.GroupVersionKind.GroupVersion().WithKind(w.Kind + "List"))
+		if err := cl.List(ctx, crs); err != nil {
+			return nil, fmt.Errorf("failed to list %s custom resources: %w", w.GroupVersionKind, err)
+		}
+		for i := range crs.Items {
+			name, err := naming.NameFor(&crs.Items[i])
+			if err != nil {
+				return nil, err
+			}
+			namespace, err := naming.NamespaceFor(&crs.Items[i])
+			if err != nil {
+				return nil, err
+			}
+			releases[apitypes.NamespacedName{Namespace: namespace, Name: name}] = struct{}{}
+		}
+	}
+	return func(rls *rspb.Release) bool {
+		_, ok := releases[apitypes.NamespacedName{Namespace: rls.Namespace, Name: rls.Name}]
+		return ok
+	}, nil
+}
+
+// drivers returns the storage drivers of backend b.
+func drivers(b storage.Backend, connectionString string, clientset kubernetes.Interface) (storage.Drivers, error) {
+	switch b {
+	case storage.BackendSQL, storage.BackendSQLite:
+		if connectionString == "" {
+			connectionString = os.Getenv(storage.ConnectionStringEnvVar)
+		}
+		db, err := storage.OpenSQL(b, connectionString, nil)
+		if err != nil {
+			return nil, err
+		}
+		return db.Driver, nil
+	case storage.BackendConfigMap:
+		return func(namespace string) (driver.Driver, error) {
+			return driver.NewConfigMaps(clientset.CoreV1().ConfigMaps(namespace)), nil
+		}, nil
+	default:
+		return func(namespace string) (driver.Driver, error) {
+			return driver.NewSecrets(clientset.CoreV1().Secrets(namespace)), nil
+		}, nil
+	}
+}
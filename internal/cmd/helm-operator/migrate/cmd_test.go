@@ -0,0 +1,127 @@
+// Copyright 2026 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migrate
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	rspb "helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/operator-framework/operator-sdk/internal/helm/storage"
+)
+
+const watchesYAML = `---
+- group: example.com
+  version: v1alpha1
+  kind: Nginx
+  chart: ../../../plugins/helm/v1/chartutil/testdata/test-chart
+`
+
+var _ = Describe("Running a migrate-storage command", func() {
+	var (
+		clientset   *k8sfake.Clientset
+		cl          client.Client
+		watchesFile string
+		out         *bytes.Buffer
+	)
+
+	newRelease := func(name string, version int) *rspb.Release {
+		return &rspb.Release{
+			Name:      name,
+			Namespace: "default",
+			Version:   version,
+			Info:      &rspb.Info{Status: rspb.StatusDeployed},
+		}
+	}
+	execute := func(args ...string) error {
+		c := &migrateCmd{clients: func() (client.Client, kubernetes.Interface, error) {
+			return cl, clientset, nil
+		}}
+		cmd := newCmd(c)
+		cmd.SetOut(out)
+		cmd.SetArgs(append([]string{"--watches-file", watchesFile}, args...))
+		return cmd.Execute()
+	}
+
+	BeforeEach(func() {
+		watchesFile = filepath.Join(GinkgoT().TempDir(), "watches.yaml")
+		Expect(os.WriteFile(watchesFile, []byte(watchesYAML), 0o600)).To(Succeed())
+
+		cr := &unstructured.Unstructured{}
+		cr.SetGroupVersionKind(schema.GroupVersionKind{Group: "example.com", Version: "v1alpha1", Kind: "Nginx"})
+		cr.SetNamespace("default")
+		cr.SetName("web")
+		cl = fake.NewClientBuilder().WithObjects(cr).Build()
+
+		clientset = k8sfake.NewClientset()
+		secrets := driver.NewSecrets(clientset.CoreV1().Secrets("default"))
+		for _, rls := range []*rspb.Release{newRelease("web", 1), newRelease("web", 2), newRelease("unowned", 1)} {
+			Expect(secrets.Create(releaseKey(rls), rls)).To(Succeed())
+		}
+		out = &bytes.Buffer{}
+	})
+
+	It("copies the release records of the watched custom resources", func() {
+		Expect(execute("--to", "configmap")).To(Succeed())
+		Expect(out.String()).To(Equal("Migrated 2 release records from \"secret\" to \"configmap\"\n"))
+
+		configMaps := driver.NewConfigMaps(clientset.CoreV1().ConfigMaps("default"))
+		migrated, err := configMaps.List(func(*rspb.Release) bool { return true })
+		Expect(err).NotTo(HaveOccurred())
+		Expect(migrated).To(HaveLen(2))
+		for _, rls := range migrated {
+			Expect(rls.Name).To(Equal("web"))
+		}
+		// The source records are kept.
+		secrets := driver.NewSecrets(clientset.CoreV1().Secrets("default"))
+		kept, err := secrets.List(func(*rspb.Release) bool { return true })
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kept).To(HaveLen(3))
+	})
+
+	It("deletes the source records with --delete-source", func() {
+		Expect(execute("--to", "configmap", "--delete-source")).To(Succeed())
+		secrets := driver.NewSecrets(clientset.CoreV1().Secrets("default"))
+		kept, err := secrets.List(func(*rspb.Release) bool { return true })
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kept).To(HaveLen(1))
+		Expect(kept[0].Name).To(Equal("unowned"))
+	})
+
+	It("rejects invalid backends", func() {
+		GinkgoT().Setenv(storage.ConnectionStringEnvVar, "")
+		Expect(execute()).To(MatchError("--to is required"))
+		Expect(execute("--to", "etcd")).To(MatchError(ContainSubstring(`invalid --to: unsupported release storage backend "etcd"`)))
+		Expect(execute("--to", "secret")).To(MatchError("--from and --to must be different backends"))
+		Expect(execute("--to", "sql", "--to-connection", "")).To(MatchError(ContainSubstring("a connection string is required")))
+	})
+})
+
+// releaseKey returns the key of the record of rls, as set by the Helm storage.
+func releaseKey(rls *rspb.Release) string {
+	return "sh.helm.release.v1." + rls.Name + ".v" + strconv.Itoa(rls.Version)
+}
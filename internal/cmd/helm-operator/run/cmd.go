@@ -15,9 +15,11 @@
 package run
 
 import (
+	"context"
 	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"runtime"
 	"strings"
@@ -27,21 +29,34 @@ import (
 	"github.com/spf13/cobra"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	apimachruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/workqueue"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	zapf "sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/manager/signals"
 	crmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/yaml"
 
+	"github.com/operator-framework/operator-sdk/internal/helm/chartsource"
 	helmClient "github.com/operator-framework/operator-sdk/internal/helm/client"
+	operatorconfig "github.com/operator-framework/operator-sdk/internal/helm/config"
 	"github.com/operator-framework/operator-sdk/internal/helm/controller"
 	"github.com/operator-framework/operator-sdk/internal/helm/flags"
+	"github.com/operator-framework/operator-sdk/internal/helm/images"
+	"github.com/operator-framework/operator-sdk/internal/helm/maintenance"
 	"github.com/operator-framework/operator-sdk/internal/helm/metrics"
 	"github.com/operator-framework/operator-sdk/internal/helm/release"
+	"github.com/operator-framework/operator-sdk/internal/helm/reloader"
+	"github.com/operator-framework/operator-sdk/internal/helm/storage"
+	"github.com/operator-framework/operator-sdk/internal/helm/tracing"
+	"github.com/operator-framework/operator-sdk/internal/helm/values"
 	"github.com/operator-framework/operator-sdk/internal/helm/watches"
 	"github.com/operator-framework/operator-sdk/internal/util/k8sutil"
 	sdkVersion "github.com/operator-framework/operator-sdk/internal/version"
@@ -53,6 +68,16 @@ import (
 
 var log = logf.Log.WithName("cmd")
 
+const (
+	// defaultTestTimeout matches the default timeout of `helm test`.
+	defaultTestTimeout = 5 * time.Minute
+	// defaultWaitTimeout matches the default timeout of `helm install --wait`.
+	defaultWaitTimeout = 5 * time.Minute
+	// tracingShutdownTimeout is how long pending traces are exported for on
+	// exit.
+	tracingShutdownTimeout = 5 * time.Second
+)
+
 func printVersion() {
 	version := sdkVersion.GitVersion
 	if version == "unknown" {
@@ -94,52 +119,50 @@ func NewCmd() *cobra.Command {
 
 func run(cmd *cobra.Command, f *flags.Flags) {
 	printVersion()
-	metrics.RegisterBuildInfo(crmetrics.Registry)
-
-	// Load config options from the config at f.ManagerConfigPath.
-	// These options will not override those set by flags.
-	var (
-		options manager.Options
-		err     error
-	)
 
-	exitIfUnsupported(options)
-
-	cfg, err := config.GetConfig()
+	options, err := newManagerOptions(cmd, f)
 	if err != nil {
-		log.Error(err, "Failed to get config.")
+		log.Error(err, "Invalid configuration.")
 		os.Exit(1)
 	}
-
-	// TODO(2.0.0): remove
-	// Deprecated: OPERATOR_NAME environment variable is an artifact of the
-	// legacy operator-sdk project scaffolding. Flag `--leader-election-id`
-	// should be used instead.
-	if operatorName, found := os.LookupEnv("OPERATOR_NAME"); found {
-		log.Info("Environment variable OPERATOR_NAME has been deprecated, use --leader-election-id instead.")
-		if cmd.Flags().Changed("leader-election-id") {
-			log.Info("Ignoring OPERATOR_NAME environment variable since --leader-election-id is set")
-		} else if options.LeaderElectionID == "" {
-			// Only set leader election ID using OPERATOR_NAME if unset everywhere else,
-			// since this env var is deprecated.
-			options.LeaderElectionID = operatorName
+	if f.PrintConfig {
+		if err := printConfig(cmd.OutOrStdout(), options, f); err != nil {
+			log.Error(err, "Failed to print configuration.")
+			os.Exit(1)
 		}
+		return
 	}
 
-	//TODO(2.0.0): remove the following checks. they are required just because of the flags deprecation
-	if cmd.Flags().Changed("leader-elect") && cmd.Flags().Changed("enable-leader-election") {
-		log.Error(errors.New("only one of --leader-elect and --enable-leader-election may be set"), "invalid flags usage")
-		os.Exit(1)
+	metrics.RegisterBuildInfo(crmetrics.Registry)
+	metrics.RegisterReleaseMetrics(crmetrics.Registry)
+
+	tracingOpts := tracing.Options{
+		Endpoint:    f.TracingEndpoint,
+		Protocol:    f.TracingProtocol,
+		Insecure:    f.TracingInsecure,
+		SampleRatio: f.TracingSampleRatio,
+	}
+	if tracingOpts.Enabled() {
+		shutdownTracing, err := tracing.Setup(context.Background(), tracingOpts)
+		if err != nil {
+			log.Error(err, "Failed to set up tracing.")
+			os.Exit(1)
+		}
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+			defer cancel()
+			if err := shutdownTracing(ctx); err != nil {
+				log.Error(err, "Failed to export pending traces.")
+			}
+		}()
 	}
 
-	if cmd.Flags().Changed("metrics-addr") && cmd.Flags().Changed("metrics-bind-address") {
-		log.Error(errors.New("only one of --metrics-addr and --metrics-bind-address may be set"), "invalid flags usage")
+	cfg, err := config.GetConfig()
+	if err != nil {
+		log.Error(err, "Failed to get config.")
 		os.Exit(1)
 	}
 
-	// Set default manager options
-	options = f.ToManagerOptions(options)
-
 	if options.Scheme == nil {
 		options.Scheme = apimachruntime.NewScheme()
 	}
@@ -150,7 +173,17 @@ func run(cmd *cobra.Command, f *flags.Flags) {
 		os.Exit(1)
 	}
 
-	configureWatchNamespaces(&options, log)
+	fetcher := chartsource.NewFetcher(f.ChartCacheDir)
+	if err := fetchRemoteCharts(ws, fetcher); err != nil {
+		log.Error(err, "Failed to fetch charts.")
+		os.Exit(1)
+	}
+	chartDirs, err := resolveChartDependencies(ws, fetcher)
+	if err != nil {
+		log.Error(err, "Failed to resolve chart dependencies.")
+		os.Exit(1)
+	}
+
 	err = configureSelectors(&options, ws, options.Scheme)
 	if err != nil {
 		log.Error(err, "Failed to configure default selectors for caching")
@@ -175,28 +208,160 @@ func run(cmd *cobra.Command, f *flags.Flags) {
 		os.Exit(1)
 	}
 
-	acg, err := helmClient.NewActionConfigGetter(mgr.GetConfig(), mgr.GetRESTMapper(), mgr.GetLogger())
+	acgs, err := newActionConfigGetters(mgr, ws, f)
 	if err != nil {
 		log.Error(err, "Failed to create Helm action config getter")
 		os.Exit(1)
 	}
+	var chartReloader *reloader.Reloader
+	if f.ReloadCharts {
+		chartReloader, err = reloader.New(f.WatchesFile, fetcher)
+		if err != nil {
+			log.Error(err, "Failed to create chart reloader")
+			os.Exit(1)
+		}
+		if err := mgr.Add(chartReloader); err != nil {
+			log.Error(err, "Failed to add chart reloader to manager")
+			os.Exit(1)
+		}
+	}
+	dependentWatches := controller.NewDependentWatches(mgr.GetCache())
+	if err := mgr.AddMetricsServerExtraHandler(controller.DependentWatchesPath, dependentWatches); err != nil {
+		log.Error(err, "Failed to add dependent watches endpoint")
+		os.Exit(1)
+	}
+	imageRewriter, err := newImageRewriter(f)
+	if err != nil {
+		log.Error(err, "Failed to load image mirrors")
+		os.Exit(1)
+	}
+	var valuesCache cache.Cache
 	for _, w := range ws {
 		// Register the controller with the factory.
 		reconcilePeriod := f.ReconcilePeriod
 		if w.ReconcilePeriod.Duration != time.Duration(0) {
 			reconcilePeriod = w.ReconcilePeriod.Duration
 		}
+		serverSideApply := f.ServerSideApply
+		if w.ServerSideApply != nil {
+			serverSideApply = *w.ServerSideApply
+		}
+		forceConflicts := f.ForceConflicts
+		if w.ForceConflicts != nil {
+			forceConflicts = *w.ForceConflicts
+		}
+		testTimeout := defaultTestTimeout
+		if w.Test.Timeout.Duration != time.Duration(0) {
+			testTimeout = w.Test.Timeout.Duration
+		}
+		waitTimeout := defaultWaitTimeout
+		if w.Wait.Timeout.Duration != time.Duration(0) {
+			waitTimeout = w.Wait.Timeout.Duration
+		}
+
+		maxHistory := f.MaxReleaseHistory
+		if w.MaxHistory != nil {
+			maxHistory = *w.MaxHistory
+		}
+		maxConcurrentReconciles := f.MaxConcurrentReconciles
+		if w.MaxConcurrentReconciles != nil {
+			maxConcurrentReconciles = *w.MaxConcurrentReconciles
+		}
+		var rateLimiter workqueue.TypedRateLimiter[reconcile.Request]
+		if w.RateLimiter != nil {
+			rateLimiter = w.RateLimiter.New()
+		}
+		prioritizer, err := w.Prioritizer()
+		if err != nil {
+			log.Error(err, "Failed to parse priorities")
+			os.Exit(1)
+		}
+
+		var maintenanceWindow *maintenance.Schedule
+		if w.MaintenanceWindow != nil {
+			maintenanceWindow, err = w.MaintenanceWindow.Parse()
+			if err != nil {
+				log.Error(err, "Failed to parse maintenance window")
+				os.Exit(1)
+			}
+		}
+
+		releaseNaming, err := w.ReleaseNaming()
+		if err != nil {
+			log.Error(err, "Failed to parse release naming")
+			os.Exit(1)
+		}
+		factoryOpts := []release.ManagerFactoryOption{
+			release.WithMaxHistory(maxHistory),
+			release.WithReleaseNaming(releaseNaming),
+		}
+		if !w.Values.IsZero() {
+			if len(w.Values.From) > 0 && valuesCache == nil {
+				valuesCache, err = newValuesCache(mgr, options)
+				if err != nil {
+					log.Error(err, "Failed to create values cache")
+					os.Exit(1)
+				}
+			}
+			pipeline, err := values.NewPipeline(w.Values, valuesCache)
+			if err != nil {
+				log.Error(err, "Failed to create values pipeline")
+				os.Exit(1)
+			}
+			factoryOpts = append(factoryOpts, release.WithValuesTransformer(pipeline))
+		}
+		if imageRewriter != nil {
+			factoryOpts = append(factoryOpts, release.WithImageRewriter(imageRewriter))
+		}
+		if w.PostRenderer != nil {
+			postRenderer, err := w.PostRenderer.New()
+			if err != nil {
+				log.Error(err, "Failed to create post-renderer")
+				os.Exit(1)
+			}
+			factoryOpts = append(factoryOpts, release.WithPostRenderer(postRenderer))
+		}
+
+		factory := release.NewManagerFactory(mgr, acgs.forWatch(w), chartDirs[w.GroupVersionKind], factoryOpts...)
+		var chartReloads <-chan event.GenericEvent
+		if chartReloader != nil {
+			chartReloads, err = chartReloader.Add(w.GroupVersionKind, w.ChartDir, factory)
+			if err != nil {
+				log.Error(err, "Failed to watch chart for changes")
+				os.Exit(1)
+			}
+		}
 
-		err := controller.Add(mgr, controller.WatchOptions{
+		err = controller.Add(mgr, controller.WatchOptions{
 			GVK:                     w.GroupVersionKind,
-			ManagerFactory:          release.NewManagerFactory(mgr, acg, w.ChartDir),
+			ManagerFactory:          factory,
 			ReconcilePeriod:         reconcilePeriod,
 			WatchDependentResources: *w.WatchDependentResources,
 			OverrideValues:          w.OverrideValues,
 			SuppressOverrideValues:  f.SuppressOverrideValues,
-			MaxConcurrentReconciles: f.MaxConcurrentReconciles,
+			MaxConcurrentReconciles: maxConcurrentReconciles,
+			RateLimiter:             rateLimiter,
+			Prioritizer:             prioritizer,
 			Selector:                w.Selector,
 			DryRunOption:            w.DryRunOption,
+			ServerSideApply:         serverSideApply,
+			ForceConflicts:          forceConflicts,
+			RunTests:                w.Test.Enabled,
+			TestTimeout:             testTimeout,
+			RollbackOnTestFailure:   w.Test.RollbackOnFailure,
+			Wait:                    w.Wait.Enabled,
+			WaitForJobs:             w.Wait.Jobs,
+			WaitTimeout:             waitTimeout,
+			ChartReloads:            chartReloads,
+			ValuesCache:             valuesCache,
+			ValuesReferences:        w.Values.From,
+			ManifestStorage:         w.ManifestStorage,
+			ReleaseStorage:          acgs.backendForWatch(w),
+			MaintenanceWindow:       maintenanceWindow,
+			ReleaseNaming:           releaseNaming,
+			AdoptReleases:           w.AdoptReleases,
+			ValidatingWebhook:       f.EnableWebhook,
+			DependentWatches:        dependentWatches,
 		})
 		if err != nil {
 			log.Error(err, "Failed to add manager factory to controller.")
@@ -211,18 +376,222 @@ func run(cmd *cobra.Command, f *flags.Flags) {
 	}
 }
 
-// exitIfUnsupported prints an error containing unsupported field names and exits
-// if any of those fields are not their default values.
-func exitIfUnsupported(options manager.Options) {
-	// The below options are webhook-specific, which is not supported by helm.
-	if options.WebhookServer != nil {
-		log.Error(errors.New("webhook configurations set in manager options"), "unsupported configuration")
-		os.Exit(1)
+// newManagerOptions returns the options of the manager, loaded from the config
+// file at f.ManagerConfigPath if it is set, and from the flags. Flags set in the
+// CLI take precedence over the config file, which takes precedence over flag
+// defaults. The defaults of watches in the config file are applied to f.
+func newManagerOptions(cmd *cobra.Command, f *flags.Flags) (manager.Options, error) {
+	var (
+		options          manager.Options
+		configNamespaces []string
+	)
+	if f.ManagerConfigPath != "" {
+		c, err := operatorconfig.Load(f.ManagerConfigPath)
+		if err != nil {
+			return options, err
+		}
+		options = c.ToManagerOptions(options)
+		f.ApplyWatchDefaults(c.WatchDefaults)
+		configNamespaces = c.Cache.Namespaces
+	}
+
+	// TODO(2.0.0): remove
+	// Deprecated: OPERATOR_NAME environment variable is an artifact of the
+	// legacy operator-sdk project scaffolding. Flag `--leader-election-id`
+	// should be used instead.
+	if operatorName, found := os.LookupEnv("OPERATOR_NAME"); found {
+		log.Info("Environment variable OPERATOR_NAME has been deprecated, use --leader-election-id instead.")
+		if cmd.Flags().Changed("leader-election-id") {
+			log.Info("Ignoring OPERATOR_NAME environment variable since --leader-election-id is set")
+		} else if options.LeaderElectionID == "" {
+			// Only set leader election ID using OPERATOR_NAME if unset everywhere else,
+			// since this env var is deprecated.
+			options.LeaderElectionID = operatorName
+		}
+	}
+
+	//TODO(2.0.0): remove the following checks. they are required just because of the flags deprecation
+	if cmd.Flags().Changed("leader-elect") && cmd.Flags().Changed("enable-leader-election") {
+		return options, errors.New("only one of --leader-elect and --enable-leader-election may be set")
+	}
+
+	if cmd.Flags().Changed("metrics-addr") && cmd.Flags().Changed("metrics-bind-address") {
+		return options, errors.New("only one of --metrics-addr and --metrics-bind-address may be set")
+	}
+
+	// Set default manager options
+	options = f.ToManagerOptions(options)
+	if options.Metrics.FilterProvider != nil && !options.Metrics.SecureServing {
+		return options, errors.New("the metrics endpoint must be secure to require RBAC")
+	}
+
+	configureWatchNamespaces(&options, configNamespaces, log)
+	return options, nil
+}
+
+// printConfig prints the effective configuration of the operator to out, as a
+// config file.
+func printConfig(out io.Writer, options manager.Options, f *flags.Flags) error {
+	b, err := yaml.Marshal(operatorconfig.FromManagerOptions(options, f.WatchDefaults()))
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(b)
+	return err
+}
+
+// actionConfigGetters holds the action config getters of the release storage
+// backends used by the watches.
+type actionConfigGetters struct {
+	defaultBackend storage.Backend
+	getters        map[storage.Backend]helmClient.ActionConfigGetter
+}
+
+// newActionConfigGetters creates the action config getters of the release
+// storage backends used by the watches.
+func newActionConfigGetters(mgr manager.Manager, ws []watches.Watch, f *flags.Flags) (*actionConfigGetters, error) {
+	defaultBackend := storage.Backend(f.ReleaseStorage)
+	if err := defaultBackend.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid --release-storage: %w", err)
+	}
+	if defaultBackend == "" {
+		defaultBackend = storage.BackendSecret
+	}
+	acgs := &actionConfigGetters{
+		defaultBackend: defaultBackend,
+		getters:        map[storage.Backend]helmClient.ActionConfigGetter{},
 	}
+
+	backends := []storage.Backend{defaultBackend}
+	for _, w := range ws {
+		if w.ReleaseStorage != "" {
+			backends = append(backends, w.ReleaseStorage)
+		}
+	}
+	for _, b := range backends {
+		if _, ok := acgs.getters[b]; ok {
+			continue
+		}
+		var db *storage.SQL
+		if b.IsSQL() {
+			connectionString := f.ReleaseStorageConn
+			if connectionString == "" {
+				connectionString = os.Getenv(storage.ConnectionStringEnvVar)
+			}
+			var err error
+			db, err = storage.OpenSQL(b, connectionString, func(format string, v ...any) {
+				mgr.GetLogger().V(1).Info(fmt.Sprintf(format, v...))
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+		acg, err := helmClient.NewActionConfigGetter(mgr.GetConfig(), mgr.GetRESTMapper(), mgr.GetLogger(),
+			helmClient.WithStorage(b, db))
+		if err != nil {
+			return nil, err
+		}
+		acgs.getters[b] = acg
+	}
+	return acgs, nil
 }
 
-func configureWatchNamespaces(options *manager.Options, log logr.Logger) {
+// forWatch returns the action config getter of the release storage backend
+// of w.
+func (acgs *actionConfigGetters) forWatch(w watches.Watch) helmClient.ActionConfigGetter {
+	return acgs.getters[acgs.backendForWatch(w)]
+}
+
+// backendForWatch returns the release storage backend of w.
+func (acgs *actionConfigGetters) backendForWatch(w watches.Watch) storage.Backend {
+	if w.ReleaseStorage != "" {
+		return w.ReleaseStorage
+	}
+	return acgs.defaultBackend
+}
+
+// fetchRemoteCharts fetches the charts of watches that reference OCI
+// registries or chart repositories into the local chart cache, and points the
+// watches at the cached chart directories.
+func fetchRemoteCharts(ws []watches.Watch, fetcher *chartsource.Fetcher) error {
+	for i, w := range ws {
+		if !w.IsRemoteChart() {
+			continue
+		}
+		chartDir, err := fetcher.Fetch(w.ChartSource())
+		if err != nil {
+			return fmt.Errorf("unable to fetch chart for %s: %w", w.GroupVersionKind, err)
+		}
+		ws[i].ChartDir = chartDir
+	}
+	return nil
+}
+
+// newImageRewriter returns the rewriter of the images of releases configured
+// by the --image-mirrors and --pin-image-digests flags, or nil if images are
+// not rewritten.
+func newImageRewriter(f *flags.Flags) (*images.Rewriter, error) {
+	if f.ImageMirrorsFile == "" && !f.PinImageDigests {
+		return nil, nil
+	}
+	var mirrors []images.Mirror
+	if f.ImageMirrorsFile != "" {
+		var err error
+		mirrors, err = images.LoadMirrors(f.ImageMirrorsFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+	log.Info("Rewriting images of releases", "mirrors", len(mirrors), "pinDigests", f.PinImageDigests)
+	return images.NewRewriter(mirrors, f.PinImageDigests), nil
+}
+
+// resolveChartDependencies resolves the dependencies of the charts of watches
+// from their lock files, and returns the directories of the charts with their
+// dependencies by GVK. The chart directories of watches are kept, so that the
+// chart reloader watches them for changes.
+func resolveChartDependencies(ws []watches.Watch, fetcher *chartsource.Fetcher) (map[schema.GroupVersionKind]string, error) {
+	chartDirs := make(map[schema.GroupVersionKind]string, len(ws))
+	for _, w := range ws {
+		chartDir, err := fetcher.ResolveDependencies(w.ChartDir, w.ChartSource())
+		if err != nil {
+			return nil, fmt.Errorf("unable to resolve chart dependencies for %s: %w", w.GroupVersionKind, err)
+		}
+		chartDirs[w.GroupVersionKind] = chartDir
+	}
+	return chartDirs, nil
+}
+
+// newValuesCache creates the cache of the ConfigMaps and Secrets referenced by
+// values pipelines in the namespaces watched by the operator, and adds it to
+// the manager.
+func newValuesCache(mgr manager.Manager, options manager.Options) (cache.Cache, error) {
+	var namespaces []string
+	for ns := range options.Cache.DefaultNamespaces {
+		if ns == metav1.NamespaceAll {
+			namespaces = nil
+			break
+		}
+		namespaces = append(namespaces, ns)
+	}
+	c, err := values.NewCache(mgr.GetConfig(), mgr.GetRESTMapper(), namespaces)
+	if err != nil {
+		return nil, err
+	}
+	if err := mgr.Add(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// configureWatchNamespaces configures the namespaces watched by the manager
+// from the WATCH_NAMESPACE environment variable, or from configNamespaces if
+// it is not set.
+func configureWatchNamespaces(options *manager.Options, configNamespaces []string, log logr.Logger) {
 	namespaces := splitNamespaces(os.Getenv(k8sutil.WatchNamespaceEnvVar))
+	if len(namespaces) == 0 {
+		namespaces = configNamespaces
+	}
 
 	namespaceConfigs := make(map[string]cache.Config)
 	if len(namespaces) != 0 {
@@ -281,7 +650,7 @@ func configureSelectors(opts *manager.Options, ws []watches.Watch, sch *apimachr
 		chartNames = append(chartNames, chrt.Name())
 
 	}
-	req, err := labels.NewRequirement("helm.sdk.operatorframework.io/chart", selection.In, chartNames)
+	req, err := labels.NewRequirement(release.ChartLabel, selection.In, chartNames)
 	if err != nil {
 		return fmt.Errorf("unable to create label requirement for cache default selector: %v", err)
 	}
@@ -18,6 +18,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/generate/bundle"
+	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/generate/helmcrd"
 	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/generate/kustomize"
 	"github.com/operator-framework/operator-sdk/internal/cmd/operator-sdk/generate/packagemanifests"
 )
@@ -35,6 +36,7 @@ code or manifests.`,
 		kustomize.NewCmd(),
 		bundle.NewCmd(),
 		packagemanifests.NewCmd(),
+		helmcrd.NewCmd(),
 	)
 	return cmd
 }
@@ -0,0 +1,76 @@
+// Copyright 2026 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helmcrd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/operator-framework/operator-sdk/internal/helm/watches"
+)
+
+const longHelp = `
+Running 'generate helm-crd' will (re)generate the schema of the spec of each CRD in 'config/crd/bases'
+reconciled by a Helm-based operator, from the chart of the CRD's watch in 'watches.yaml'. The schema is
+converted from the chart's values.schema.json if it has one, or inferred from the types of the values in
+the chart's values.yaml otherwise. Run this command after updating a chart so that the API server validates
+custom resources against the chart's current values.
+`
+
+const examples = `
+  # Update a chart, then regenerate the CRD schemas of the charts in watches.yaml:
+  $ operator-sdk generate helm-crd
+  Updated config/crd/bases/cache.example.com_memcacheds.yaml
+`
+
+type helmCRDCmd struct {
+	watchesFile string
+	crdsDir     string
+	quiet       bool
+}
+
+// NewCmd returns the 'helm-crd' command.
+func NewCmd() *cobra.Command {
+	c := &helmCRDCmd{}
+	cmd := &cobra.Command{
+		Use:     "helm-crd",
+		Short:   "Generates the spec schemas of Helm-based operator CRDs from their charts",
+		Long:    longHelp,
+		Example: examples,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return fmt.Errorf("command %s doesn't accept any arguments", cmd.CommandPath())
+			}
+			if err := c.run(); err != nil {
+				log.Fatalf("Error generating CRD schemas: %v", err)
+			}
+			return nil
+		},
+	}
+
+	c.addFlagsTo(cmd.Flags())
+
+	return cmd
+}
+
+func (c *helmCRDCmd) addFlagsTo(fs *pflag.FlagSet) {
+	fs.StringVar(&c.watchesFile, "watches-file", watches.WatchesFile, "Path to the watches file")
+	fs.StringVar(&c.crdsDir, "crds-dir", filepath.Join("config", "crd", "bases"), "Directory containing the CRDs to update")
+	fs.BoolVarP(&c.quiet, "quiet", "q", false, "Run in quiet mode")
+}
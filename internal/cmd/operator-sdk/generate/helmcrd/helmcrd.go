@@ -0,0 +1,161 @@
+// Copyright 2026 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helmcrd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	log "github.com/sirupsen/logrus"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"github.com/operator-framework/operator-sdk/internal/helm/watches"
+	"github.com/operator-framework/operator-sdk/internal/plugins/helm/v1/chartutil"
+)
+
+const documentSeparator = "---\n"
+
+// run updates the spec schema of every CRD in crdsDir that has a watch with
+// a local chart.
+func (c helmCRDCmd) run() error {
+	ws, err := watches.Load(c.watchesFile)
+	if err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(c.crdsDir)
+	if err != nil {
+		return fmt.Errorf("error reading CRDs directory: %v", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".yaml" {
+			continue
+		}
+		path := filepath.Join(c.crdsDir, entry.Name())
+		updated, err := updateCRDFile(path, ws)
+		if err != nil {
+			return fmt.Errorf("error updating %s: %v", path, err)
+		}
+		if updated && !c.quiet {
+			fmt.Println("Updated", path)
+		}
+	}
+	return nil
+}
+
+// updateCRDFile updates the spec schema of the CRD in path from the chart of
+// its watch, and returns true if the CRD has a watch.
+func updateCRDFile(path string, ws []watches.Watch) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	crd := map[string]any{}
+	if err := yaml.Unmarshal(data, &crd); err != nil {
+		return false, err
+	}
+	if crd["kind"] != "CustomResourceDefinition" {
+		return false, nil
+	}
+	group, _, _ := unstructured.NestedString(crd, "spec", "group")
+	kind, _, _ := unstructured.NestedString(crd, "spec", "names", "kind")
+
+	updated := false
+	for _, w := range ws {
+		if w.Group != group || w.Kind != kind {
+			continue
+		}
+		if w.IsRemoteChart() {
+			log.Warnf("Skipping %s: the schema of remote chart %q cannot be generated", path, w.ChartDir)
+			continue
+		}
+		chrt, err := loader.Load(w.ChartDir)
+		if err != nil {
+			return false, fmt.Errorf("error loading chart %s: %v", w.ChartDir, err)
+		}
+		schema, err := chartutil.SpecSchema(chrt)
+		if err != nil {
+			return false, err
+		}
+		schema.Description = fmt.Sprintf("Spec defines the desired state of %s", kind)
+		if err := setSpecSchema(crd, w.Version, schema); err != nil {
+			return false, err
+		}
+		updated = true
+	}
+	if !updated {
+		return false, nil
+	}
+
+	out, err := yaml.Marshal(crd)
+	if err != nil {
+		return false, err
+	}
+	if bytes.HasPrefix(data, []byte(documentSeparator)) {
+		out = append([]byte(documentSeparator), out...)
+	}
+	return true, os.WriteFile(path, out, 0644)
+}
+
+// setSpecSchema sets the schema of the spec of version in crd to schema.
+func setSpecSchema(crd map[string]any, version string, schema *apiextv1.JSONSchemaProps) error {
+	b, err := json.Marshal(schema)
+	if err != nil {
+		return err
+	}
+	specSchema := map[string]any{}
+	if err := json.Unmarshal(b, &specSchema); err != nil {
+		return err
+	}
+
+	spec, _ := crd["spec"].(map[string]any)
+	// apiextensions.k8s.io/v1beta1 CRDs have a single schema for all versions.
+	if validation, ok := spec["validation"].(map[string]any); ok {
+		return setProperty(validation, specSchema)
+	}
+	versions, _ := spec["versions"].([]any)
+	for _, v := range versions {
+		v, ok := v.(map[string]any)
+		if !ok || v["name"] != version {
+			continue
+		}
+		s, ok := v["schema"].(map[string]any)
+		if !ok {
+			return fmt.Errorf("version %s has no schema", version)
+		}
+		return setProperty(s, specSchema)
+	}
+	return fmt.Errorf("version %s not found", version)
+}
+
+func setProperty(schema, specSchema map[string]any) error {
+	openAPIV3Schema, ok := schema["openAPIV3Schema"].(map[string]any)
+	if !ok {
+		return fmt.Errorf("no openAPIV3Schema found")
+	}
+	props, ok := openAPIV3Schema["properties"].(map[string]any)
+	if !ok {
+		props = map[string]any{}
+		openAPIV3Schema["properties"] = props
+	}
+	props["spec"] = specSchema
+	return nil
+}
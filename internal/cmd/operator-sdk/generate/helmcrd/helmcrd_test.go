@@ -0,0 +1,80 @@
+// Copyright 2026 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helmcrd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const sampleDir = "../../../../../testdata/helm/memcached-operator"
+
+func TestRun(t *testing.T) {
+	chartDir, err := filepath.Abs(filepath.Join(sampleDir, "helm-charts", "memcached"))
+	require.NoError(t, err)
+	crdData, err := os.ReadFile(filepath.Join(sampleDir, "config", "crd", "bases", "cache.example.com_memcacheds.yaml"))
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	watchesFile := filepath.Join(dir, "watches.yaml")
+	require.NoError(t, os.WriteFile(watchesFile, []byte(fmt.Sprintf(
+		"- group: cache.example.com\n  version: v1alpha1\n  kind: Memcached\n  chart: %s\n", chartDir)), 0o600))
+	crdsDir := filepath.Join(dir, "crds")
+	require.NoError(t, os.Mkdir(crdsDir, 0o755))
+	crdFile := filepath.Join(crdsDir, "cache.example.com_memcacheds.yaml")
+	require.NoError(t, os.WriteFile(crdFile, crdData, 0o600))
+	otherFile := filepath.Join(crdsDir, "other.yaml")
+	require.NoError(t, os.WriteFile(otherFile, []byte("apiVersion: v1\nkind: ConfigMap\n"), 0o600))
+
+	c := helmCRDCmd{watchesFile: watchesFile, crdsDir: crdsDir, quiet: true}
+	require.NoError(t, c.run())
+
+	data, err := os.ReadFile(crdFile)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(data), documentSeparator))
+	crd := &apiextv1.CustomResourceDefinition{}
+	require.NoError(t, yaml.UnmarshalStrict(data, crd))
+	schema := crd.Spec.Versions[0].Schema.OpenAPIV3Schema
+	spec := schema.Properties["spec"]
+	assert.Equal(t, "Spec defines the desired state of Memcached", spec.Description)
+	assert.Equal(t, "integer", spec.Properties["replicaCount"].Type)
+	assert.Equal(t, "string", spec.Properties["memcached"].Properties["verbosity"].Type)
+	assert.Equal(t, "string", schema.Properties["apiVersion"].Type)
+	assert.Contains(t, schema.Properties, "status")
+
+	// Running the command again does not change the CRD.
+	require.NoError(t, c.run())
+	again, err := os.ReadFile(crdFile)
+	require.NoError(t, err)
+	assert.Equal(t, string(data), string(again))
+
+	// Files that are not CRDs of a watch are left untouched.
+	other, err := os.ReadFile(otherFile)
+	require.NoError(t, err)
+	assert.Equal(t, "apiVersion: v1\nkind: ConfigMap\n", string(other))
+}
+
+func TestSetSpecSchemaMissingVersion(t *testing.T) {
+	crd := map[string]any{"spec": map[string]any{"versions": []any{map[string]any{"name": "v1"}}}}
+	assert.ErrorContains(t, setSpecSchema(crd, "v2", &apiextv1.JSONSchemaProps{}), "version v2 not found")
+}
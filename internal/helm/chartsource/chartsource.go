@@ -0,0 +1,258 @@
+// Copyright 2026 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chartsource
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/downloader"
+	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/registry"
+	"helm.sh/helm/v3/pkg/repo"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("helm.chartsource")
+
+const (
+	// digestPrefix is the only supported digest algorithm.
+	digestPrefix = "sha256:"
+
+	// dockerConfigJSONKey is the key of kubernetes.io/dockerconfigjson secrets.
+	dockerConfigJSONKey = ".dockerconfigjson"
+	// usernameKey and passwordKey are the keys of kubernetes.io/basic-auth secrets.
+	usernameKey = "username"
+	passwordKey = "password"
+)
+
+// Source identifies a chart in an OCI registry or a chart repository.
+type Source struct {
+	// Chart is an oci:// chart reference, or the name of the chart in Repo.
+	Chart string
+	// Repo is the URL of the chart repository containing Chart.
+	Repo string
+	// Version is the chart version. For OCI references it may instead be
+	// set as the reference tag.
+	Version string
+	// Digest is the expected sha256 digest of the chart archive, in the
+	// form "sha256:<hex>". If set, it is verified after download and allows
+	// a cached chart to be used without contacting the registry.
+	Digest string
+	// PullSecret is the path of a directory containing a mounted pull
+	// secret, either a kubernetes.io/dockerconfigjson secret for OCI
+	// registries or a kubernetes.io/basic-auth secret.
+	PullSecret string
+	// PlainHTTP allows connecting to OCI registries over plain HTTP.
+	PlainHTTP bool
+}
+
+// IsRemote returns true if chart and repo refer to a chart that must be
+// fetched from an OCI registry or a chart repository.
+func IsRemote(chart, repo string) bool {
+	return repo != "" || registry.IsOCI(chart)
+}
+
+// ValidateDigest returns an error if digest is not a valid sha256 digest.
+func ValidateDigest(digest string) error {
+	hexDigest, ok := strings.CutPrefix(digest, digestPrefix)
+	if !ok {
+		return fmt.Errorf("digest %q must start with %q", digest, digestPrefix)
+	}
+	if b, err := hex.DecodeString(hexDigest); err != nil || len(b) != sha256.Size {
+		return fmt.Errorf("digest %q is not a valid sha256 digest", digest)
+	}
+	return nil
+}
+
+// Fetcher downloads charts into a local cache directory. Each chart is
+// expanded into a directory named after the digest of its archive, so
+// fetching a chart with a known digest that is already cached is a no-op.
+type Fetcher struct {
+	CacheDir string
+}
+
+// NewFetcher returns a Fetcher that caches charts in cacheDir.
+func NewFetcher(cacheDir string) *Fetcher {
+	return &Fetcher{CacheDir: cacheDir}
+}
+
+// Fetch downloads the chart identified by src, verifies its digest, and
+// returns the path of the expanded chart directory in the cache.
+func (f *Fetcher) Fetch(src Source) (string, error) {
+	if src.Digest != "" {
+		if err := ValidateDigest(src.Digest); err != nil {
+			return "", err
+		}
+		if chartDir, err := cachedChartDir(f.digestDir(src.Digest)); err == nil {
+			log.V(1).Info("Using cached chart", "chart", src.Chart, "digest", src.Digest)
+			return chartDir, nil
+		}
+	}
+
+	if err := os.MkdirAll(f.CacheDir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create chart cache directory: %w", err)
+	}
+	tmpDir, err := os.MkdirTemp(f.CacheDir, "download-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer func() {
+		if err := os.RemoveAll(tmpDir); err != nil {
+			log.Error(err, "Failed to remove temporary directory", "dir", tmpDir)
+		}
+	}()
+
+	archive, err := download(tmpDir, src)
+	if err != nil {
+		return "", fmt.Errorf("failed to download chart %q: %w", src.Chart, err)
+	}
+	digest, err := fileDigest(archive)
+	if err != nil {
+		return "", fmt.Errorf("failed to compute chart digest: %w", err)
+	}
+	if src.Digest != "" && src.Digest != digest {
+		return "", fmt.Errorf("chart %q digest mismatch: expected %s, got %s", src.Chart, src.Digest, digest)
+	}
+
+	digestDir := f.digestDir(digest)
+	if chartDir, err := cachedChartDir(digestDir); err == nil {
+		return chartDir, nil
+	}
+	// Expand into a temporary directory first and rename it, so that a
+	// partially expanded chart is never picked up from the cache.
+	expandDir := filepath.Join(tmpDir, "expanded")
+	if err := chartutil.ExpandFile(expandDir, archive); err != nil {
+		return "", fmt.Errorf("failed to expand chart archive: %w", err)
+	}
+	if err := os.Rename(expandDir, digestDir); err != nil {
+		return "", fmt.Errorf("failed to move chart into cache: %w", err)
+	}
+	log.Info("Fetched chart", "chart", src.Chart, "version", src.Version, "digest", digest)
+	return cachedChartDir(digestDir)
+}
+
+func (f *Fetcher) digestDir(digest string) string {
+	return filepath.Join(f.CacheDir, strings.TrimPrefix(digest, digestPrefix))
+}
+
+// cachedChartDir returns the chart directory expanded into dir.
+func cachedChartDir(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		chartDir := filepath.Join(dir, e.Name())
+		if ok, err := chartutil.IsChartDir(chartDir); ok && err == nil {
+			return chartDir, nil
+		}
+	}
+	return "", fmt.Errorf("no chart found in %s", dir)
+}
+
+func download(destDir string, src Source) (string, error) {
+	username, password, credentialsFile, err := readPullSecret(src.PullSecret)
+	if err != nil {
+		return "", err
+	}
+
+	registryOpts := []registry.ClientOption{registry.ClientOptWriter(io.Discard)}
+	if credentialsFile != "" {
+		registryOpts = append(registryOpts, registry.ClientOptCredentialsFile(credentialsFile))
+	}
+	if username != "" {
+		registryOpts = append(registryOpts, registry.ClientOptBasicAuth(username, password))
+	}
+	if src.PlainHTTP {
+		registryOpts = append(registryOpts, registry.ClientOptPlainHTTP())
+	}
+	registryClient, err := registry.NewClient(registryOpts...)
+	if err != nil {
+		return "", fmt.Errorf("failed to create registry client: %w", err)
+	}
+
+	settings := cli.New()
+	getters := getter.All(settings)
+	c := downloader.ChartDownloader{
+		Out:              io.Discard,
+		Getters:          getters,
+		RepositoryConfig: settings.RepositoryConfig,
+		RepositoryCache:  settings.RepositoryCache,
+		RegistryClient:   registryClient,
+		Options:          []getter.Option{getter.WithRegistryClient(registryClient)},
+	}
+	if username != "" {
+		c.Options = append(c.Options, getter.WithBasicAuth(username, password))
+	}
+
+	ref := src.Chart
+	if src.Repo != "" {
+		ref, err = repo.FindChartInAuthRepoURL(src.Repo, username, password, src.Chart, src.Version, "", "", "", getters)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	archive, _, err := c.DownloadTo(ref, src.Version, destDir)
+	return archive, err
+}
+
+// readPullSecret reads the credentials of the pull secret mounted at dir.
+func readPullSecret(dir string) (username, password, credentialsFile string, err error) {
+	if dir == "" {
+		return "", "", "", nil
+	}
+	if _, err := os.Stat(filepath.Join(dir, dockerConfigJSONKey)); err == nil {
+		credentialsFile = filepath.Join(dir, dockerConfigJSONKey)
+	}
+	usernameData, usernameErr := os.ReadFile(filepath.Join(dir, usernameKey))
+	passwordData, passwordErr := os.ReadFile(filepath.Join(dir, passwordKey))
+	if usernameErr == nil && passwordErr == nil {
+		username = strings.TrimSpace(string(usernameData))
+		password = strings.TrimSpace(string(passwordData))
+	}
+	if credentialsFile == "" && username == "" {
+		return "", "", "", fmt.Errorf("pull secret directory %s contains neither %s nor %s and %s",
+			dir, dockerConfigJSONKey, usernameKey, passwordKey)
+	}
+	return username, password, credentialsFile, nil
+}
+
+func fileDigest(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	_, copyErr := io.Copy(h, f)
+	if err := f.Close(); err != nil && copyErr == nil {
+		copyErr = err
+	}
+	if copyErr != nil {
+		return "", copyErr
+	}
+	return digestPrefix + hex.EncodeToString(h.Sum(nil)), nil
+}
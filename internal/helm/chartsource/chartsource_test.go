@@ -0,0 +1,215 @@
+// Copyright 2026 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chartsource
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/repo"
+	"sigs.k8s.io/yaml"
+)
+
+const testChartArchive = "../../plugins/helm/v1/chartutil/testdata/test-chart-1.2.3.tgz"
+
+func digestOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return digestPrefix + hex.EncodeToString(sum[:])
+}
+
+// newTestRegistry returns a minimal stand-in for an OCI registry serving a
+// single chart archive as <repository>:<tag>.
+func newTestRegistry(t *testing.T, repository, tag string, archive []byte) *httptest.Server {
+	config, err := json.Marshal(chart.Metadata{APIVersion: "v2", Name: "test-chart", Version: tag})
+	require.NoError(t, err)
+	blobs := map[string][]byte{
+		digestOf(config):  config,
+		digestOf(archive): archive,
+	}
+	manifest, err := json.Marshal(map[string]any{
+		"schemaVersion": 2,
+		"mediaType":     "application/vnd.oci.image.manifest.v1+json",
+		"config": map[string]any{
+			"mediaType": "application/vnd.cncf.helm.config.v1+json",
+			"digest":    digestOf(config),
+			"size":      len(config),
+		},
+		"layers": []map[string]any{{
+			"mediaType": "application/vnd.cncf.helm.chart.content.v1.tar+gzip",
+			"digest":    digestOf(archive),
+			"size":      len(archive),
+		}},
+	})
+	require.NoError(t, err)
+
+	prefix := "/v2/" + repository
+	serve := func(w http.ResponseWriter, r *http.Request, contentType string, data []byte) {
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Docker-Content-Digest", digestOf(data))
+		w.Header().Set("Content-Length", fmt.Sprint(len(data)))
+		if r.Method != http.MethodHead {
+			_, _ = w.Write(data)
+		}
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v2/":
+			w.WriteHeader(http.StatusOK)
+		case r.URL.Path == prefix+"/tags/list":
+			_ = json.NewEncoder(w).Encode(map[string]any{"name": repository, "tags": []string{tag}})
+		case r.URL.Path == prefix+"/manifests/"+tag || r.URL.Path == prefix+"/manifests/"+digestOf(manifest):
+			serve(w, r, "application/vnd.oci.image.manifest.v1+json", manifest)
+		case strings.HasPrefix(r.URL.Path, prefix+"/blobs/"):
+			blob, ok := blobs[strings.TrimPrefix(r.URL.Path, prefix+"/blobs/")]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			serve(w, r, "application/octet-stream", blob)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+// newTestRepo returns a chart repository serving a single chart archive.
+// If username is set, requests must use basic auth.
+func newTestRepo(t *testing.T, archive []byte, username, password string) *httptest.Server {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if u, p, _ := r.BasicAuth(); username != "" && (u != username || p != password) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/index.yaml":
+			idx := repo.NewIndexFile()
+			require.NoError(t, idx.MustAdd(&chart.Metadata{APIVersion: "v2", Name: "test-chart", Version: "1.2.3"},
+				"test-chart-1.2.3.tgz", srv.URL, digestOf(archive)))
+			data, err := yaml.Marshal(idx)
+			require.NoError(t, err)
+			_, _ = w.Write(data)
+		case "/test-chart-1.2.3.tgz":
+			_, _ = w.Write(archive)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	return srv
+}
+
+func TestFetchOCI(t *testing.T) {
+	archive, err := os.ReadFile(testChartArchive)
+	require.NoError(t, err)
+	srv := newTestRegistry(t, "charts/test-chart", "1.2.3", archive)
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "http://")
+
+	f := NewFetcher(t.TempDir())
+	src := Source{
+		Chart:     fmt.Sprintf("oci://%s/charts/test-chart", host),
+		Version:   "1.2.3",
+		Digest:    digestOf(archive),
+		PlainHTTP: true,
+	}
+	chartDir, err := f.Fetch(src)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(f.CacheDir, strings.TrimPrefix(digestOf(archive), digestPrefix), "test-chart"), chartDir)
+	assert.FileExists(t, filepath.Join(chartDir, "Chart.yaml"))
+
+	// A cached chart with a known digest is used without contacting the registry.
+	srv.Close()
+	cachedDir, err := f.Fetch(src)
+	require.NoError(t, err)
+	assert.Equal(t, chartDir, cachedDir)
+}
+
+func TestFetchOCIDigestMismatch(t *testing.T) {
+	archive, err := os.ReadFile(testChartArchive)
+	require.NoError(t, err)
+	srv := newTestRegistry(t, "charts/test-chart", "1.2.3", archive)
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "http://")
+
+	f := NewFetcher(t.TempDir())
+	_, err = f.Fetch(Source{
+		Chart:     fmt.Sprintf("oci://%s/charts/test-chart:1.2.3", host),
+		Digest:    digestOf([]byte("something else")),
+		PlainHTTP: true,
+	})
+	assert.ErrorContains(t, err, "digest mismatch")
+}
+
+func TestFetchRepo(t *testing.T) {
+	archive, err := os.ReadFile(testChartArchive)
+	require.NoError(t, err)
+	srv := newTestRepo(t, archive, "user", "pass")
+	defer srv.Close()
+
+	secretDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(secretDir, usernameKey), []byte("user"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(secretDir, passwordKey), []byte("pass\n"), 0o600))
+
+	f := NewFetcher(t.TempDir())
+	chartDir, err := f.Fetch(Source{
+		Chart:      "test-chart",
+		Repo:       srv.URL,
+		Version:    "1.2.3",
+		PullSecret: secretDir,
+	})
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(chartDir, "Chart.yaml"))
+
+	_, err = f.Fetch(Source{Chart: "test-chart", Repo: srv.URL, Version: "1.2.3"})
+	assert.Error(t, err)
+}
+
+func TestReadPullSecret(t *testing.T) {
+	username, password, credentialsFile, err := readPullSecret("")
+	assert.NoError(t, err)
+	assert.Empty(t, username+password+credentialsFile)
+
+	dir := t.TempDir()
+	_, _, _, err = readPullSecret(dir)
+	assert.Error(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, dockerConfigJSONKey), []byte("{}"), 0o600))
+	_, _, credentialsFile, err = readPullSecret(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, dockerConfigJSONKey), credentialsFile)
+}
+
+func TestValidateDigest(t *testing.T) {
+	assert.NoError(t, ValidateDigest(digestOf([]byte("chart"))))
+	assert.Error(t, ValidateDigest("md5:abc"))
+	assert.Error(t, ValidateDigest("sha256:abc"))
+}
+
+func TestIsRemote(t *testing.T) {
+	assert.True(t, IsRemote("oci://example.com/charts/test-chart", ""))
+	assert.True(t, IsRemote("test-chart", "https://charts.example.com"))
+	assert.False(t, IsRemote("helm-charts/test-chart", ""))
+}
@@ -0,0 +1,296 @@
+// Copyright 2026 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chartsource
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/registry"
+)
+
+// dependenciesDir is the directory of the chart cache containing copies of
+// charts whose dependencies were downloaded.
+const dependenciesDir = "dependencies"
+
+// ResolveDependencies returns the directory of the chart at chartDir with all
+// of its dependencies in its charts directory. A chart whose dependencies are
+// all vendored is used as is. Otherwise, the chart is copied into the cache
+// and the missing dependencies are downloaded at the versions of its lock
+// file, with the pull secret and plain HTTP setting of src.
+//
+// The lock file must be in sync with the dependencies of the chart, and
+// vendored dependencies must have the versions of the lock file.
+func (f *Fetcher) ResolveDependencies(chartDir string, src Source) (string, error) {
+	c, err := loader.LoadDir(chartDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to load chart %s: %w", chartDir, err)
+	}
+	if len(c.Metadata.Dependencies) == 0 {
+		return chartDir, nil
+	}
+	if c.Lock != nil {
+		if err := verifyLock(c); err != nil {
+			return "", err
+		}
+	}
+	missing := missingDependencies(c)
+	if len(missing) == 0 {
+		return chartDir, nil
+	}
+	if c.Lock == nil {
+		return "", fmt.Errorf("chart %s has missing dependencies %s and no lock file: "+
+			"run `helm dependency update` to create one", c.Name(), dependencyNames(missing))
+	}
+
+	digest, err := dirDigest(chartDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to compute digest of chart %s: %w", c.Name(), err)
+	}
+	resolvedDir := filepath.Join(f.CacheDir, dependenciesDir, digest)
+	if cached, err := cachedChartDir(resolvedDir); err == nil {
+		log.V(1).Info("Using cached chart dependencies", "chart", c.Name(), "path", cached)
+		return cached, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(resolvedDir), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create chart cache directory: %w", err)
+	}
+	tmpDir, err := os.MkdirTemp(f.CacheDir, "dependencies-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer func() {
+		if err := os.RemoveAll(tmpDir); err != nil {
+			log.Error(err, "Failed to remove temporary directory", "dir", tmpDir)
+		}
+	}()
+
+	// Download into a copy of the chart, which is moved into the cache once
+	// complete, so that the chart directory is never modified.
+	expandDir := filepath.Join(tmpDir, "expanded")
+	copyDir := filepath.Join(expandDir, filepath.Base(chartDir))
+	if err := copyChartDir(chartDir, copyDir); err != nil {
+		return "", fmt.Errorf("failed to copy chart %s: %w", c.Name(), err)
+	}
+	chartsDir := filepath.Join(copyDir, "charts")
+	if err := os.MkdirAll(chartsDir, 0o755); err != nil {
+		return "", err
+	}
+	for _, dep := range missing {
+		if err := downloadDependency(chartsDir, chartDir, dep, src); err != nil {
+			return "", fmt.Errorf("failed to download dependency %s %s of chart %s: %w", dep.Name, dep.Version,
+				c.Name(), err)
+		}
+	}
+
+	resolved, err := loader.LoadDir(copyDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to load chart %s with its dependencies: %w", c.Name(), err)
+	}
+	if missing := missingDependencies(resolved); len(missing) > 0 {
+		return "", fmt.Errorf("dependencies %s of chart %s do not match its lock file after download",
+			dependencyNames(missing), c.Name())
+	}
+	if err := os.Rename(expandDir, resolvedDir); err != nil {
+		return "", fmt.Errorf("failed to move chart into cache: %w", err)
+	}
+	log.Info("Downloaded chart dependencies", "chart", c.Name(), "dependencies", dependencyNames(missing))
+	return cachedChartDir(resolvedDir)
+}
+
+// verifyLock returns an error if the lock file of c is out of sync with the
+// dependencies of c, which happens when Chart.yaml is changed without running
+// `helm dependency update`.
+func verifyLock(c *chart.Chart) error {
+	digest, err := lockDigest(c.Metadata.Dependencies, c.Lock.Dependencies)
+	if err == nil && digest == c.Lock.Digest {
+		return nil
+	}
+	if c.Metadata.APIVersion == chart.APIVersionV1 {
+		// Lock files of apiVersion v1 charts may have been created by Helm 2.
+		if digest, err := lockDigestV2(c.Metadata.Dependencies); err == nil && digest == c.Lock.Digest {
+			return nil
+		}
+	}
+	for _, dep := range c.Metadata.Dependencies {
+		if strings.HasPrefix(dep.Repository, "@") || strings.HasPrefix(dep.Repository, "alias:") {
+			return fmt.Errorf("dependency %s of chart %s references repository alias %q: use the repository URL",
+				dep.Name, c.Name(), dep.Repository)
+		}
+	}
+	return fmt.Errorf("the lock file of chart %s is out of sync with its dependencies: "+
+		"run `helm dependency update` to update it", c.Name())
+}
+
+// lockDigest returns the digest of the dependencies and locked dependencies
+// of a chart, as recorded by Helm in lock files.
+func lockDigest(req, lock []*chart.Dependency) (string, error) {
+	data, err := json.Marshal([2][]*chart.Dependency{req, lock})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return digestPrefix + hex.EncodeToString(sum[:]), nil
+}
+
+// lockDigestV2 returns the digest of the dependencies of a chart, as recorded
+// by Helm 2 in lock files.
+func lockDigestV2(req []*chart.Dependency) (string, error) {
+	data, err := json.Marshal(map[string][]*chart.Dependency{"dependencies": req})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return digestPrefix + hex.EncodeToString(sum[:]), nil
+}
+
+// missingDependencies returns the dependencies of c that are not in its
+// charts directory. If c has a lock file, they are its locked dependencies
+// that are not vendored at the locked version; otherwise they are its
+// dependencies that are not vendored at a version in their range.
+func missingDependencies(c *chart.Chart) []*chart.Dependency {
+	vendored := func(name string, match func(version string) bool) bool {
+		for _, sub := range c.Dependencies() {
+			if sub.Name() == name && match(sub.Metadata.Version) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var missing []*chart.Dependency
+	if c.Lock != nil {
+		for _, dep := range c.Lock.Dependencies {
+			if !vendored(dep.Name, func(version string) bool { return version == dep.Version }) {
+				missing = append(missing, dep)
+			}
+		}
+		return missing
+	}
+	for _, dep := range c.Metadata.Dependencies {
+		if !vendored(dep.Name, func(version string) bool { return chartutil.IsCompatibleRange(dep.Version, version) }) {
+			missing = append(missing, dep)
+		}
+	}
+	return missing
+}
+
+func dependencyNames(deps []*chart.Dependency) string {
+	names := make([]string, 0, len(deps))
+	for _, dep := range deps {
+		names = append(names, dep.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// downloadDependency downloads the archive of the locked dependency dep of
+// the chart at chartDir into chartsDir. Dependencies in local directories,
+// referenced by file:// repositories, are archived instead.
+func downloadDependency(chartsDir, chartDir string, dep *chart.Dependency, src Source) error {
+	switch {
+	case dep.Repository == "":
+		return fmt.Errorf("it has no repository and is not in the charts directory")
+	case strings.HasPrefix(dep.Repository, "file://"):
+		path := strings.TrimPrefix(dep.Repository, "file://")
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(chartDir, path)
+		}
+		c, err := loader.Load(path)
+		if err != nil {
+			return err
+		}
+		if c.Metadata.Version != dep.Version {
+			return fmt.Errorf("chart in %s has version %s", path, c.Metadata.Version)
+		}
+		_, err = chartutil.Save(c, chartsDir)
+		return err
+	case registry.IsOCI(dep.Repository):
+		_, err := download(chartsDir, Source{
+			Chart:      strings.TrimSuffix(dep.Repository, "/") + "/" + dep.Name,
+			Version:    dep.Version,
+			PullSecret: src.PullSecret,
+			PlainHTTP:  src.PlainHTTP,
+		})
+		return err
+	default:
+		_, err := download(chartsDir, Source{
+			Chart:      dep.Name,
+			Repo:       dep.Repository,
+			Version:    dep.Version,
+			PullSecret: src.PullSecret,
+		})
+		return err
+	}
+}
+
+// dirDigest returns the hex-encoded sha256 digest of the paths and contents
+// of the files in dir.
+func dirDigest(dir string) (string, error) {
+	h := sha256.New()
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(h, "%s\x00", filepath.ToSlash(rel))
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		_, err = io.Copy(h, file)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// copyChartDir copies the files of the chart in src into dst.
+func copyChartDir(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0o755)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, data, 0o644)
+	})
+}
@@ -0,0 +1,118 @@
+// Copyright 2026 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chartsource
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/downloader"
+	"sigs.k8s.io/yaml"
+)
+
+// writeChart writes a chart named name with dependencies deps into dir.
+func writeChart(t *testing.T, dir, name string, deps ...*chart.Dependency) string {
+	chartDir := filepath.Join(dir, name)
+	require.NoError(t, os.MkdirAll(filepath.Join(chartDir, "templates"), 0o755))
+	require.NoError(t, chartutil.SaveChartfile(filepath.Join(chartDir, chartutil.ChartfileName), &chart.Metadata{
+		APIVersion:   chart.APIVersionV2,
+		Name:         name,
+		Version:      "0.1.0",
+		Dependencies: deps,
+	}))
+	return chartDir
+}
+
+// writeLock writes the lock file of the chart in chartDir with the locked
+// dependencies lock.
+func writeLock(t *testing.T, chartDir string, req []*chart.Dependency, lock ...*chart.Dependency) {
+	digest, err := lockDigest(req, lock)
+	require.NoError(t, err)
+	data, err := yaml.Marshal(&chart.Lock{Generated: time.Now(), Digest: digest, Dependencies: lock})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(chartDir, "Chart.lock"), data, 0o644))
+}
+
+func TestResolveDependenciesLocal(t *testing.T) {
+	dir := t.TempDir()
+	writeChart(t, dir, "sub")
+	chartDir := writeChart(t, dir, "parent", &chart.Dependency{
+		Name:       "sub",
+		Version:    "~0.1.0",
+		Repository: "file://../sub",
+		Condition:  "sub.enabled",
+	})
+	f := NewFetcher(t.TempDir())
+
+	// The lock file is created and the dependency vendored by Helm, so that
+	// the digest of the lock file is computed like `helm dependency update`.
+	m := &downloader.Manager{Out: io.Discard, ChartPath: chartDir, RepositoryConfig: filepath.Join(dir, "repositories.yaml"),
+		RepositoryCache: dir, SkipUpdate: true}
+	require.NoError(t, m.Update())
+	resolvedDir, err := f.ResolveDependencies(chartDir, Source{})
+	require.NoError(t, err)
+	assert.Equal(t, chartDir, resolvedDir)
+
+	// Missing dependencies are resolved from the lock file into a copy of the
+	// chart.
+	require.NoError(t, os.RemoveAll(filepath.Join(chartDir, "charts")))
+	resolvedDir, err = f.ResolveDependencies(chartDir, Source{})
+	require.NoError(t, err)
+	assert.NotEqual(t, chartDir, resolvedDir)
+	assert.FileExists(t, filepath.Join(resolvedDir, "charts", "sub-0.1.0.tgz"))
+	assert.NoDirExists(t, filepath.Join(chartDir, "charts"))
+	cachedDir, err := f.ResolveDependencies(chartDir, Source{})
+	require.NoError(t, err)
+	assert.Equal(t, resolvedDir, cachedDir)
+
+	// The lock file must be updated when the dependencies change.
+	writeChart(t, dir, "parent", &chart.Dependency{Name: "sub", Version: "~0.2.0", Repository: "file://../sub"})
+	_, err = f.ResolveDependencies(chartDir, Source{})
+	assert.ErrorContains(t, err, "the lock file of chart parent is out of sync with its dependencies")
+
+	// Missing dependencies cannot be resolved without a lock file.
+	require.NoError(t, os.Remove(filepath.Join(chartDir, "Chart.lock")))
+	_, err = f.ResolveDependencies(chartDir, Source{})
+	assert.ErrorContains(t, err, "chart parent has missing dependencies sub and no lock file")
+}
+
+func TestResolveDependenciesRepo(t *testing.T) {
+	archive, err := os.ReadFile(testChartArchive)
+	require.NoError(t, err)
+	srv := newTestRepo(t, archive, "", "")
+	defer srv.Close()
+
+	req := []*chart.Dependency{{Name: "test-chart", Version: "^1.2.0", Repository: srv.URL, Alias: "test"}}
+	chartDir := writeChart(t, t.TempDir(), "parent", req...)
+	writeLock(t, chartDir, req, &chart.Dependency{Name: "test-chart", Version: "1.2.3", Repository: srv.URL})
+
+	resolvedDir, err := NewFetcher(t.TempDir()).ResolveDependencies(chartDir, Source{})
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(resolvedDir, "charts", "test-chart-1.2.3.tgz"))
+}
+
+func TestResolveDependenciesNone(t *testing.T) {
+	chartDir := writeChart(t, t.TempDir(), "standalone")
+	resolvedDir, err := NewFetcher(t.TempDir()).ResolveDependencies(chartDir, Source{})
+	require.NoError(t, err)
+	assert.Equal(t, chartDir, resolvedDir)
+}
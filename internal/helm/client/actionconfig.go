@@ -26,7 +26,7 @@ import (
 	"github.com/go-logr/logr"
 	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/kube"
-	"helm.sh/helm/v3/pkg/storage"
+	helmstorage "helm.sh/helm/v3/pkg/storage"
 	"helm.sh/helm/v3/pkg/storage/driver"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -34,13 +34,31 @@ import (
 	v1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/operator-framework/operator-sdk/internal/helm/storage"
 )
 
+// ActionConfigGetter returns the Helm action configuration used to manage the
+// release of an object in namespace. The release records are stored in
+// namespace, and owned by the object.
 type ActionConfigGetter interface {
-	ActionConfigFor(obj client.Object) (*action.Configuration, error)
+	ActionConfigFor(obj client.Object, namespace string) (*action.Configuration, error)
 }
 
-func NewActionConfigGetter(cfg *rest.Config, rm meta.RESTMapper, log logr.Logger) (ActionConfigGetter, error) {
+// ActionConfigGetterOption configures an ActionConfigGetter.
+type ActionConfigGetterOption func(*actionConfigGetter)
+
+// WithStorage configures the backend in which release records are stored.
+// db is the database of the SQL backend. By default, release records are
+// stored in Secrets.
+func WithStorage(backend storage.Backend, db *storage.SQL) ActionConfigGetterOption {
+	return func(acg *actionConfigGetter) {
+		acg.storageBackend = backend
+		acg.sqlStorage = db
+	}
+}
+
+func NewActionConfigGetter(cfg *rest.Config, rm meta.RESTMapper, log logr.Logger, opts ...ActionConfigGetterOption) (ActionConfigGetter, error) {
 	rcg := newRESTClientGetter(cfg, rm, "")
 	// Setup the debug log function that Helm will use
 	debugLog := func(format string, v ...any) {
@@ -57,14 +75,21 @@ func NewActionConfigGetter(cfg *rest.Config, rm meta.RESTMapper, log logr.Logger
 		return nil, fmt.Errorf("creating kubernetes client set: %w", err)
 	}
 
-	return &actionConfigGetter{
+	acg := &actionConfigGetter{
 		kubeClient:          kc,
 		kubeClientSet:       kcs,
 		debugLog:            debugLog,
 		restClientGetter:    rcg.restClientGetter,
 		watchedSecrets:      map[string]*WatchedSecrets{},
 		watchedSecretsMutex: &sync.Mutex{},
-	}, nil
+	}
+	for _, opt := range opts {
+		opt(acg)
+	}
+	if acg.storageBackend.IsSQL() && acg.sqlStorage == nil {
+		return nil, fmt.Errorf("a database is required for the %q release storage backend", acg.storageBackend)
+	}
+	return acg, nil
 }
 
 var _ ActionConfigGetter = &actionConfigGetter{}
@@ -76,6 +101,8 @@ type actionConfigGetter struct {
 	restClientGetter    *restClientGetter
 	watchedSecrets      map[string]*WatchedSecrets
 	watchedSecretsMutex *sync.Mutex
+	storageBackend      storage.Backend
+	sqlStorage          *storage.SQL
 }
 
 // Creates a new watcher for each namespace to not require cluster-wide secret access
@@ -89,22 +116,16 @@ func (acg *actionConfigGetter) getWatchedSecretsForNamespace(namespace string) *
 	return acg.watchedSecrets[namespace]
 }
 
-func (acg *actionConfigGetter) ActionConfigFor(obj client.Object) (*action.Configuration, error) {
-	watchedSecrets := acg.getWatchedSecretsForNamespace(obj.GetNamespace())
-	ownerRef := metav1.NewControllerRef(obj, obj.GetObjectKind().GroupVersionKind())
-	d := driver.NewSecrets(&ownerRefSecretClient{
-		SecretInterface: watchedSecrets,
-		refs:            []metav1.OwnerReference{*ownerRef},
-	})
-
-	// Also, use the debug log for the storage driver
-	d.Log = acg.debugLog
-
+func (acg *actionConfigGetter) ActionConfigFor(obj client.Object, namespace string) (*action.Configuration, error) {
 	// Initialize the storage backend
-	s := storage.Init(d)
+	d, err := acg.storageDriver(obj, namespace)
+	if err != nil {
+		return nil, err
+	}
+	s := helmstorage.Init(d)
 
 	kubeClient := *acg.kubeClient
-	kubeClient.Namespace = obj.GetNamespace()
+	kubeClient.Namespace = namespace
 
 	ownerRefClient, err := NewOwnerRefInjectingClient(&kubeClient, acg.restClientGetter.restMapper, obj)
 	if err != nil {
@@ -112,13 +133,43 @@ func (acg *actionConfigGetter) ActionConfigFor(obj client.Object) (*action.Confi
 	}
 
 	return &action.Configuration{
-		RESTClientGetter: acg.restClientGetter.ForNamespace(obj.GetNamespace()),
+		RESTClientGetter: acg.restClientGetter.ForNamespace(namespace),
 		Releases:         s,
 		KubeClient:       ownerRefClient,
 		Log:              acg.debugLog,
 	}, nil
 }
 
+// storageDriver returns the driver storing the release records of obj in
+// namespace. Release records stored in Kubernetes objects are owned by obj,
+// unless they are in another namespace than obj, since a namespaced owner
+// cannot own resources in other namespaces.
+func (acg *actionConfigGetter) storageDriver(obj client.Object, namespace string) (driver.Driver, error) {
+	var refs []metav1.OwnerReference
+	if obj.GetNamespace() == "" || obj.GetNamespace() == namespace {
+		refs = append(refs, *metav1.NewControllerRef(obj, obj.GetObjectKind().GroupVersionKind()))
+	}
+	switch acg.storageBackend {
+	case storage.BackendConfigMap:
+		d := driver.NewConfigMaps(&ownerRefConfigMapClient{
+			ConfigMapInterface: acg.kubeClientSet.CoreV1().ConfigMaps(namespace),
+			refs:               refs,
+		})
+		// Also, use the debug log for the storage driver
+		d.Log = acg.debugLog
+		return d, nil
+	case storage.BackendSQL, storage.BackendSQLite:
+		return acg.sqlStorage.Driver(namespace)
+	default:
+		d := driver.NewSecrets(&ownerRefSecretClient{
+			SecretInterface: acg.getWatchedSecretsForNamespace(namespace),
+			refs:            refs,
+		})
+		d.Log = acg.debugLog
+		return d, nil
+	}
+}
+
 var _ v1.SecretInterface = &ownerRefSecretClient{}
 
 type ownerRefSecretClient struct {
@@ -135,3 +186,20 @@ func (c *ownerRefSecretClient) Update(ctx context.Context, in *corev1.Secret, op
 	in.OwnerReferences = append(in.OwnerReferences, c.refs...)
 	return c.SecretInterface.Update(ctx, in, opts)
 }
+
+var _ v1.ConfigMapInterface = &ownerRefConfigMapClient{}
+
+type ownerRefConfigMapClient struct {
+	v1.ConfigMapInterface
+	refs []metav1.OwnerReference
+}
+
+func (c *ownerRefConfigMapClient) Create(ctx context.Context, in *corev1.ConfigMap, opts metav1.CreateOptions) (*corev1.ConfigMap, error) {
+	in.OwnerReferences = append(in.OwnerReferences, c.refs...)
+	return c.ConfigMapInterface.Create(ctx, in, opts)
+}
+
+func (c *ownerRefConfigMapClient) Update(ctx context.Context, in *corev1.ConfigMap, opts metav1.UpdateOptions) (*corev1.ConfigMap, error) {
+	in.OwnerReferences = append(in.OwnerReferences, c.refs...)
+	return c.ConfigMapInterface.Update(ctx, in, opts)
+}
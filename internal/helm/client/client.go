@@ -15,11 +15,16 @@
 package client
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"io"
+	"maps"
 	"strings"
+	"time"
 
 	"github.com/operator-framework/operator-lib/handler"
+	"github.com/operator-framework/operator-sdk/internal/helm/images"
 	"github.com/operator-framework/operator-sdk/internal/util/k8sutil"
 	"helm.sh/helm/v3/pkg/kube"
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -147,3 +152,72 @@ func (c *labelInjectingClient) Build(reader io.Reader, validate bool) (kube.Reso
 	}
 	return resourceList, nil
 }
+
+// imageRewriteTimeout bounds the time spent rewriting the images of a
+// manifest, including the resolution of their digests.
+const imageRewriteTimeout = 2 * time.Minute
+
+type imageRewritingClient struct {
+	kube.Interface
+	ctx      context.Context
+	rewriter *images.Rewriter
+	pins     func(manifest string) images.Pins
+	applied  images.Pins
+}
+
+// NewImageRewritingClient returns a client rewriting the images of the pods
+// and workloads it builds with rewriter, so that they are pulled from
+// mirrors, and pinned to digests. Since Helm builds the manifests of releases
+// before applying them, the manifests recorded in releases keep the images
+// rendered from their chart. Images are rewritten within ctx, with the pins
+// returned by pins for the manifest built, if pins is not nil. The images
+// rewritten are recorded in applied, if it is not nil.
+func NewImageRewritingClient(ctx context.Context, base kube.Interface, rewriter *images.Rewriter,
+	pins func(manifest string) images.Pins, applied images.Pins) kube.Interface {
+	return &imageRewritingClient{
+		Interface: base,
+		ctx:       ctx,
+		rewriter:  rewriter,
+		pins:      pins,
+		applied:   applied,
+	}
+}
+
+func (c *imageRewritingClient) Build(reader io.Reader, validate bool) (kube.ResourceList, error) {
+	manifest, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	resourceList, err := c.Interface.Build(bytes.NewReader(manifest), validate)
+	if err != nil {
+		return resourceList, err
+	}
+	// Images used by several resources are resolved once.
+	pins := images.Pins{}
+	if c.pins != nil {
+		maps.Copy(pins, c.pins(string(manifest)))
+	}
+	ctx, cancel := context.WithTimeout(c.ctx, imageRewriteTimeout)
+	defer cancel()
+	err = resourceList.Visit(func(r *resource.Info, err error) error {
+		if err != nil {
+			return err
+		}
+		objMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(r.Object)
+		if err != nil {
+			return err
+		}
+		rewrites, err := c.rewriter.RewriteObject(ctx, &unstructured.Unstructured{Object: objMap}, pins)
+		for _, rw := range rewrites {
+			pins[rw.Source] = rw.Image
+			if c.applied != nil {
+				c.applied[rw.Source] = rw.Image
+			}
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resourceList, nil
+}
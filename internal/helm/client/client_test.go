@@ -15,11 +15,17 @@
 package client
 
 import (
+	"context"
+	"io"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"helm.sh/helm/v3/pkg/kube"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/operator-framework/operator-sdk/internal/helm/images"
 )
 
 func TestContainsResourcePolicyKeep(t *testing.T) {
@@ -77,3 +83,45 @@ func TestContainsResourcePolicyKeep(t *testing.T) {
 		assert.Equal(t, test.expectedVal, containsResourcePolicyKeep(test.input), test.name)
 	}
 }
+
+// buildingClient is a kube.Interface whose Build returns resources.
+type buildingClient struct {
+	kube.Interface
+	resources kube.ResourceList
+}
+
+func (c buildingClient) Build(io.Reader, bool) (kube.ResourceList, error) {
+	return c.resources, nil
+}
+
+func TestImageRewritingClient(t *testing.T) {
+	deployment := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]any{"name": "test"},
+		"spec": map[string]any{"template": map[string]any{"spec": map[string]any{
+			"containers": []any{map[string]any{"name": "app", "image": "nginx:1.27"}},
+		}}},
+	}}
+	rewriter := images.NewRewriter([]images.Mirror{
+		{Source: "docker.io/library", Mirrors: []string{"mirror.example.com/library"}},
+	}, false)
+	var pinned string
+	pins := func(manifest string) images.Pins {
+		pinned = manifest
+		return nil
+	}
+	applied := images.Pins{}
+	c := NewImageRewritingClient(context.TODO(), buildingClient{resources: kube.ResourceList{{Object: deployment}}},
+		rewriter, pins, applied)
+
+	resources, err := c.Build(strings.NewReader("kind: Deployment"), false)
+	require.NoError(t, err)
+	assert.Equal(t, "kind: Deployment", pinned)
+	assert.Equal(t, images.Pins{"nginx:1.27": "mirror.example.com/library/nginx:1.27"}, applied)
+	require.Len(t, resources, 1)
+	containers, _, err := unstructured.NestedSlice(resources[0].Object.(*unstructured.Unstructured).Object,
+		"spec", "template", "spec", "containers")
+	require.NoError(t, err)
+	assert.Equal(t, "mirror.example.com/library/nginx:1.27", containers[0].(map[string]any)["image"])
+}
@@ -0,0 +1,282 @@
+// Copyright 2026 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package config defines the configuration file of the helm-operator, which
+// configures the manager of the operator and the defaults of its watches.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
+	"sigs.k8s.io/yaml"
+
+	"github.com/operator-framework/operator-sdk/internal/helm/storage"
+)
+
+const (
+	// APIVersion is the API version of the configuration file.
+	APIVersion = "helm.sdk.operatorframework.io/v1alpha1"
+	// Kind is the kind of the configuration file.
+	Kind = "HelmOperatorConfig"
+)
+
+// Config is the configuration file of the helm-operator. Unset fields keep
+// the default of the corresponding flag.
+type Config struct {
+	metav1.TypeMeta `json:",inline"`
+
+	Metrics        Metrics        `json:"metrics,omitempty"`
+	Health         Health         `json:"health,omitempty"`
+	LeaderElection LeaderElection `json:"leaderElection,omitempty"`
+	Cache          Cache          `json:"cache,omitempty"`
+	WatchDefaults  WatchDefaults  `json:"watchDefaults,omitempty"`
+}
+
+// Metrics configures the metrics endpoint.
+type Metrics struct {
+	// BindAddress is the address the metrics endpoint binds to.
+	BindAddress string `json:"bindAddress,omitempty"`
+	// Secure serves the metrics endpoint over HTTPS.
+	Secure *bool `json:"secure,omitempty"`
+	// RequireRBAC protects the metrics endpoint with RBAC-based authentication
+	// and authorization. It requires Secure.
+	RequireRBAC *bool `json:"requireRBAC,omitempty"`
+}
+
+// Health configures the health probe endpoint.
+type Health struct {
+	// ProbeBindAddress is the address the probe endpoint binds to.
+	ProbeBindAddress string `json:"probeBindAddress,omitempty"`
+}
+
+// LeaderElection configures the leader election of the operator.
+type LeaderElection struct {
+	// Enabled ensures there is only one active operator.
+	Enabled *bool `json:"enabled,omitempty"`
+	// ID is the name of the lease holding the leader lock.
+	ID string `json:"id,omitempty"`
+	// Namespace is the namespace of the lease holding the leader lock.
+	Namespace string `json:"namespace,omitempty"`
+	// LeaseDuration is how long non-leaders wait before acquiring a lease
+	// that was not renewed.
+	LeaseDuration *metav1.Duration `json:"leaseDuration,omitempty"`
+	// RenewDeadline is how long the leader retries renewing its lease before
+	// giving it up.
+	RenewDeadline *metav1.Duration `json:"renewDeadline,omitempty"`
+	// RetryPeriod is how long candidates wait between attempts to acquire or
+	// renew the lease.
+	RetryPeriod *metav1.Duration `json:"retryPeriod,omitempty"`
+}
+
+// Cache configures the cache of the resources watched by the operator.
+type Cache struct {
+	// SyncPeriod is the period at which watched resources are resynced.
+	SyncPeriod *metav1.Duration `json:"syncPeriod,omitempty"`
+	// Namespaces are the namespaces watched by the operator. All namespaces
+	// are watched if it is empty. The WATCH_NAMESPACE environment variable
+	// takes precedence.
+	Namespaces []string `json:"namespaces,omitempty"`
+}
+
+// WatchDefaults are the defaults of the settings of watches, which the
+// watches file can override.
+type WatchDefaults struct {
+	ReconcilePeriod         *metav1.Duration `json:"reconcilePeriod,omitempty"`
+	MaxConcurrentReconciles *int             `json:"maxConcurrentReconciles,omitempty"`
+	MaxReleaseHistory       *int             `json:"maxReleaseHistory,omitempty"`
+	ReleaseStorage          storage.Backend  `json:"releaseStorage,omitempty"`
+	ServerSideApply         *bool            `json:"serverSideApply,omitempty"`
+	ForceConflicts          *bool            `json:"forceConflicts,omitempty"`
+}
+
+// Load loads and validates the configuration file at path.
+func Load(path string) (*Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open config file: %w", err)
+	}
+	defer f.Close()
+	c, err := LoadReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// LoadReader loads and validates a configuration file from reader. Unknown
+// fields are rejected.
+func LoadReader(reader io.Reader) (*Config, error) {
+	b, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	c := &Config{}
+	if err := yaml.UnmarshalStrict(b, c); err != nil {
+		return nil, err
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Validate returns an error if c is invalid.
+func (c *Config) Validate() error {
+	if c.APIVersion != APIVersion || c.Kind != Kind {
+		return fmt.Errorf("unsupported apiVersion %q and kind %q: must be %q and %q", c.APIVersion, c.Kind,
+			APIVersion, Kind)
+	}
+
+	if ptr.Deref(c.Metrics.RequireRBAC, false) && !ptr.Deref(c.Metrics.Secure, false) {
+		return errors.New("metrics.requireRBAC requires metrics.secure")
+	}
+
+	le := c.LeaderElection
+	for name, d := range map[string]*metav1.Duration{
+		"leaseDuration": le.LeaseDuration,
+		"renewDeadline": le.RenewDeadline,
+		"retryPeriod":   le.RetryPeriod,
+	} {
+		if d != nil && d.Duration <= 0 {
+			return fmt.Errorf("leaderElection.%s must be positive", name)
+		}
+	}
+	if le.LeaseDuration != nil && le.RenewDeadline != nil && le.RenewDeadline.Duration >= le.LeaseDuration.Duration {
+		return errors.New("leaderElection.renewDeadline must be less than leaderElection.leaseDuration")
+	}
+	if le.Namespace != "" {
+		if errs := validation.IsDNS1123Label(le.Namespace); len(errs) > 0 {
+			return fmt.Errorf("invalid leaderElection.namespace %q: %s", le.Namespace, strings.Join(errs, ", "))
+		}
+	}
+
+	if c.Cache.SyncPeriod != nil && c.Cache.SyncPeriod.Duration <= 0 {
+		return errors.New("cache.syncPeriod must be positive")
+	}
+	for _, ns := range c.Cache.Namespaces {
+		if errs := validation.IsDNS1123Label(ns); len(errs) > 0 {
+			return fmt.Errorf("invalid namespace %q in cache.namespaces: %s", ns, strings.Join(errs, ", "))
+		}
+	}
+
+	d := c.WatchDefaults
+	if d.ReconcilePeriod != nil && d.ReconcilePeriod.Duration < 0 {
+		return errors.New("watchDefaults.reconcilePeriod must not be negative")
+	}
+	if d.MaxConcurrentReconciles != nil && *d.MaxConcurrentReconciles <= 0 {
+		return errors.New("watchDefaults.maxConcurrentReconciles must be positive")
+	}
+	if d.MaxReleaseHistory != nil && *d.MaxReleaseHistory < 0 {
+		return errors.New("watchDefaults.maxReleaseHistory must not be negative")
+	}
+	if err := d.ReleaseStorage.Validate(); err != nil {
+		return fmt.Errorf("invalid watchDefaults.releaseStorage: %w", err)
+	}
+	return nil
+}
+
+// ToManagerOptions sets the options configured by c. Flags set in the CLI
+// take precedence over them, as they are applied later by
+// flags.Flags.ToManagerOptions. The namespaces of c are configured
+// separately, since the WATCH_NAMESPACE environment variable takes precedence
+// over them.
+func (c *Config) ToManagerOptions(options manager.Options) manager.Options {
+	if c.Metrics.BindAddress != "" {
+		options.Metrics.BindAddress = c.Metrics.BindAddress
+	}
+	if c.Metrics.Secure != nil {
+		options.Metrics.SecureServing = *c.Metrics.Secure
+	}
+	if ptr.Deref(c.Metrics.RequireRBAC, false) {
+		options.Metrics.FilterProvider = filters.WithAuthenticationAndAuthorization
+	}
+	if c.Health.ProbeBindAddress != "" {
+		options.HealthProbeBindAddress = c.Health.ProbeBindAddress
+	}
+
+	le := c.LeaderElection
+	if le.Enabled != nil {
+		options.LeaderElection = *le.Enabled
+	}
+	if le.ID != "" {
+		options.LeaderElectionID = le.ID
+	}
+	if le.Namespace != "" {
+		options.LeaderElectionNamespace = le.Namespace
+	}
+	if le.LeaseDuration != nil {
+		options.LeaseDuration = &le.LeaseDuration.Duration
+	}
+	if le.RenewDeadline != nil {
+		options.RenewDeadline = &le.RenewDeadline.Duration
+	}
+	if le.RetryPeriod != nil {
+		options.RetryPeriod = &le.RetryPeriod.Duration
+	}
+
+	if c.Cache.SyncPeriod != nil {
+		options.Cache.SyncPeriod = &c.Cache.SyncPeriod.Duration
+	}
+	return options
+}
+
+// FromManagerOptions returns the configuration file equivalent to options and
+// the watch defaults d, so that the effective configuration of the operator
+// can be printed.
+func FromManagerOptions(options manager.Options, d WatchDefaults) *Config {
+	c := &Config{
+		TypeMeta: metav1.TypeMeta{APIVersion: APIVersion, Kind: Kind},
+		Metrics: Metrics{
+			BindAddress: options.Metrics.BindAddress,
+			Secure:      ptr.To(options.Metrics.SecureServing),
+			RequireRBAC: ptr.To(options.Metrics.FilterProvider != nil),
+		},
+		Health: Health{ProbeBindAddress: options.HealthProbeBindAddress},
+		LeaderElection: LeaderElection{
+			Enabled:       ptr.To(options.LeaderElection),
+			ID:            options.LeaderElectionID,
+			Namespace:     options.LeaderElectionNamespace,
+			LeaseDuration: toDuration(options.LeaseDuration),
+			RenewDeadline: toDuration(options.RenewDeadline),
+			RetryPeriod:   toDuration(options.RetryPeriod),
+		},
+		Cache:         Cache{SyncPeriod: toDuration(options.Cache.SyncPeriod)},
+		WatchDefaults: d,
+	}
+	for ns := range options.Cache.DefaultNamespaces {
+		if ns != metav1.NamespaceAll {
+			c.Cache.Namespaces = append(c.Cache.Namespaces, ns)
+		}
+	}
+	sort.Strings(c.Cache.Namespaces)
+	return c
+}
+
+func toDuration(d *time.Duration) *metav1.Duration {
+	if d == nil {
+		return nil
+	}
+	return &metav1.Duration{Duration: *d}
+}
@@ -0,0 +1,193 @@
+// Copyright 2026 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/yaml"
+
+	"github.com/operator-framework/operator-sdk/internal/helm/storage"
+)
+
+const validConfig = `apiVersion: helm.sdk.operatorframework.io/v1alpha1
+kind: HelmOperatorConfig
+metrics:
+  bindAddress: ":8443"
+  secure: true
+  requireRBAC: true
+health:
+  probeBindAddress: ":8081"
+leaderElection:
+  enabled: true
+  id: memcached-operator
+  namespace: operators
+  leaseDuration: 30s
+  renewDeadline: 20s
+  retryPeriod: 5s
+cache:
+  syncPeriod: 1h
+  namespaces: [default, apps]
+watchDefaults:
+  reconcilePeriod: 5m
+  maxConcurrentReconciles: 4
+  maxReleaseHistory: 3
+  releaseStorage: configmap
+  serverSideApply: true
+  forceConflicts: false
+`
+
+func TestLoadReader(t *testing.T) {
+	c, err := LoadReader(strings.NewReader(validConfig))
+	require.NoError(t, err)
+	assert.Equal(t, ":8443", c.Metrics.BindAddress)
+	assert.Equal(t, []string{"default", "apps"}, c.Cache.Namespaces)
+	assert.Equal(t, WatchDefaults{
+		ReconcilePeriod:         &metav1.Duration{Duration: 5 * time.Minute},
+		MaxConcurrentReconciles: ptr.To(4),
+		MaxReleaseHistory:       ptr.To(3),
+		ReleaseStorage:          storage.BackendConfigMap,
+		ServerSideApply:         ptr.To(true),
+		ForceConflicts:          ptr.To(false),
+	}, c.WatchDefaults)
+
+	header := "apiVersion: helm.sdk.operatorframework.io/v1alpha1\nkind: HelmOperatorConfig\n"
+	for _, tc := range []struct {
+		name   string
+		config string
+		err    string
+	}{
+		{
+			name:   "empty",
+			config: "",
+			err:    `unsupported apiVersion "" and kind ""`,
+		},
+		{
+			name:   "legacy component config",
+			config: "apiVersion: controller-runtime.sigs.k8s.io/v1alpha1\nkind: ControllerManagerConfig\n",
+			err:    `unsupported apiVersion "controller-runtime.sigs.k8s.io/v1alpha1" and kind "ControllerManagerConfig"`,
+		},
+		{
+			name:   "unknown field",
+			config: header + "metrics:\n  bindAddr: :8080\n",
+			err:    `unknown field "bindAddr"`,
+		},
+		{
+			name:   "RBAC without secure metrics",
+			config: header + "metrics:\n  requireRBAC: true\n",
+			err:    "metrics.requireRBAC requires metrics.secure",
+		},
+		{
+			name:   "non-positive lease duration",
+			config: header + "leaderElection:\n  leaseDuration: 0s\n",
+			err:    "leaderElection.leaseDuration must be positive",
+		},
+		{
+			name:   "renew deadline exceeding lease duration",
+			config: header + "leaderElection:\n  leaseDuration: 10s\n  renewDeadline: 15s\n",
+			err:    "leaderElection.renewDeadline must be less than leaderElection.leaseDuration",
+		},
+		{
+			name:   "invalid namespace",
+			config: header + "cache:\n  namespaces: [Default]\n",
+			err:    `invalid namespace "Default" in cache.namespaces`,
+		},
+		{
+			name:   "non-positive sync period",
+			config: header + "cache:\n  syncPeriod: -1m\n",
+			err:    "cache.syncPeriod must be positive",
+		},
+		{
+			name:   "zero max concurrent reconciles",
+			config: header + "watchDefaults:\n  maxConcurrentReconciles: 0\n",
+			err:    "watchDefaults.maxConcurrentReconciles must be positive",
+		},
+		{
+			name:   "negative max release history",
+			config: header + "watchDefaults:\n  maxReleaseHistory: -1\n",
+			err:    "watchDefaults.maxReleaseHistory must not be negative",
+		},
+		{
+			name:   "unsupported release storage",
+			config: header + "watchDefaults:\n  releaseStorage: etcd\n",
+			err:    `invalid watchDefaults.releaseStorage: unsupported release storage backend "etcd"`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := LoadReader(strings.NewReader(tc.config))
+			assert.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("apiVersion: v1\nkind: ConfigMap\n"), 0o600))
+	_, err := Load(path)
+	assert.ErrorContains(t, err, "invalid config file "+path)
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorContains(t, err, "could not open config file")
+}
+
+func TestToManagerOptions(t *testing.T) {
+	c, err := LoadReader(strings.NewReader(validConfig))
+	require.NoError(t, err)
+	options := c.ToManagerOptions(manager.Options{HealthProbeBindAddress: ":9090"})
+	assert.Equal(t, ":8443", options.Metrics.BindAddress)
+	assert.True(t, options.Metrics.SecureServing)
+	assert.NotNil(t, options.Metrics.FilterProvider)
+	assert.Equal(t, ":8081", options.HealthProbeBindAddress)
+	assert.True(t, options.LeaderElection)
+	assert.Equal(t, "memcached-operator", options.LeaderElectionID)
+	assert.Equal(t, "operators", options.LeaderElectionNamespace)
+	assert.Equal(t, ptr.To(30*time.Second), options.LeaseDuration)
+	assert.Equal(t, ptr.To(20*time.Second), options.RenewDeadline)
+	assert.Equal(t, ptr.To(5*time.Second), options.RetryPeriod)
+	assert.Equal(t, ptr.To(time.Hour), options.Cache.SyncPeriod)
+
+	// Unset fields do not change options.
+	options = (&Config{}).ToManagerOptions(manager.Options{HealthProbeBindAddress: ":9090"})
+	assert.Equal(t, ":9090", options.HealthProbeBindAddress)
+	assert.Nil(t, options.Cache.SyncPeriod)
+}
+
+func TestFromManagerOptions(t *testing.T) {
+	c, err := LoadReader(strings.NewReader(validConfig))
+	require.NoError(t, err)
+	options := c.ToManagerOptions(manager.Options{})
+	options.Cache.DefaultNamespaces = map[string]cache.Config{"default": {}, "apps": {}}
+
+	printed, err := yaml.Marshal(FromManagerOptions(options, c.WatchDefaults))
+	require.NoError(t, err)
+	reloaded, err := LoadReader(strings.NewReader(string(printed)))
+	require.NoError(t, err)
+	c.Cache.Namespaces = []string{"apps", "default"}
+	assert.Equal(t, c, reloaded)
+
+	// Watching all namespaces is printed as an empty list of namespaces.
+	options.Cache.DefaultNamespaces = map[string]cache.Config{metav1.NamespaceAll: {}}
+	assert.Empty(t, FromManagerOptions(options, WatchDefaults{}).Cache.Namespaces)
+}
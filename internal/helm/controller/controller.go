@@ -15,28 +15,42 @@
 package controller
 
 import (
+	"context"
 	"fmt"
 	"strings"
-	"sync"
 	"time"
 
 	rpb "helm.sh/helm/v3/pkg/release"
 	"helm.sh/helm/v3/pkg/releaseutil"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	apitypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	crthandler "sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	"sigs.k8s.io/yaml"
 
 	libhandler "github.com/operator-framework/operator-lib/handler"
 	"github.com/operator-framework/operator-lib/predicate"
+	"github.com/operator-framework/operator-sdk/internal/helm/internal/types"
+	"github.com/operator-framework/operator-sdk/internal/helm/maintenance"
+	"github.com/operator-framework/operator-sdk/internal/helm/queue"
 	"github.com/operator-framework/operator-sdk/internal/helm/release"
+	"github.com/operator-framework/operator-sdk/internal/helm/storage"
+	"github.com/operator-framework/operator-sdk/internal/helm/values"
 	"github.com/operator-framework/operator-sdk/internal/util/k8sutil"
 )
 
@@ -52,8 +66,31 @@ type WatchOptions struct {
 	OverrideValues          map[string]string
 	SuppressOverrideValues  bool
 	MaxConcurrentReconciles int
+	RateLimiter             workqueue.TypedRateLimiter[reconcile.Request]
+	Prioritizer             *queue.Prioritizer
 	Selector                metav1.LabelSelector
 	DryRunOption            string
+	ServerSideApply         bool
+	ForceConflicts          bool
+	RunTests                bool
+	TestTimeout             time.Duration
+	RollbackOnTestFailure   bool
+	Wait                    bool
+	WaitForJobs             bool
+	WaitTimeout             time.Duration
+	ChartReloads            <-chan event.GenericEvent
+	ValuesCache             cache.Cache
+	ValuesReferences        []values.Reference
+	ManifestStorage         types.ManifestStorage
+	ReleaseStorage          storage.Backend
+	MaintenanceWindow       *maintenance.Schedule
+	ReleaseNaming           types.ReleaseNaming
+	AdoptReleases           bool
+	ValidatingWebhook       bool
+	// DependentWatches tracks the dependent watches shared by the controllers
+	// of the manager. If nil, the watches of the controller are tracked on
+	// their own.
+	DependentWatches *DependentWatches
 }
 
 // Add creates a new helm operator controller and adds it to the manager
@@ -62,6 +99,7 @@ func Add(mgr manager.Manager, options WatchOptions) error {
 
 	r := &HelmOperatorReconciler{
 		Client:                 mgr.GetClient(),
+		APIReader:              mgr.GetAPIReader(),
 		EventRecorder:          mgr.GetEventRecorderFor(controllerName),
 		GVK:                    options.GVK,
 		ManagerFactory:         options.ManagerFactory,
@@ -69,12 +107,31 @@ func Add(mgr manager.Manager, options WatchOptions) error {
 		OverrideValues:         options.OverrideValues,
 		SuppressOverrideValues: options.SuppressOverrideValues,
 		DryRunOption:           options.DryRunOption,
+		ServerSideApply:        options.ServerSideApply,
+		ForceConflicts:         options.ForceConflicts,
+		RunTests:               options.RunTests,
+		TestTimeout:            options.TestTimeout,
+		RollbackOnTestFailure:  options.RollbackOnTestFailure,
+		Wait:                   options.Wait,
+		WaitForJobs:            options.WaitForJobs,
+		WaitTimeout:            options.WaitTimeout,
+		ManifestStorage:        options.ManifestStorage,
+		ReleaseStorage:         options.ReleaseStorage,
+		MaintenanceWindow:      options.MaintenanceWindow,
+		AdoptReleases:          options.AdoptReleases,
+		ReleaseNaming:          options.ReleaseNaming,
 	}
 
-	c, err := controller.New(controllerName, mgr, controller.Options{
+	controllerOpts := controller.Options{
 		Reconciler:              r,
 		MaxConcurrentReconciles: options.MaxConcurrentReconciles,
-	})
+		RateLimiter:             options.RateLimiter,
+	}
+	if options.Prioritizer != nil {
+		controllerOpts.UsePriorityQueue = ptr.To(true)
+		controllerOpts.NewQueue = queue.NewPriorityQueue(mgr.GetClient(), options.GVK, options.Prioritizer)
+	}
+	c, err := controller.New(controllerName, mgr, controllerOpts)
 	if err != nil {
 		return err
 	}
@@ -86,8 +143,37 @@ func Add(mgr manager.Manager, options WatchOptions) error {
 		return err
 	}
 
+	if options.ChartReloads != nil {
+		if err := c.Watch(source.Channel(options.ChartReloads, crthandler.EnqueueRequestsFromMapFunc(requestsForGVK(mgr.GetClient(), options.GVK)))); err != nil {
+			return err
+		}
+	}
+
+	if options.ValuesCache != nil && len(options.ValuesReferences) > 0 {
+		mapFunc := requestsForValuesReferences(mgr.GetClient(), options.GVK, options.ValuesReferences, options.ReleaseNaming)
+		for _, obj := range []client.Object{&corev1.ConfigMap{}, &corev1.Secret{}} {
+			if err := c.Watch(source.Kind(options.ValuesCache, obj, crthandler.EnqueueRequestsFromMapFunc(mapFunc))); err != nil {
+				return err
+			}
+		}
+	}
+
+	dws := options.DependentWatches
+	if dws == nil {
+		dws = NewDependentWatches(mgr.GetCache())
+	}
+	// The informer of the custom resources is shared with the dependent
+	// watches of the releases that contain custom resources of this kind.
+	dws.share(options.GVK)
 	if options.WatchDependentResources {
-		watchDependentResources(mgr, r, c)
+		watchDependentResources(mgr, r, c, dws)
+	}
+
+	if options.ValidatingWebhook {
+		path := ValidatingWebhookPath(options.GVK)
+		mgr.GetWebhookServer().Register(path, &webhook.Admission{Handler: newValidator(r)})
+		log.Info("Serving validating webhook", "apiVersion", options.GVK.GroupVersion(), "kind",
+			options.GVK.Kind, "path", path)
 	}
 
 	log.Info("Watching resource", "apiVersion", options.GVK.GroupVersion(), "kind",
@@ -95,95 +181,158 @@ func Add(mgr manager.Manager, options WatchOptions) error {
 	return nil
 }
 
+// requestsForGVK returns a map function that requests the reconciliation of
+// every custom resource of gvk, used to roll out a reloaded chart.
+func requestsForGVK(c client.Reader, gvk schema.GroupVersionKind) crthandler.MapFunc {
+	return func(ctx context.Context, _ client.Object) []reconcile.Request {
+		requests := listRequests(ctx, c, gvk, nil)
+		log.Info("Reconciling resources with reloaded chart", "apiVersion", gvk.GroupVersion(), "kind", gvk.Kind,
+			"count", len(requests))
+		return requests
+	}
+}
+
+// requestsForValuesReferences returns a map function that requests the
+// reconciliation of the custom resources of gvk whose release is in the
+// namespace of a ConfigMap or Secret referenced by their values pipeline.
+func requestsForValuesReferences(c client.Reader, gvk schema.GroupVersionKind, refs []values.Reference,
+	releaseNaming types.ReleaseNaming) crthandler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		kind := values.KindConfigMap
+		if _, ok := obj.(*corev1.Secret); ok {
+			kind = values.KindSecret
+		}
+		for _, ref := range refs {
+			if ref.Kind != kind || ref.Name != obj.GetName() {
+				continue
+			}
+			// Releases may be in another namespace than their custom
+			// resource, so all custom resources are listed.
+			return listRequests(ctx, c, gvk, func(cr *unstructured.Unstructured) bool {
+				ns, err := releaseNaming.NamespaceFor(cr)
+				return err == nil && ns == obj.GetNamespace()
+			})
+		}
+		return nil
+	}
+}
+
+// listRequests returns requests for the custom resources of gvk accepted by
+// filter, or for all of them if filter is nil.
+func listRequests(ctx context.Context, c client.Reader, gvk schema.GroupVersionKind,
+	filter func(*unstructured.Unstructured) bool) []reconcile.Request {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+	if err := c.List(ctx, list); err != nil {
+		log.Error(err, "Failed to list resources", "apiVersion", gvk.GroupVersion(), "kind", gvk.Kind)
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(list.Items))
+	for i := range list.Items {
+		if filter != nil && !filter(&list.Items[i]) {
+			continue
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&list.Items[i])})
+	}
+	return requests
+}
+
 // watchDependentResources adds a release hook function to the HelmOperatorReconciler
-// that adds watches for resources in released Helm charts.
-func watchDependentResources(mgr manager.Manager, r *HelmOperatorReconciler, c controller.Controller) {
-	var m sync.RWMutex
-	watches := map[schema.GroupVersionKind]struct{}{}
-	releaseHook := func(release *rpb.Release) error {
+// that adds watches for resources in released Helm charts. Resources that can
+// be owned by their custom resource are watched through their owner
+// references, and other resources, such as resources in another namespace,
+// through their owner annotations. The watches are tracked by dws, which stops
+// the informers of resources that no deployed release contains anymore.
+func watchDependentResources(mgr manager.Manager, r *HelmOperatorReconciler, c controller.Controller,
+	dws *DependentWatches) {
+	startWatch := func(dw dependentWatch) error {
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(dw.gvk)
+		if dw.annotations { // Setup watch using annotations.
+			return c.Watch(
+				source.Kind(
+					mgr.GetCache(),
+					client.Object(obj),
+					&libhandler.EnqueueRequestForAnnotation[client.Object]{Type: r.GVK.GroupKind()},
+					predicate.DependentPredicate{}))
+		}
+		// Setup watch using owner references.
 		owner := &unstructured.Unstructured{}
 		owner.SetGroupVersionKind(r.GVK)
-		owner.SetNamespace(release.Namespace)
-		resources := releaseutil.SplitManifests(release.Manifest)
-		for _, resource := range resources {
-			var u unstructured.Unstructured
-			if err := yaml.Unmarshal([]byte(resource), &u); err != nil {
-				return err
-			}
+		return c.Watch(
+			source.Kind(
+				mgr.GetCache(),
+				client.Object(obj),
+				crthandler.TypedEnqueueRequestForOwner[client.Object](mgr.GetScheme(), mgr.GetRESTMapper(), owner, crthandler.OnlyControllerOwner()),
+				predicate.DependentPredicate{}))
+	}
 
-			gvk := u.GroupVersionKind()
-			if gvk.Empty() {
-				continue
-			}
+	releaseHook := func(o *unstructured.Unstructured, release *rpb.Release) error {
+		key := releaseOwner{gvk: r.GVK, NamespacedName: apitypes.NamespacedName{Namespace: o.GetNamespace(), Name: o.GetName()}}
+		if release == nil {
+			return dws.update(context.Background(), key, nil, startWatch)
+		}
+		dependents, err := releaseDependentWatches(mgr.GetRESTMapper(), r.GVK, o, release)
+		if err != nil {
+			return err
+		}
+		return dws.update(context.Background(), key, dependents, startWatch)
+	}
+	r.releaseHook = releaseHook
+}
 
-			var setWatchOnResource = func(dependent runtime.Object) error {
-				unstructuredObj := dependent.(*unstructured.Unstructured)
-				gvkDependent := unstructuredObj.GroupVersionKind()
-				if gvkDependent.Empty() {
-					return nil
-				}
-
-				m.RLock()
-				_, ok := watches[gvkDependent]
-				m.RUnlock()
-				if ok {
-					return nil
-				}
-
-				restMapper := mgr.GetRESTMapper()
-				useOwnerRef, err := k8sutil.SupportsOwnerReference(restMapper, owner, dependent, "")
-				if err != nil {
-					return err
-				}
-
-				if useOwnerRef { // Setup watch using owner references.
-					err = c.Watch(
-						source.Kind(
-							mgr.GetCache(),
-							client.Object(unstructuredObj),
-							crthandler.TypedEnqueueRequestForOwner[client.Object](mgr.GetScheme(), mgr.GetRESTMapper(), owner, crthandler.OnlyControllerOwner()),
-							predicate.DependentPredicate{}))
-					if err != nil {
-						return err
-					}
-				} else { // Setup watch using annotations.
-					err = c.Watch(
-						source.Kind(
-							mgr.GetCache(),
-							client.Object(unstructuredObj),
-							&libhandler.EnqueueRequestForAnnotation[client.Object]{Type: gvkDependent.GroupKind()},
-							predicate.DependentPredicate{}))
-					if err != nil {
-						return err
-					}
-				}
-				m.Lock()
-				watches[gvkDependent] = struct{}{}
-				m.Unlock()
-				log.Info("Watching dependent resource", "ownerApiVersion", r.GVK.GroupVersion(),
-					"ownerKind", r.GVK.Kind, "apiVersion", gvkDependent.GroupVersion(), "kind", gvkDependent.Kind)
-				return nil
-			}
+// releaseDependentWatches returns the dependent watches of the resources of
+// release, owned by o.
+func releaseDependentWatches(restMapper meta.RESTMapper, gvk schema.GroupVersionKind, o *unstructured.Unstructured,
+	release *rpb.Release) ([]dependentWatch, error) {
+	owner := &unstructured.Unstructured{}
+	owner.SetGroupVersionKind(gvk)
+	owner.SetNamespace(o.GetNamespace())
 
-			// List is not actually a resource and therefore cannot have a
-			// watch on it. The watch will be on the kinds listed in the list
-			// and will therefore need to be handled individually.
-			listGVK := schema.GroupVersionKind{Group: "", Version: "v1", Kind: "List"}
-			if gvk == listGVK {
-				errListItem := u.EachListItem(func(obj runtime.Object) error {
-					return setWatchOnResource(obj)
-				})
-				if errListItem != nil {
-					return errListItem
-				}
-			} else {
-				err := setWatchOnResource(&u)
-				if err != nil {
-					return err
-				}
-			}
+	var dependents []dependentWatch
+	seen := map[dependentWatch]struct{}{}
+	addDependent := func(dependent runtime.Object) error {
+		unstructuredObj := dependent.(*unstructured.Unstructured)
+		gvkDependent := unstructuredObj.GroupVersionKind()
+		if gvkDependent.Empty() {
+			return nil
+		}
+
+		// Resources without a namespace are installed in the release
+		// namespace.
+		depNamespace := unstructuredObj.GetNamespace()
+		if depNamespace == "" {
+			depNamespace = release.Namespace
+		}
+		useOwnerRef, err := k8sutil.SupportsOwnerReference(restMapper, owner, dependent, depNamespace)
+		if err != nil {
+			return err
+		}
+		dw := dependentWatch{gvk: gvkDependent, annotations: !useOwnerRef}
+		if _, ok := seen[dw]; !ok {
+			seen[dw] = struct{}{}
+			dependents = append(dependents, dw)
 		}
 		return nil
 	}
-	r.releaseHook = releaseHook
+
+	for _, resource := range releaseutil.SplitManifests(release.Manifest) {
+		var u unstructured.Unstructured
+		if err := yaml.Unmarshal([]byte(resource), &u); err != nil {
+			return nil, err
+		}
+
+		// List is not actually a resource and therefore cannot have a
+		// watch on it. The watch will be on the kinds listed in the list
+		// and will therefore need to be handled individually.
+		listGVK := schema.GroupVersionKind{Group: "", Version: "v1", Kind: "List"}
+		if u.GroupVersionKind() == listGVK {
+			if err := u.EachListItem(addDependent); err != nil {
+				return nil, err
+			}
+		} else if err := addDependent(&u); err != nil {
+			return nil, err
+		}
+	}
+	return dependents, nil
 }
@@ -0,0 +1,61 @@
+// Copyright 2026 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	helmtypes "github.com/operator-framework/operator-sdk/internal/helm/internal/types"
+	"github.com/operator-framework/operator-sdk/internal/helm/values"
+)
+
+func TestRequestsForValuesReferences(t *testing.T) {
+	gvk := schema.GroupVersionKind{Group: "example.com", Version: "v1alpha1", Kind: "Nginx"}
+	newCR := func(name, targetNamespace string) *unstructured.Unstructured {
+		o := &unstructured.Unstructured{Object: map[string]any{
+			"spec": map[string]any{"targetNamespace": targetNamespace},
+		}}
+		o.SetGroupVersionKind(gvk)
+		o.SetName(name)
+		return o
+	}
+	c := fake.NewClientBuilder().WithObjects(newCR("a", "team-a"), newCR("b", "team-b"), newCR("c", "")).Build()
+	refs := []values.Reference{{Kind: values.KindConfigMap, Name: "shared-values"}}
+	naming, err := helmtypes.NewReleaseNaming("", "team-b", "spec.targetNamespace")
+	require.NoError(t, err)
+	mapFunc := requestsForValuesReferences(c, gvk, refs, naming)
+
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "team-b", Name: "shared-values"}}
+	assert.Equal(t, []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Name: "b"}},
+		{NamespacedName: types.NamespacedName{Name: "c"}},
+	}, mapFunc(context.TODO(), cm))
+
+	other := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "team-b", Name: "other"}}
+	assert.Empty(t, mapFunc(context.TODO(), other))
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "team-b", Name: "shared-values"}}
+	assert.Empty(t, mapFunc(context.TODO(), secret))
+}
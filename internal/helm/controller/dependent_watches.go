@@ -0,0 +1,278 @@
+// Copyright 2026 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	apitypes "k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+
+	"github.com/operator-framework/operator-sdk/internal/helm/metrics"
+)
+
+// DependentWatchesPath is the path of the debug endpoint serving the active
+// dependent watches on the metrics server.
+const DependentWatchesPath = "/debug/dependent-watches"
+
+// DependentWatches tracks the dependent resources watched by the controllers
+// of a manager. Watches of dependent resources of the same GVK share an
+// informer, which is stopped once no deployed release contains resources of
+// this GVK anymore, unless the informer is shared with other watches, such as
+// the watches of custom resources.
+type DependentWatches struct {
+	informers cache.Informers
+
+	mu sync.Mutex
+	// releases maps the GVK of each watched dependent resource to the custom
+	// resources whose deployed release contains resources of this GVK.
+	releases map[schema.GroupVersionKind]map[releaseOwner]struct{}
+	// dependents maps each custom resource to the dependent watches of its
+	// deployed release.
+	dependents map[releaseOwner][]dependentWatch
+	// watched records the dependent watches set up by the controller of each
+	// custom resource GVK.
+	watched map[schema.GroupVersionKind]map[dependentWatch]struct{}
+	// shared records the GVKs whose informers are also used by other watches
+	// than dependent watches. They are never stopped.
+	shared map[schema.GroupVersionKind]struct{}
+}
+
+// dependentWatch is a watch of dependent resources of gvk, which finds their
+// owner through owner annotations if annotations is true, and through owner
+// references otherwise.
+type dependentWatch struct {
+	gvk         schema.GroupVersionKind
+	annotations bool
+}
+
+// releaseOwner is a custom resource owning a release.
+type releaseOwner struct {
+	gvk schema.GroupVersionKind
+	apitypes.NamespacedName
+}
+
+// NewDependentWatches returns a DependentWatches stopping unused informers of
+// informers.
+func NewDependentWatches(informers cache.Informers) *DependentWatches {
+	return &DependentWatches{
+		informers:  informers,
+		releases:   map[schema.GroupVersionKind]map[releaseOwner]struct{}{},
+		dependents: map[releaseOwner][]dependentWatch{},
+		watched:    map[schema.GroupVersionKind]map[dependentWatch]struct{}{},
+		shared:     map[schema.GroupVersionKind]struct{}{},
+	}
+}
+
+// share records that the informer of gvk is used by other watches than
+// dependent watches, such as the watch of the custom resources of a
+// controller, so that it is never stopped.
+func (d *DependentWatches) share(gvk schema.GroupVersionKind) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.shared[gvk] = struct{}{}
+}
+
+// update records that the deployed release of owner contains the resources
+// watched by dependents, which is empty if owner was deleted. The watches of
+// dependents that are not set up yet are started with startWatch, and the
+// informers of the GVKs that no deployed release contains anymore are stopped.
+func (d *DependentWatches) update(ctx context.Context, owner releaseOwner, dependents []dependentWatch,
+	startWatch func(dependentWatch) error) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	watched, ok := d.watched[owner.gvk]
+	if !ok {
+		watched = map[dependentWatch]struct{}{}
+		d.watched[owner.gvk] = watched
+	}
+	for _, dw := range dependents {
+		if _, ok := watched[dw]; ok {
+			continue
+		}
+		if err := startWatch(dw); err != nil {
+			return err
+		}
+		watched[dw] = struct{}{}
+		log.Info("Watching dependent resource", "ownerApiVersion", owner.gvk.GroupVersion(),
+			"ownerKind", owner.gvk.Kind, "apiVersion", dw.gvk.GroupVersion(), "kind", dw.gvk.Kind)
+	}
+
+	previous := d.dependents[owner]
+	if len(dependents) == 0 {
+		delete(d.dependents, owner)
+	} else {
+		d.dependents[owner] = dependents
+	}
+	current := map[schema.GroupVersionKind]struct{}{}
+	for _, dw := range dependents {
+		current[dw.gvk] = struct{}{}
+		owners, ok := d.releases[dw.gvk]
+		if !ok {
+			owners = map[releaseOwner]struct{}{}
+			d.releases[dw.gvk] = owners
+		}
+		owners[owner] = struct{}{}
+		metrics.SetDependentWatch(dw.gvk, len(owners))
+	}
+
+	var errs []error
+	for _, dw := range previous {
+		if _, ok := current[dw.gvk]; ok {
+			continue
+		}
+		owners, ok := d.releases[dw.gvk]
+		if !ok {
+			// The informer of this GVK was already stopped.
+			continue
+		}
+		delete(owners, owner)
+		metrics.SetDependentWatch(dw.gvk, len(owners))
+		if len(owners) == 0 {
+			if err := d.stop(ctx, dw.gvk); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to stop dependent watches: %v", errs)
+	}
+	return nil
+}
+
+// stop stops the informer of the dependent resources of gvk, so that the
+// controllers watching them set up their watch again if a release contains
+// resources of gvk later. The informer of a shared GVK is kept, along with the
+// dependent watches using it, which must not be set up twice.
+func (d *DependentWatches) stop(ctx context.Context, gvk schema.GroupVersionKind) error {
+	delete(d.releases, gvk)
+	metrics.DeleteDependentWatch(gvk)
+	if _, ok := d.shared[gvk]; ok {
+		return nil
+	}
+	for _, watched := range d.watched {
+		for dw := range watched {
+			if dw.gvk == gvk {
+				delete(watched, dw)
+			}
+		}
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	if err := d.informers.RemoveInformer(ctx, obj); err != nil {
+		return fmt.Errorf("failed to stop informer of %s: %w", gvk, err)
+	}
+	log.Info("Stopped watching dependent resource", "apiVersion", gvk.GroupVersion(), "kind", gvk.Kind)
+	return nil
+}
+
+// ActiveDependentWatch is a watched dependent resource kind, as served by the
+// debug endpoint.
+type ActiveDependentWatch struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	// Controllers are the controllers watching resources of this kind.
+	Controllers []DependentWatchController `json:"controllers"`
+	// Releases are the custom resources whose deployed release contains
+	// resources of this kind.
+	Releases []corev1.ObjectReference `json:"releases"`
+}
+
+// DependentWatchController is the controller of a custom resource kind
+// watching dependent resources.
+type DependentWatchController struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	// Annotations is true if the owners of dependent resources are found
+	// through owner annotations rather than owner references.
+	Annotations bool `json:"annotations"`
+}
+
+// Active returns the watched dependent resource kinds, sorted by API version
+// and kind.
+func (d *DependentWatches) Active() []ActiveDependentWatch {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	active := make([]ActiveDependentWatch, 0, len(d.releases))
+	for gvk, owners := range d.releases {
+		apiVersion, kind := gvk.ToAPIVersionAndKind()
+		w := ActiveDependentWatch{APIVersion: apiVersion, Kind: kind}
+		for ownerGVK, watched := range d.watched {
+			for dw := range watched {
+				if dw.gvk != gvk {
+					continue
+				}
+				ownerAPIVersion, ownerKind := ownerGVK.ToAPIVersionAndKind()
+				w.Controllers = append(w.Controllers, DependentWatchController{
+					APIVersion:  ownerAPIVersion,
+					Kind:        ownerKind,
+					Annotations: dw.annotations,
+				})
+			}
+		}
+		for owner := range owners {
+			ownerAPIVersion, ownerKind := owner.gvk.ToAPIVersionAndKind()
+			w.Releases = append(w.Releases, corev1.ObjectReference{
+				APIVersion: ownerAPIVersion,
+				Kind:       ownerKind,
+				Namespace:  owner.Namespace,
+				Name:       owner.Name,
+			})
+		}
+		sort.Slice(w.Controllers, func(i, j int) bool {
+			a, b := w.Controllers[i], w.Controllers[j]
+			if a.APIVersion+a.Kind != b.APIVersion+b.Kind {
+				return a.APIVersion+a.Kind < b.APIVersion+b.Kind
+			}
+			return !a.Annotations && b.Annotations
+		})
+		sort.Slice(w.Releases, func(i, j int) bool {
+			return objectReferenceKey(w.Releases[i]) < objectReferenceKey(w.Releases[j])
+		})
+		active = append(active, w)
+	}
+	sort.Slice(active, func(i, j int) bool {
+		if active[i].APIVersion != active[j].APIVersion {
+			return active[i].APIVersion < active[j].APIVersion
+		}
+		return active[i].Kind < active[j].Kind
+	})
+	return active
+}
+
+func objectReferenceKey(ref corev1.ObjectReference) string {
+	return ref.APIVersion + "/" + ref.Kind + "/" + namespacedName(ref.Namespace, ref.Name)
+}
+
+// ServeHTTP serves the active dependent watches as JSON.
+func (d *DependentWatches) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(d.Active()); err != nil {
+		log.Error(err, "Failed to serve dependent watches")
+	}
+}
@@ -0,0 +1,208 @@
+// Copyright 2026 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	rpb "helm.sh/helm/v3/pkg/release"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	apitypes "k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// fakeInformers records the informers removed from a cache.
+type fakeInformers struct {
+	cache.Informers
+	removed []schema.GroupVersionKind
+}
+
+func (f *fakeInformers) RemoveInformer(_ context.Context, obj client.Object) error {
+	f.removed = append(f.removed, obj.GetObjectKind().GroupVersionKind())
+	return nil
+}
+
+func TestDependentWatchesUpdate(t *testing.T) {
+	nginx := schema.GroupVersionKind{Group: "example.com", Version: "v1alpha1", Kind: "Nginx"}
+	redis := schema.GroupVersionKind{Group: "example.com", Version: "v1alpha1", Kind: "Redis"}
+	deployments := dependentWatch{gvk: schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}}
+	configMaps := dependentWatch{gvk: schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}}
+	clusterRoles := dependentWatch{
+		gvk:         schema.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRole"},
+		annotations: true,
+	}
+	owner := func(gvk schema.GroupVersionKind, name string) releaseOwner {
+		return releaseOwner{gvk: gvk, NamespacedName: apitypes.NamespacedName{Namespace: "default", Name: name}}
+	}
+
+	informers := &fakeInformers{}
+	dws := NewDependentWatches(informers)
+	var started []dependentWatch
+	startWatch := func(dw dependentWatch) error {
+		started = append(started, dw)
+		return nil
+	}
+	update := func(o releaseOwner, dependents ...dependentWatch) {
+		require.NoError(t, dws.update(context.TODO(), o, dependents, startWatch))
+	}
+
+	update(owner(nginx, "a"), deployments, configMaps, clusterRoles)
+	update(owner(nginx, "b"), deployments)
+	update(owner(redis, "c"), configMaps)
+	assert.Equal(t, []dependentWatch{deployments, configMaps, clusterRoles, configMaps}, started)
+	active := dws.Active()
+	require.Len(t, active, 3)
+	assert.Equal(t, ActiveDependentWatch{
+		APIVersion: "v1",
+		Kind:       "ConfigMap",
+		Controllers: []DependentWatchController{
+			{APIVersion: "example.com/v1alpha1", Kind: "Nginx"},
+			{APIVersion: "example.com/v1alpha1", Kind: "Redis"},
+		},
+		Releases: []corev1.ObjectReference{
+			{APIVersion: "example.com/v1alpha1", Kind: "Nginx", Namespace: "default", Name: "a"},
+			{APIVersion: "example.com/v1alpha1", Kind: "Redis", Namespace: "default", Name: "c"},
+		},
+	}, active[2])
+	assert.Equal(t, "apps/v1", active[0].APIVersion)
+	assert.Equal(t, "rbac.authorization.k8s.io/v1", active[1].APIVersion)
+	assert.True(t, active[1].Controllers[0].Annotations)
+
+	// Informers are kept while a release contains resources of their GVK.
+	update(owner(nginx, "a"), configMaps)
+	assert.Equal(t, []schema.GroupVersionKind{clusterRoles.gvk}, informers.removed)
+	update(owner(nginx, "b"))
+	assert.Equal(t, []schema.GroupVersionKind{clusterRoles.gvk, deployments.gvk}, informers.removed)
+	require.Len(t, dws.Active(), 1)
+
+	// Stopped watches are started again when a release contains resources of
+	// their GVK.
+	started = nil
+	update(owner(nginx, "b"), deployments, configMaps)
+	assert.Equal(t, []dependentWatch{deployments}, started)
+
+	// Watches that fail to start are retried.
+	err := dws.update(context.TODO(), owner(redis, "c"), []dependentWatch{deployments},
+		func(dependentWatch) error { return errors.New("no matches for kind") })
+	assert.EqualError(t, err, "no matches for kind")
+	started = nil
+	update(owner(redis, "c"), deployments)
+	assert.Equal(t, []dependentWatch{deployments}, started)
+
+	rec := httptest.NewRecorder()
+	dws.ServeHTTP(rec, httptest.NewRequest("GET", DependentWatchesPath, nil))
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	var served []ActiveDependentWatch
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &served))
+	assert.Equal(t, dws.Active(), served)
+}
+
+func TestReleaseDependentWatches(t *testing.T) {
+	nginx := schema.GroupVersionKind{Group: "example.com", Version: "v1alpha1", Kind: "Nginx"}
+	deployment := schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}
+	configMap := schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}
+	clusterRole := schema.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRole"}
+	restMapper := meta.NewDefaultRESTMapper(nil)
+	restMapper.Add(nginx, meta.RESTScopeNamespace)
+	restMapper.Add(deployment, meta.RESTScopeNamespace)
+	restMapper.Add(configMap, meta.RESTScopeNamespace)
+	restMapper.Add(clusterRole, meta.RESTScopeRoot)
+
+	o := &unstructured.Unstructured{}
+	o.SetGroupVersionKind(nginx)
+	o.SetNamespace("default")
+	o.SetName("test")
+	rel := &rpb.Release{Namespace: "default", Manifest: `---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: test
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: test
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: test-other
+    namespace: other
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: test
+`}
+
+	dependents, err := releaseDependentWatches(restMapper, nginx, o, rel)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []dependentWatch{
+		{gvk: deployment},
+		{gvk: configMap},
+		{gvk: configMap, annotations: true},
+		{gvk: clusterRole, annotations: true},
+	}, dependents)
+}
+
+func TestDependentWatchesShared(t *testing.T) {
+	nginx := schema.GroupVersionKind{Group: "example.com", Version: "v1alpha1", Kind: "Nginx"}
+	redis := schema.GroupVersionKind{Group: "example.com", Version: "v1alpha1", Kind: "Redis"}
+	redises := dependentWatch{gvk: redis}
+	configMaps := dependentWatch{gvk: schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}}
+	owner := releaseOwner{gvk: nginx, NamespacedName: apitypes.NamespacedName{Namespace: "default", Name: "a"}}
+
+	informers := &fakeInformers{}
+	dws := NewDependentWatches(informers)
+	dws.share(nginx)
+	dws.share(redis)
+	var started []dependentWatch
+	update := func(dependents ...dependentWatch) {
+		require.NoError(t, dws.update(context.TODO(), owner, dependents, func(dw dependentWatch) error {
+			started = append(started, dw)
+			return nil
+		}))
+	}
+
+	// The chart of Nginx creates Redis custom resources, which are watched by
+	// the controller of Redis.
+	update(redises, configMaps)
+	assert.Equal(t, []dependentWatch{redises, configMaps}, started)
+
+	// The informer of Redis custom resources is kept once the release no
+	// longer contains any.
+	update()
+	assert.Equal(t, []schema.GroupVersionKind{configMaps.gvk}, informers.removed)
+	assert.Empty(t, dws.Active())
+
+	// Since its informer was kept, the dependent watch of Redis custom
+	// resources is not set up twice.
+	started = nil
+	update(redises, configMaps)
+	assert.Equal(t, []dependentWatch{configMaps}, started)
+	require.Len(t, dws.Active(), 2)
+}
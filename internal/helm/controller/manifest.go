@@ -0,0 +1,240 @@
+// Copyright 2026 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+
+	libhandler "github.com/operator-framework/operator-lib/handler"
+	rpb "helm.sh/helm/v3/pkg/release"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/operator-framework/operator-sdk/internal/helm/internal/types"
+	"github.com/operator-framework/operator-sdk/internal/helm/release"
+	"github.com/operator-framework/operator-sdk/internal/helm/storage"
+)
+
+const (
+	// manifestConfigMapKey is the key of the gzip-compressed manifest in
+	// manifest ConfigMaps.
+	manifestConfigMapKey = "manifest.gz"
+	// manifestReleaseLabel is set on manifest ConfigMaps to the name of the
+	// release.
+	manifestReleaseLabel = "helm.sdk.operatorframework.io/release"
+)
+
+// manifestDigest returns the SHA-256 digest of manifest.
+func manifestDigest(manifest string) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(manifest)))
+}
+
+// manifestConfigMapName returns the name of the ConfigMap containing the
+// manifest of a release when manifests are stored in ConfigMaps.
+func manifestConfigMapName(releaseName string) string {
+	return fmt.Sprintf("sh.helm.manifest.v1.%s", releaseName)
+}
+
+// releaseStatus returns the deployedRelease status of rel, storing its
+// manifest according to the reconciler's manifest storage. current is the
+// deployedRelease status before rel was deployed or reconciled.
+func (r HelmOperatorReconciler) releaseStatus(ctx context.Context, o *unstructured.Unstructured,
+	current *types.HelmAppRelease, rel *rpb.Release) (*types.HelmAppRelease, error) {
+	status, err := r.manifestStatus(ctx, o, current, rel)
+	if err != nil {
+		return nil, err
+	}
+	// A release in another namespace than its custom resource, such as the
+	// release of a cluster-scoped custom resource, is kept in the namespace in
+	// which it was installed.
+	if rel.Namespace != o.GetNamespace() {
+		status.Namespace = rel.Namespace
+	}
+	return status, nil
+}
+
+func (r HelmOperatorReconciler) manifestStatus(ctx context.Context, o *unstructured.Unstructured,
+	current *types.HelmAppRelease, rel *rpb.Release) (*types.HelmAppRelease, error) {
+	switch r.ManifestStorage {
+	case types.ManifestStorageRelease:
+		return &types.HelmAppRelease{
+			Name:           rel.Name,
+			Revision:       rel.Version,
+			ManifestDigest: manifestDigest(rel.Manifest),
+			ManifestRef:    releaseRecordRef(r.ReleaseStorage, rel),
+		}, nil
+	case types.ManifestStorageConfigMap:
+		status := &types.HelmAppRelease{
+			Name:           rel.Name,
+			Revision:       rel.Version,
+			ManifestDigest: manifestDigest(rel.Manifest),
+			ManifestRef: &types.HelmAppManifestRef{
+				Kind: "ConfigMap",
+				Name: manifestConfigMapName(rel.Name),
+				Key:  manifestConfigMapKey,
+			},
+		}
+		// The ConfigMap is only written when the manifest changes, rather
+		// than on every reconciliation.
+		if current != nil && current.ManifestDigest == status.ManifestDigest && current.ManifestRef != nil &&
+			*current.ManifestRef == *status.ManifestRef {
+			return status, nil
+		}
+		if err := r.storeManifest(ctx, o, status.ManifestRef.Name, rel); err != nil {
+			return nil, fmt.Errorf("failed to store release manifest: %w", err)
+		}
+		return status, nil
+	default:
+		return &types.HelmAppRelease{
+			Name:     rel.Name,
+			Manifest: rel.Manifest,
+		}, nil
+	}
+}
+
+// releaseRecordRef returns the reference of the object in which backend
+// stores the release record of rel, or nil if backend does not store release
+// records in objects, like the SQL backends.
+func releaseRecordRef(backend storage.Backend, rel *rpb.Release) *types.HelmAppManifestRef {
+	switch backend {
+	case "", storage.BackendSecret:
+		return &types.HelmAppManifestRef{Kind: "Secret", Name: release.ReleaseSecretName(rel.Name, rel.Version)}
+	case storage.BackendConfigMap:
+		return &types.HelmAppManifestRef{Kind: "ConfigMap", Name: release.ReleaseSecretName(rel.Name, rel.Version)}
+	}
+	return nil
+}
+
+// manifestInReleaseRecord returns true if the manifest of deployed is only
+// stored in its release record, rather than in the status or in a manifest
+// ConfigMap.
+func manifestInReleaseRecord(deployed *types.HelmAppRelease) bool {
+	return deployed != nil && deployed.ManifestDigest != "" && (deployed.ManifestRef == nil || deployed.ManifestRef.Key == "")
+}
+
+// storeManifest creates or replaces the ConfigMap named name, owned by o,
+// with the compressed manifest of rel, in the namespace of rel. Since a
+// namespaced owner cannot own resources in other namespaces, the ConfigMap is
+// annotated with its owner instead if it is in another namespace than o.
+func (r HelmOperatorReconciler) storeManifest(ctx context.Context, o *unstructured.Unstructured, name string, rel *rpb.Release) error {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(rel.Manifest)); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: rel.Namespace,
+			Labels:    map[string]string{manifestReleaseLabel: rel.Name},
+		},
+		BinaryData: map[string][]byte{manifestConfigMapKey: buf.Bytes()},
+	}
+	if o.GetNamespace() == "" || o.GetNamespace() == rel.Namespace {
+		cm.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(o, o.GroupVersionKind())}
+	} else if err := libhandler.SetOwnerAnnotations(o, cm); err != nil {
+		return err
+	}
+	err := r.Client.Create(ctx, cm)
+	if apierrors.IsAlreadyExists(err) {
+		err = r.Client.Update(ctx, cm)
+	}
+	return err
+}
+
+// deleteManifest deletes the ConfigMap containing the manifest of deployed,
+// the deployed release of o, if its manifest is stored in a ConfigMap. The
+// ConfigMap of a release in another namespace than o is not garbage collected
+// with o, since it is only annotated with its owner.
+func (r HelmOperatorReconciler) deleteManifest(ctx context.Context, o *unstructured.Unstructured, deployed *types.HelmAppRelease) error {
+	if deployed == nil || manifestInReleaseRecord(deployed) || deployed.ManifestRef == nil {
+		return nil
+	}
+	namespace := o.GetNamespace()
+	if deployed.Namespace != "" {
+		namespace = deployed.Namespace
+	}
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: deployed.ManifestRef.Name}}
+	if err := r.Client.Delete(ctx, cm); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete manifest ConfigMap: %w", err)
+	}
+	return nil
+}
+
+// deployedManifest returns the manifest of the deployed release recorded in
+// the status of o, reading it from where it is stored. The manifest is
+// verified against the recorded digest.
+func (r HelmOperatorReconciler) deployedManifest(ctx context.Context, o *unstructured.Unstructured,
+	manager release.Manager, deployed *types.HelmAppRelease) (string, error) {
+	if deployed.ManifestDigest == "" {
+		return deployed.Manifest, nil
+	}
+
+	var (
+		manifest string
+		err      error
+	)
+	if manifestInReleaseRecord(deployed) {
+		manifest, err = manager.ReleaseManifest(deployed.Revision)
+	} else {
+		namespace := o.GetNamespace()
+		if deployed.Namespace != "" {
+			namespace = deployed.Namespace
+		}
+		manifest, err = r.readManifest(ctx, namespace, deployed.ManifestRef)
+	}
+	if err != nil {
+		return "", err
+	}
+	if digest := manifestDigest(manifest); digest != deployed.ManifestDigest {
+		return "", fmt.Errorf("manifest digest %s does not match recorded digest %s", digest, deployed.ManifestDigest)
+	}
+	return manifest, nil
+}
+
+// readManifest reads a compressed manifest from the ConfigMap referenced by
+// ref. The ConfigMap is read from the API server, since the operator does not
+// cache ConfigMaps.
+func (r HelmOperatorReconciler) readManifest(ctx context.Context, namespace string, ref *types.HelmAppManifestRef) (string, error) {
+	cm := &corev1.ConfigMap{}
+	if err := r.APIReader.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ref.Name}, cm); err != nil {
+		return "", fmt.Errorf("failed to get manifest ConfigMap: %w", err)
+	}
+	data, ok := cm.BinaryData[ref.Key]
+	if !ok {
+		return "", fmt.Errorf("manifest ConfigMap %s has no key %q", ref.Name, ref.Key)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("failed to decompress manifest: %w", err)
+	}
+	manifest, err := io.ReadAll(zr)
+	if err != nil {
+		return "", fmt.Errorf("failed to decompress manifest: %w", err)
+	}
+	return string(manifest), nil
+}
@@ -0,0 +1,238 @@
+// Copyright 2026 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	rpb "helm.sh/helm/v3/pkg/release"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/operator-framework/operator-sdk/internal/helm/internal/types"
+	"github.com/operator-framework/operator-sdk/internal/helm/metrics"
+	"github.com/operator-framework/operator-sdk/internal/helm/release"
+	"github.com/operator-framework/operator-sdk/internal/helm/storage"
+)
+
+const testManifest = `---
+# Source: test/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: test
+`
+
+type fakeManifestManager struct {
+	fakeManager
+	manifests map[int]string
+}
+
+func (m fakeManifestManager) ReleaseManifest(revision int) (string, error) {
+	return m.manifests[revision], nil
+}
+
+func newTestCR() *unstructured.Unstructured {
+	o := &unstructured.Unstructured{}
+	o.SetAPIVersion("example.com/v1alpha1")
+	o.SetKind("Nginx")
+	o.SetNamespace("default")
+	o.SetName("test")
+	o.SetUID("uid")
+	return o
+}
+
+func TestReleaseStatus(t *testing.T) {
+	rel := &rpb.Release{Name: "test", Namespace: "default", Version: 3, Manifest: testManifest}
+	manager := fakeManifestManager{manifests: map[int]string{3: testManifest}}
+	o := newTestCR()
+
+	t.Run("status", func(t *testing.T) {
+		r := HelmOperatorReconciler{}
+		status, err := r.releaseStatus(context.TODO(), o, nil, rel)
+		require.NoError(t, err)
+		assert.Equal(t, &types.HelmAppRelease{Name: "test", Manifest: testManifest}, status)
+
+		manifest, err := r.deployedManifest(context.TODO(), o, manager, status)
+		require.NoError(t, err)
+		assert.Equal(t, testManifest, manifest)
+	})
+
+	t.Run("release", func(t *testing.T) {
+		r := HelmOperatorReconciler{ManifestStorage: types.ManifestStorageRelease}
+		status, err := r.releaseStatus(context.TODO(), o, nil, rel)
+		require.NoError(t, err)
+		assert.Empty(t, status.Manifest)
+		assert.Equal(t, 3, status.Revision)
+		assert.Equal(t, manifestDigest(testManifest), status.ManifestDigest)
+		assert.Equal(t, &types.HelmAppManifestRef{Kind: "Secret", Name: "sh.helm.release.v1.test.v3"}, status.ManifestRef)
+
+		manifest, err := r.deployedManifest(context.TODO(), o, manager, status)
+		require.NoError(t, err)
+		assert.Equal(t, testManifest, manifest)
+
+		status.ManifestDigest = manifestDigest("other")
+		_, err = r.deployedManifest(context.TODO(), o, manager, status)
+		assert.ErrorContains(t, err, "does not match recorded digest")
+	})
+
+	t.Run("release in other backends", func(t *testing.T) {
+		for backend, ref := range map[storage.Backend]*types.HelmAppManifestRef{
+			storage.BackendConfigMap: {Kind: "ConfigMap", Name: "sh.helm.release.v1.test.v3"},
+			storage.BackendSQL:       nil,
+		} {
+			r := HelmOperatorReconciler{ManifestStorage: types.ManifestStorageRelease, ReleaseStorage: backend}
+			status, err := r.releaseStatus(context.TODO(), o, nil, rel)
+			require.NoError(t, err)
+			assert.Equal(t, ref, status.ManifestRef, backend)
+			assert.True(t, manifestInReleaseRecord(status), backend)
+
+			manifest, err := r.deployedManifest(context.TODO(), o, manager, status)
+			require.NoError(t, err)
+			assert.Equal(t, testManifest, manifest, backend)
+		}
+	})
+
+	t.Run("configMap", func(t *testing.T) {
+		c := fake.NewClientBuilder().Build()
+		r := HelmOperatorReconciler{Client: c, APIReader: c, ManifestStorage: types.ManifestStorageConfigMap}
+		status, err := r.releaseStatus(context.TODO(), o, nil, rel)
+		require.NoError(t, err)
+		assert.Empty(t, status.Manifest)
+		assert.Equal(t, &types.HelmAppManifestRef{Kind: "ConfigMap", Name: "sh.helm.manifest.v1.test", Key: "manifest.gz"}, status.ManifestRef)
+		assert.False(t, manifestInReleaseRecord(status))
+
+		cm := &corev1.ConfigMap{}
+		require.NoError(t, c.Get(context.TODO(), client.ObjectKey{Namespace: "default", Name: "sh.helm.manifest.v1.test"}, cm))
+		assert.Equal(t, "test", cm.Labels[manifestReleaseLabel])
+		assert.Equal(t, "test", cm.OwnerReferences[0].Name)
+		assert.NotEmpty(t, cm.BinaryData[manifestConfigMapKey])
+
+		manifest, err := r.deployedManifest(context.TODO(), o, manager, status)
+		require.NoError(t, err)
+		assert.Equal(t, testManifest, manifest)
+
+		// A new manifest replaces the stored one.
+		upgraded := &rpb.Release{Name: "test", Namespace: "default", Version: 4, Manifest: testManifest + "data:\n  a: b\n"}
+		status, err = r.releaseStatus(context.TODO(), o, status, upgraded)
+		require.NoError(t, err)
+		manifest, err = r.deployedManifest(context.TODO(), o, manager, status)
+		require.NoError(t, err)
+		assert.Equal(t, upgraded.Manifest, manifest)
+	})
+
+	t.Run("cluster-scoped", func(t *testing.T) {
+		clusterScoped := newTestCR()
+		clusterScoped.SetNamespace("")
+		targetRel := &rpb.Release{Name: "test", Namespace: "target", Version: 3, Manifest: testManifest}
+		c := fake.NewClientBuilder().Build()
+		r := HelmOperatorReconciler{Client: c, APIReader: c, ManifestStorage: types.ManifestStorageConfigMap}
+		status, err := r.releaseStatus(context.TODO(), clusterScoped, nil, targetRel)
+		require.NoError(t, err)
+		assert.Equal(t, "target", status.Namespace)
+
+		cm := &corev1.ConfigMap{}
+		require.NoError(t, c.Get(context.TODO(), client.ObjectKey{Namespace: "target", Name: "sh.helm.manifest.v1.test"}, cm))
+		manifest, err := r.deployedManifest(context.TODO(), clusterScoped, manager, status)
+		require.NoError(t, err)
+		assert.Equal(t, testManifest, manifest)
+
+		// The namespace is only recorded for releases in another namespace
+		// than their custom resource.
+		status, err = r.releaseStatus(context.TODO(), o, nil, rel)
+		require.NoError(t, err)
+		assert.Empty(t, status.Namespace)
+	})
+
+	t.Run("other namespace", func(t *testing.T) {
+		targetRel := &rpb.Release{Name: "test", Namespace: "target", Version: 3, Manifest: testManifest}
+		c := fake.NewClientBuilder().Build()
+		r := HelmOperatorReconciler{Client: c, APIReader: c, ManifestStorage: types.ManifestStorageConfigMap}
+		status, err := r.releaseStatus(context.TODO(), o, nil, targetRel)
+		require.NoError(t, err)
+		assert.Equal(t, "target", status.Namespace)
+
+		// A namespaced custom resource cannot own the ConfigMap in another
+		// namespace, so it is annotated with its owner.
+		cm := &corev1.ConfigMap{}
+		require.NoError(t, c.Get(context.TODO(), client.ObjectKey{Namespace: "target", Name: "sh.helm.manifest.v1.test"}, cm))
+		assert.Empty(t, cm.OwnerReferences)
+		assert.Equal(t, o.GetNamespace()+"/"+o.GetName(), cm.Annotations["operator-sdk/primary-resource"])
+	})
+}
+
+// uninstallingManager is a manager uninstalling a release.
+type uninstallingManager struct {
+	fakeManager
+}
+
+func (m uninstallingManager) UninstallRelease(...release.UninstallOption) (*rpb.Release, error) {
+	return &rpb.Release{Name: "test", Manifest: testManifest}, nil
+}
+
+func (m uninstallingManager) CleanupRelease(string) (bool, error) {
+	return true, nil
+}
+
+func TestUninstallDeletesManifestConfigMap(t *testing.T) {
+	for _, wait := range []bool{false, true} {
+		o := newTestCR()
+		o.SetFinalizers([]string{uninstallFinalizer})
+		if wait {
+			o.SetAnnotations(map[string]string{helmUninstallWaitAnnotation: "true"})
+		}
+		c := fake.NewClientBuilder().WithObjects(o).WithStatusSubresource(o).
+			WithInterceptorFuncs(interceptor.Funcs{SubResourceUpdate: updateStatusAsMap}).Build()
+		r := HelmOperatorReconciler{
+			Client:          c,
+			APIReader:       c,
+			EventRecorder:   record.NewFakeRecorder(10),
+			GVK:             o.GroupVersionKind(),
+			ManagerFactory:  fakeManagerFactory{manager: uninstallingManager{}},
+			ManifestStorage: types.ManifestStorageConfigMap,
+		}
+
+		// The manifest ConfigMap of a release in another namespace is not
+		// owned by the custom resource.
+		status, err := r.releaseStatus(context.TODO(), o, nil,
+			&rpb.Release{Name: "test", Namespace: "target", Version: 1, Manifest: testManifest})
+		require.NoError(t, err)
+		o.Object["status"] = map[string]any{"deployedRelease": map[string]any{
+			"name":           status.Name,
+			"namespace":      status.Namespace,
+			"revision":       int64(status.Revision),
+			"manifestDigest": status.ManifestDigest,
+			"manifestRef":    map[string]any{"kind": "ConfigMap", "name": status.ManifestRef.Name, "key": status.ManifestRef.Key},
+		}}
+		require.NoError(t, c.Status().Update(context.TODO(), o))
+		require.NoError(t, c.Delete(context.TODO(), o))
+
+		key := client.ObjectKeyFromObject(o)
+		_, err = r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: key})
+		require.NoError(t, err, "wait=%t", wait)
+		err = c.Get(context.TODO(), client.ObjectKey{Namespace: "target", Name: "sh.helm.manifest.v1.test"}, &corev1.ConfigMap{})
+		assert.True(t, apierrors.IsNotFound(err), "wait=%t: %v", wait, err)
+		metrics.DeleteReleaseState(o.GroupVersionKind(), key)
+	}
+}
@@ -23,9 +23,12 @@ import (
 	"strings"
 	"time"
 
+	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/trace"
 	rpb "helm.sh/helm/v3/pkg/release"
 	"helm.sh/helm/v3/pkg/storage/driver"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -37,18 +40,25 @@ import (
 
 	"github.com/operator-framework/operator-sdk/internal/helm/internal/diff"
 	"github.com/operator-framework/operator-sdk/internal/helm/internal/types"
+	"github.com/operator-framework/operator-sdk/internal/helm/maintenance"
+	"github.com/operator-framework/operator-sdk/internal/helm/metrics"
 	"github.com/operator-framework/operator-sdk/internal/helm/release"
+	"github.com/operator-framework/operator-sdk/internal/helm/storage"
+	"github.com/operator-framework/operator-sdk/internal/helm/tracing"
 )
 
 // blank assignment to verify that HelmOperatorReconciler implements reconcile.Reconciler
 var _ reconcile.Reconciler = &HelmOperatorReconciler{}
 
-// ReleaseHookFunc defines a function signature for release hooks.
-type ReleaseHookFunc func(*rpb.Release) error
+// ReleaseHookFunc defines a function signature for release hooks. Release
+// hooks are called with the deployed release of a custom resource, or with a
+// nil release once the custom resource is deleted.
+type ReleaseHookFunc func(*unstructured.Unstructured, *rpb.Release) error
 
 // HelmOperatorReconciler reconciles custom resources as Helm releases.
 type HelmOperatorReconciler struct {
 	Client                 client.Client
+	APIReader              client.Reader
 	EventRecorder          record.EventRecorder
 	GVK                    schema.GroupVersionKind
 	ManagerFactory         release.ManagerFactory
@@ -57,6 +67,19 @@ type HelmOperatorReconciler struct {
 	SuppressOverrideValues bool
 	releaseHook            ReleaseHookFunc
 	DryRunOption           string
+	ServerSideApply        bool
+	ForceConflicts         bool
+	RunTests               bool
+	TestTimeout            time.Duration
+	RollbackOnTestFailure  bool
+	Wait                   bool
+	WaitForJobs            bool
+	WaitTimeout            time.Duration
+	ManifestStorage        types.ManifestStorage
+	ReleaseStorage         storage.Backend
+	MaintenanceWindow      *maintenance.Schedule
+	AdoptReleases          bool
+	ReleaseNaming          types.ReleaseNaming
 }
 
 const (
@@ -69,14 +92,40 @@ const (
 	helmRollbackForceAnnotation   = "helm.sdk.operatorframework.io/rollback-force"
 	helmUninstallWaitAnnotation   = "helm.sdk.operatorframework.io/uninstall-wait"
 	helmReconcilePeriodAnnotation = "helm.sdk.operatorframework.io/reconcile-period"
+	helmWaitAnnotation            = "helm.sdk.operatorframework.io/wait"
+	helmWaitForJobsAnnotation     = "helm.sdk.operatorframework.io/wait-for-jobs"
+	helmWaitTimeoutAnnotation     = "helm.sdk.operatorframework.io/wait-timeout"
+	helmRollbackToAnnotation      = "helm.sdk.operatorframework.io/rollback-to"
+	helmPausedAnnotation          = "helm.sdk.operatorframework.io/paused"
+	helmAdoptReleaseAnnotation    = "helm.sdk.operatorframework.io/adopt-release"
+
+	// readinessRequeueInterval is how soon a CR is requeued while its release
+	// resources are becoming ready.
+	readinessRequeueInterval = 5 * time.Second
+
+	// maxEventMessageLength is the maximum length of the message of an event
+	// accepted by the API server.
+	maxEventMessageLength = 1024
 )
 
 // Reconcile reconciles the requested resource by installing, updating, or
 // uninstalling a Helm release based on the resource's current state. If no
 // release changes are necessary, Reconcile will create or patch the underlying
 // resources to match the expected release manifest.
+func (r HelmOperatorReconciler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	ctx, span := tracing.Start(ctx, "Reconcile",
+		tracing.AttributeGroup.String(r.GVK.Group),
+		tracing.AttributeVersion.String(r.GVK.Version),
+		tracing.AttributeKind.String(r.GVK.Kind),
+		tracing.AttributeNamespace.String(request.Namespace),
+		tracing.AttributeName.String(request.Name),
+	)
+	result, err := r.reconcile(ctx, request)
+	tracing.End(span, err)
+	return result, err
+}
 
-func (r HelmOperatorReconciler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) { //nolint:gocyclo
+func (r HelmOperatorReconciler) reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) { //nolint:gocyclo
 	o := &unstructured.Unstructured{}
 	o.SetGroupVersionKind(r.GVK)
 	o.SetNamespace(request.Namespace)
@@ -91,6 +140,13 @@ func (r HelmOperatorReconciler) Reconcile(ctx context.Context, request reconcile
 
 	err := r.Client.Get(ctx, request.NamespacedName, o)
 	if apierrors.IsNotFound(err) {
+		metrics.DeleteReleaseState(r.GVK, request.NamespacedName)
+		if r.releaseHook != nil {
+			if err := r.releaseHook(o, nil); err != nil {
+				log.Error(err, "Failed to run release hook")
+				return reconcile.Result{}, err
+			}
+		}
 		return reconcile.Result{}, nil
 	}
 	if err != nil {
@@ -98,14 +154,21 @@ func (r HelmOperatorReconciler) Reconcile(ctx context.Context, request reconcile
 		return reconcile.Result{}, err
 	}
 
-	manager, err := r.ManagerFactory.NewManager(o, r.OverrideValues, r.DryRunOption)
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(tracing.ObjectAttributes(o)...)
+
+	manager, err := r.ManagerFactory.NewManager(ctx, o, r.OverrideValues, r.DryRunOption)
 	if err != nil {
 		log.Error(err, "Failed to get release manager")
 		return reconcile.Result{}, err
 	}
+	span.SetAttributes(tracing.AttributeRelease.String(manager.ReleaseName()))
 
 	status := types.StatusFor(o)
 	originalStatus := types.StatusFor(o.DeepCopy())
+	// The state is recorded on every reconciliation, since the status is
+	// only updated when it changes.
+	defer r.recordReleaseState(o, status)
 	log = log.WithValues("release", manager.ReleaseName())
 
 	reconcileResult := reconcile.Result{RequeueAfter: r.ReconcilePeriod}
@@ -120,6 +183,19 @@ func (r HelmOperatorReconciler) Reconcile(ctx context.Context, request reconcile
 	}
 	reconcileResult.RequeueAfter = finalReconcilePeriod
 
+	// Until it is installed, the release of o may collide with the release of
+	// another CR, which must be left untouched.
+	if status.DeployedRelease == nil {
+		collision, err := r.checkReleaseCollision(ctx, o, manager)
+		if err != nil {
+			log.Error(err, "Failed to check release name collision")
+			return reconcile.Result{}, err
+		}
+		if collision != nil {
+			return r.releaseCollision(ctx, o, status, collision)
+		}
+	}
+
 	if o.GetDeletionTimestamp() != nil {
 		if !controllerutil.ContainsFinalizer(o, uninstallFinalizer) && !controllerutil.ContainsFinalizer(o, uninstallFinalizerLegacy) {
 
@@ -127,7 +203,18 @@ func (r HelmOperatorReconciler) Reconcile(ctx context.Context, request reconcile
 			return reconcile.Result{}, nil
 		}
 
-		uninstalledRelease, err := manager.UninstallRelease()
+		// The deployed release is removed from the status once uninstalled,
+		// but its manifest ConfigMap is deleted only once the uninstall is
+		// complete.
+		deployed := status.DeployedRelease
+		wait := hasAnnotation(helmUninstallWaitAnnotation, o)
+		// A release whose manifest is only referenced from the status is kept
+		// in storage while waiting for its resources to be deleted, so that
+		// the manifest can still be read.
+		keepHistory := wait && manifestInReleaseRecord(status.DeployedRelease)
+		_, done := r.startOperation(ctx, metrics.OperationUninstall, "UninstallRelease")
+		uninstalledRelease, err := manager.UninstallRelease(release.KeepHistory(keepHistory))
+		done(err)
 		if err != nil && !errors.Is(err, driver.ErrReleaseNotFound) {
 			log.Error(err, "Failed to uninstall release")
 			status.SetCondition(types.HelmAppCondition{
@@ -143,14 +230,15 @@ func (r HelmOperatorReconciler) Reconcile(ctx context.Context, request reconcile
 		}
 		status.RemoveCondition(types.ConditionReleaseFailed)
 
-		wait := hasAnnotation(helmUninstallWaitAnnotation, o)
 		if errors.Is(err, driver.ErrReleaseNotFound) {
 			log.Info("Release not found")
 		} else {
-			log.Info("Uninstalled release")
-			if log.V(1).Enabled() && uninstalledRelease != nil {
-				fmt.Println(diff.Generate(uninstalledRelease.Manifest, ""))
+			var summary diff.Summary
+			if uninstalledRelease != nil {
+				summary = diff.Summarize(uninstalledRelease.Manifest, "")
 			}
+			log.Info("Uninstalled release", "diff", summary.WithoutDiffs())
+			r.recordDiff(log, o, "Uninstalled release", summary)
 			if !wait {
 				status.SetCondition(types.HelmAppCondition{
 					Type:   types.ConditionDeployed,
@@ -173,9 +261,21 @@ func (r HelmOperatorReconciler) Reconcile(ctx context.Context, request reconcile
 			return reconcile.Result{}, err
 		}
 
-		if wait && status.DeployedRelease != nil && status.DeployedRelease.Manifest != "" {
+		if wait && status.DeployedRelease != nil {
 			log.Info("Uninstall wait")
-			isAllResourcesDeleted, err := manager.CleanupRelease(status.DeployedRelease.Manifest)
+			manifest, err := r.deployedManifest(ctx, o, manager, status.DeployedRelease)
+			if err != nil {
+				log.Error(err, "Failed to get deployed release manifest")
+				status.SetCondition(types.HelmAppCondition{
+					Type:    types.ConditionReleaseFailed,
+					Status:  types.StatusTrue,
+					Reason:  types.ReasonUninstallError,
+					Message: err.Error(),
+				})
+				_ = r.updateResourceStatus(ctx, o, status)
+				return reconcile.Result{}, err
+			}
+			isAllResourcesDeleted, err := manager.CleanupRelease(manifest)
 			if err != nil {
 				log.Error(err, "Failed to cleanup release")
 				status.SetCondition(types.HelmAppCondition{
@@ -191,9 +291,20 @@ func (r HelmOperatorReconciler) Reconcile(ctx context.Context, request reconcile
 				log.Info("Waiting until all resources are deleted")
 				return reconcileResult, nil
 			}
+			if keepHistory {
+				if _, err := manager.UninstallRelease(); err != nil && !errors.Is(err, driver.ErrReleaseNotFound) {
+					log.Error(err, "Failed to delete release history")
+					return reconcile.Result{}, err
+				}
+			}
 			status.RemoveCondition(types.ConditionReleaseFailed)
 		}
 
+		if err := r.deleteManifest(ctx, o, deployed); err != nil {
+			log.Error(err, "Failed to delete release manifest")
+			return reconcile.Result{}, err
+		}
+
 		log.Info("Removing finalizer")
 		controllerutil.RemoveFinalizer(o, uninstallFinalizer)
 		controllerutil.RemoveFinalizer(o, uninstallFinalizerLegacy)
@@ -218,8 +329,27 @@ func (r HelmOperatorReconciler) Reconcile(ctx context.Context, request reconcile
 		Type:   types.ConditionInitialized,
 		Status: types.StatusTrue,
 	})
+	if !r.RunTests {
+		status.RemoveCondition(types.ConditionTested)
+	}
 
-	if err := manager.Sync(); err != nil {
+	if readBoolAnnotationWithDefault(o, helmPausedAnnotation, false) {
+		log.V(1).Info("Reconciliation is paused")
+		status.SetCondition(types.HelmAppCondition{
+			Type:   types.ConditionPaused,
+			Status: types.StatusTrue,
+			Reason: types.ReasonPausedByAnnotation,
+			Message: fmt.Sprintf("Install, upgrade and drift correction are paused until the %s annotation is removed",
+				helmPausedAnnotation),
+		})
+		if !reflect.DeepEqual(status, originalStatus) {
+			err = r.updateResourceStatus(ctx, o, status)
+		}
+		return reconcileResult, err
+	}
+	status.RemoveCondition(types.ConditionPaused)
+
+	if err := manager.Sync(ctx); err != nil {
 		log.Error(err, "Failed to sync release")
 		status.SetCondition(types.HelmAppCondition{
 			Type:    types.ConditionIrreconcilable,
@@ -234,6 +364,19 @@ func (r HelmOperatorReconciler) Reconcile(ctx context.Context, request reconcile
 	}
 	status.RemoveCondition(types.ConditionIrreconcilable)
 
+	if _, ok := o.GetAnnotations()[helmRollbackToAnnotation]; ok {
+		return r.rollbackTo(ctx, log, o, manager, status, reconcileResult)
+	}
+
+	// A release that is installed although the CR status does not record a
+	// deployed release was installed by another client, such as the Helm CLI.
+	if manager.IsInstalled() && status.DeployedRelease == nil &&
+		readBoolAnnotationWithDefault(o, helmAdoptReleaseAnnotation, r.AdoptReleases) {
+		if err := r.adoptRelease(ctx, o, manager, status); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
 	if !manager.IsInstalled() {
 		for k, v := range r.OverrideValues {
 			if r.SuppressOverrideValues {
@@ -242,7 +385,9 @@ func (r HelmOperatorReconciler) Reconcile(ctx context.Context, request reconcile
 			r.EventRecorder.Eventf(o, "Warning", "OverrideValuesInUse",
 				"Chart value %q overridden to %q by operator's watches.yaml", k, v)
 		}
+		_, done := r.startOperation(ctx, metrics.OperationInstall, "InstallRelease")
 		installedRelease, err := manager.InstallRelease()
+		done(err)
 		if err != nil {
 			log.Error(err, "Release failed")
 			status.SetCondition(types.HelmAppCondition{
@@ -266,16 +411,19 @@ func (r HelmOperatorReconciler) Reconcile(ctx context.Context, request reconcile
 		}
 
 		if r.releaseHook != nil {
-			if err := r.releaseHook(installedRelease); err != nil {
+			if err := r.releaseHook(o, installedRelease); err != nil {
 				log.Error(err, "Failed to run release hook")
 				return reconcile.Result{}, err
 			}
 		}
 
-		log.Info("Installed release")
-		if log.V(1).Enabled() {
-			fmt.Println(diff.Generate("", installedRelease.Manifest))
+		if r.RunTests {
+			setTestsPending(status)
 		}
+
+		summary := diff.Summarize("", installedRelease.Manifest)
+		log.Info("Installed release", "diff", summary.WithoutDiffs())
+		r.recordDiff(log, o, "Installed release", summary)
 		log.V(1).Info("Config values", "values", installedRelease.Config)
 		message := ""
 		if installedRelease.Info != nil {
@@ -287,12 +435,14 @@ func (r HelmOperatorReconciler) Reconcile(ctx context.Context, request reconcile
 			Reason:  types.ReasonInstallSuccessful,
 			Message: message,
 		})
-		status.DeployedRelease = &types.HelmAppRelease{
-			Name:     installedRelease.Name,
-			Manifest: installedRelease.Manifest,
+		if status.DeployedRelease, err = r.releaseStatus(ctx, o, status.DeployedRelease, installedRelease); err != nil {
+			log.Error(err, "Failed to record deployed release")
+			return reconcile.Result{}, err
 		}
-		err = r.updateResourceStatus(ctx, o, status)
-		return reconcileResult, err
+		r.setHistory(ctx, manager, status)
+		reconcileResult = r.checkReadiness(ctx, o, manager, installedRelease, status, reconcileResult)
+		reconcileResult, err = r.runPendingTests(ctx, o, manager, installedRelease, status, reconcileResult)
+		return reconcileResult, errors.Join(err, r.updateResourceStatus(ctx, o, status))
 	}
 
 	if !controllerutil.ContainsFinalizer(o, uninstallFinalizer) && !controllerutil.ContainsFinalizer(o, uninstallFinalizerLegacy) {
@@ -305,7 +455,31 @@ func (r HelmOperatorReconciler) Reconcile(ctx context.Context, request reconcile
 		}
 	}
 
-	if manager.IsUpgradeRequired() {
+	if status.Rollback != nil && status.Rollback.Generation != o.GetGeneration() {
+		// The spec changed since the release was rolled back manually, so
+		// upgrades resume.
+		status.Rollback = nil
+	}
+
+	upgradeRequired := manager.IsUpgradeRequired() && status.Rollback == nil
+	status.NextMaintenanceWindow = nil
+	// Outside of maintenance windows, upgrades and drift corrections, which
+	// both change live resources, are deferred to the next window.
+	nextWindow, deferChanges := r.deferChanges(time.Now())
+	if deferChanges && nextWindow != nil {
+		until := time.Until(nextWindow.Time)
+		if reconcileResult.RequeueAfter == 0 || until < reconcileResult.RequeueAfter {
+			reconcileResult.RequeueAfter = until
+		}
+	}
+	upgradeDeferred := upgradeRequired && deferChanges
+	if upgradeDeferred {
+		log.Info("Deferring upgrade to the next maintenance window", "nextMaintenanceWindow", nextWindow)
+		status.NextMaintenanceWindow = nextWindow
+		upgradeRequired = false
+	}
+
+	if upgradeRequired {
 		for k, v := range r.OverrideValues {
 			if r.SuppressOverrideValues {
 				v = "****"
@@ -315,14 +489,16 @@ func (r HelmOperatorReconciler) Reconcile(ctx context.Context, request reconcile
 		}
 		force := hasAnnotation(helmUpgradeForceAnnotation, o)
 
+		_, done := r.startOperation(ctx, metrics.OperationUpgrade, "UpgradeRelease")
 		previousRelease, upgradedRelease, err := manager.UpgradeRelease(release.ForceUpgrade(force))
+		done(err)
 		if err != nil {
 			if errors.Is(err, release.ErrUpgradeFailed) {
 				// the forceRollback variable takes the value of the annotation,
 				// "helm.sdk.operatorframework.io/rollback-force".
 				// The default value for the annotation is true
 				forceRollback := readBoolAnnotationWithDefault(o, helmRollbackForceAnnotation, true)
-				if err := manager.RollBack(release.ForceRollback(forceRollback)); err != nil {
+				if err := r.rollBack(ctx, manager, release.ForceRollback(forceRollback)); err != nil {
 					log.Error(err, "Error rolling back release")
 				}
 			}
@@ -333,6 +509,7 @@ func (r HelmOperatorReconciler) Reconcile(ctx context.Context, request reconcile
 				Reason:  types.ReasonUpgradeError,
 				Message: err.Error(),
 			})
+			r.setHistory(ctx, manager, status)
 			if err := r.updateResourceStatus(ctx, o, status); err != nil {
 				log.Error(err, "Failed to update status after sync release failure")
 			}
@@ -340,17 +517,20 @@ func (r HelmOperatorReconciler) Reconcile(ctx context.Context, request reconcile
 		}
 		status.RemoveCondition(types.ConditionReleaseFailed)
 
+		if r.RunTests {
+			setTestsPending(status)
+		}
+
 		if r.releaseHook != nil {
-			if err := r.releaseHook(upgradedRelease); err != nil {
+			if err := r.releaseHook(o, upgradedRelease); err != nil {
 				log.Error(err, "Failed to run release hook")
 				return reconcile.Result{}, err
 			}
 		}
 
-		log.Info("Upgraded release", "force", force)
-		if log.V(1).Enabled() {
-			fmt.Println(diff.Generate(previousRelease.Manifest, upgradedRelease.Manifest))
-		}
+		summary := diff.Summarize(previousRelease.Manifest, upgradedRelease.Manifest)
+		log.Info("Upgraded release", "force", force, "diff", summary.WithoutDiffs())
+		r.recordDiff(log, o, "Upgraded release", summary)
 		log.V(1).Info("Config values", "values", upgradedRelease.Config)
 		message := ""
 		if upgradedRelease.Info != nil {
@@ -362,12 +542,14 @@ func (r HelmOperatorReconciler) Reconcile(ctx context.Context, request reconcile
 			Reason:  types.ReasonUpgradeSuccessful,
 			Message: message,
 		})
-		status.DeployedRelease = &types.HelmAppRelease{
-			Name:     upgradedRelease.Name,
-			Manifest: upgradedRelease.Manifest,
+		if status.DeployedRelease, err = r.releaseStatus(ctx, o, status.DeployedRelease, upgradedRelease); err != nil {
+			log.Error(err, "Failed to record deployed release")
+			return reconcile.Result{}, err
 		}
-		err = r.updateResourceStatus(ctx, o, status)
-		return reconcileResult, err
+		r.setHistory(ctx, manager, status)
+		reconcileResult = r.checkReadiness(ctx, o, manager, upgradedRelease, status, reconcileResult)
+		reconcileResult, err = r.runPendingTests(ctx, o, manager, upgradedRelease, status, reconcileResult)
+		return reconcileResult, errors.Join(err, r.updateResourceStatus(ctx, o, status))
 	}
 
 	// If a change is made to the CR spec that causes a release failure, a
@@ -378,7 +560,37 @@ func (r HelmOperatorReconciler) Reconcile(ctx context.Context, request reconcile
 	// no longer being attempted.
 	status.RemoveCondition(types.ConditionReleaseFailed)
 
-	expectedRelease, err := manager.ReconcileRelease(ctx)
+	var reconcileOpts []release.ReconcileOption
+	if r.ServerSideApply {
+		reconcileOpts = append(reconcileOpts, release.ServerSideApply(r.ForceConflicts))
+	}
+	if deferChanges {
+		reconcileOpts = append(reconcileOpts, release.DryRun())
+	}
+	reconcileCtx, done := r.startOperation(ctx, metrics.OperationDriftReconcile, "ReconcileRelease")
+	expectedRelease, corrections, err := manager.ReconcileRelease(reconcileCtx, reconcileOpts...)
+	done(err)
+	if deferChanges {
+		r.recordDeferredDriftCorrections(status, corrections, nextWindow)
+	} else {
+		r.recordDriftCorrections(o, status, corrections)
+	}
+	var conflictErr *release.ApplyConflictError
+	if errors.As(err, &conflictErr) {
+		// Conflicting fields are owned by another field manager. The rest of
+		// the release has been applied, so surface the conflicts on the CR
+		// rather than failing the reconciliation.
+		log.Info("Field ownership conflicts while applying release", "conflicts", conflictErr.Conflicts)
+		status.SetCondition(types.HelmAppCondition{
+			Type:    types.ConditionApplyConflict,
+			Status:  types.StatusTrue,
+			Reason:  types.ReasonFieldManagerConflict,
+			Message: conflictErr.Error(),
+		})
+		err = nil
+	} else {
+		status.RemoveCondition(types.ConditionApplyConflict)
+	}
 	if err != nil {
 		log.Error(err, "Failed to reconcile release")
 		status.SetCondition(types.HelmAppCondition{
@@ -395,7 +607,7 @@ func (r HelmOperatorReconciler) Reconcile(ctx context.Context, request reconcile
 	status.RemoveCondition(types.ConditionIrreconcilable)
 
 	if r.releaseHook != nil {
-		if err := r.releaseHook(expectedRelease); err != nil {
+		if err := r.releaseHook(o, expectedRelease); err != nil {
 			log.Error(err, "Failed to run release hook")
 			return reconcile.Result{}, err
 		}
@@ -410,24 +622,585 @@ func (r HelmOperatorReconciler) Reconcile(ctx context.Context, request reconcile
 	if expectedRelease.Info != nil {
 		message = expectedRelease.Info.Notes
 	}
+	if status.Rollback != nil {
+		reason = types.ReasonRollbackSuccessful
+		message = fmt.Sprintf("Rolled back to revision %d. Upgrades resume when the spec changes.", status.Rollback.Revision)
+	}
+	if upgradeDeferred && status.NextMaintenanceWindow != nil {
+		reason = types.ReasonUpgradeDeferred
+		message = fmt.Sprintf("An upgrade is deferred to the maintenance window starting at %s.",
+			status.NextMaintenanceWindow.UTC().Format(time.RFC3339))
+	}
 	status.SetCondition(types.HelmAppCondition{
 		Type:    types.ConditionDeployed,
 		Status:  types.StatusTrue,
 		Reason:  reason,
 		Message: message,
 	})
-	status.DeployedRelease = &types.HelmAppRelease{
-		Name:     expectedRelease.Name,
-		Manifest: expectedRelease.Manifest,
+	if status.DeployedRelease, err = r.releaseStatus(ctx, o, status.DeployedRelease, expectedRelease); err != nil {
+		log.Error(err, "Failed to record deployed release")
+		return reconcile.Result{}, err
 	}
+	r.setHistory(ctx, manager, status)
+	reconcileResult = r.checkReadiness(ctx, o, manager, expectedRelease, status, reconcileResult)
+	reconcileResult, err = r.runPendingTests(ctx, o, manager, expectedRelease, status, reconcileResult)
 
 	if !reflect.DeepEqual(status, originalStatus) {
-		err = r.updateResourceStatus(ctx, o, status)
+		err = errors.Join(err, r.updateResourceStatus(ctx, o, status))
 	}
 
 	return reconcileResult, err
 }
 
+// checkReleaseCollision returns a collision error if the release of o has the
+// same name and namespace as the release of another CR of the same kind that
+// takes precedence over o, i.e. that has installed its release or, if none
+// has, that was created before o. A release whose labels record its owner is
+// the release of that CR, even if its status does not record it, so that it
+// is uninstalled when that CR is deleted; the CRs of the kind are only listed
+// if the release records no owner. err is returned if the collision cannot be
+// checked.
+func (r HelmOperatorReconciler) checkReleaseCollision(ctx context.Context, o *unstructured.Unstructured,
+	manager release.Manager) (collision error, err error) {
+	// Releases of CRs of the same kind have distinct names and namespaces by
+	// default.
+	if r.ReleaseNaming.IsDefault() {
+		return nil, nil
+	}
+	owner, err := manager.ReleaseOwner()
+	if err != nil {
+		return nil, err
+	}
+	if owner == o.GetUID() && owner != "" {
+		return nil, nil
+	}
+	releaseName := manager.ReleaseName()
+	namespace, err := r.ReleaseNaming.NamespaceFor(o)
+	if err != nil {
+		return nil, err
+	}
+	if owner != "" {
+		return fmt.Errorf("release %s/%s is already the release of %s with UID %s", namespace, releaseName,
+			r.GVK.Kind, owner), nil
+	}
+
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(r.GVK.GroupVersion().WithKind(r.GVK.Kind + "List"))
+	if err := r.Client.List(ctx, list); err != nil {
+		return nil, fmt.Errorf("failed to list %s resources: %w", r.GVK.Kind, err)
+	}
+	var precedent *unstructured.Unstructured
+	for i := range list.Items {
+		other := &list.Items[i]
+		if other.GetUID() == o.GetUID() {
+			continue
+		}
+		otherName, err := r.ReleaseNaming.NameFor(other)
+		if err != nil || otherName != releaseName {
+			continue
+		}
+		otherNamespace, err := r.ReleaseNaming.NamespaceFor(other)
+		if err != nil || otherNamespace != namespace {
+			continue
+		}
+		if types.StatusFor(other).DeployedRelease != nil {
+			precedent = other
+			break
+		}
+		if createdBefore(other, o) && (precedent == nil || createdBefore(other, precedent)) {
+			precedent = other
+		}
+	}
+	if precedent == nil {
+		return nil, nil
+	}
+	return fmt.Errorf("release %s/%s is already the release of %s %s", namespace, releaseName,
+		r.GVK.Kind, namespacedName(precedent.GetNamespace(), precedent.GetName())), nil
+}
+
+// createdBefore returns true if a was created before b. CRs created at the
+// same time are ordered by namespace and name.
+func createdBefore(a, b *unstructured.Unstructured) bool {
+	at, bt := a.GetCreationTimestamp(), b.GetCreationTimestamp()
+	if !at.Equal(&bt) {
+		return at.Before(&bt)
+	}
+	return namespacedName(a.GetNamespace(), a.GetName()) < namespacedName(b.GetNamespace(), b.GetName())
+}
+
+// releaseCollision handles a CR whose release collides with the release of
+// another CR: the CR is marked irreconcilable or, if it is being deleted, its
+// finalizer is removed without uninstalling the release.
+func (r HelmOperatorReconciler) releaseCollision(ctx context.Context, o *unstructured.Unstructured,
+	status *types.HelmAppStatus, collision error) (reconcile.Result, error) {
+	log := log.WithValues("namespace", o.GetNamespace(), "name", o.GetName(), "kind", o.GetKind())
+	if o.GetDeletionTimestamp() != nil {
+		log.Info("Removing finalizer without uninstalling the release of another resource", "reason", collision.Error())
+		controllerutil.RemoveFinalizer(o, uninstallFinalizer)
+		controllerutil.RemoveFinalizer(o, uninstallFinalizerLegacy)
+		if err := r.updateResource(ctx, o); err != nil {
+			log.Info("Failed to remove CR uninstall finalizer")
+			return reconcile.Result{}, err
+		}
+		return reconcile.Result{}, nil
+	}
+
+	log.Error(collision, "Release name collision")
+	status.SetCondition(types.HelmAppCondition{
+		Type:    types.ConditionIrreconcilable,
+		Status:  types.StatusTrue,
+		Reason:  types.ReasonReleaseNameCollision,
+		Message: collision.Error(),
+	})
+	if err := r.updateResourceStatus(ctx, o, status); err != nil {
+		log.Error(err, "Failed to update status after release name collision")
+	}
+	return reconcile.Result{}, collision
+}
+
+// adoptRelease takes ownership of the release of o, which was installed by
+// another client.
+func (r HelmOperatorReconciler) adoptRelease(ctx context.Context, o *unstructured.Unstructured,
+	manager release.Manager, status *types.HelmAppStatus) error {
+	log := log.WithValues("namespace", o.GetNamespace(), "name", o.GetName(), "kind", o.GetKind())
+	adopted, err := manager.AdoptRelease(ctx)
+	if err != nil {
+		log.Error(err, "Failed to adopt release")
+		status.SetCondition(types.HelmAppCondition{
+			Type:    types.ConditionIrreconcilable,
+			Status:  types.StatusTrue,
+			Reason:  types.ReasonAdoptError,
+			Message: err.Error(),
+		})
+		if err := r.updateResourceStatus(ctx, o, status); err != nil {
+			log.Error(err, "Failed to update status after adopt release failure")
+		}
+		return err
+	}
+	log.Info("Adopted release", "resources", adopted)
+	r.EventRecorder.Eventf(o, "Normal", "ReleaseAdopted",
+		"Adopted existing release %q and %d of its resources", manager.ReleaseName(), adopted)
+	return nil
+}
+
+// deferChanges returns true if changes to live resources at now must be
+// deferred because it is outside of the maintenance window, along with the
+// start of the next maintenance window, if there is one.
+func (r HelmOperatorReconciler) deferChanges(now time.Time) (*metav1.Time, bool) {
+	if r.MaintenanceWindow == nil || r.MaintenanceWindow.Contains(now) {
+		return nil, false
+	}
+	next, ok := r.MaintenanceWindow.Next(now)
+	if !ok {
+		return nil, true
+	}
+	nextTime := metav1.NewTime(next)
+	return &nextTime, true
+}
+
+// rollbackTo rolls the release back to the revision set in the rollback-to
+// annotation of o, and removes the annotation once the rollback succeeded.
+// Since the spec of o is left unchanged, upgrades of the release are
+// suspended until the generation of o changes; otherwise the release would
+// immediately be upgraded again.
+func (r HelmOperatorReconciler) rollbackTo(ctx context.Context, log logr.Logger, o *unstructured.Unstructured,
+	manager release.Manager, status *types.HelmAppStatus, result reconcile.Result) (reconcile.Result, error) {
+	value := o.GetAnnotations()[helmRollbackToAnnotation]
+	revision, rollbackErr := strconv.Atoi(value)
+	if rollbackErr == nil && revision <= 0 {
+		rollbackErr = fmt.Errorf("revision must be positive")
+	}
+	// An invalid revision is never rolled back to, so its annotation is
+	// removed, whereas a failed rollback is retried until it succeeds or the
+	// annotation is removed.
+	invalid := rollbackErr != nil
+	if !invalid {
+		force := readBoolAnnotationWithDefault(o, helmRollbackForceAnnotation, true)
+		rollbackErr = r.rollBack(ctx, manager, release.RollbackVersion(revision), release.ForceRollback(force))
+	}
+
+	if rollbackErr == nil || invalid {
+		annotations := o.GetAnnotations()
+		delete(annotations, helmRollbackToAnnotation)
+		o.SetAnnotations(annotations)
+		if err := r.updateResource(ctx, o); err != nil {
+			log.Info("Failed to remove CR rollback annotation")
+			return reconcile.Result{}, err
+		}
+	}
+
+	if rollbackErr != nil {
+		log.Error(rollbackErr, "Failed to roll back release", "revision", value)
+		r.EventRecorder.Eventf(o, "Warning", "RollbackFailed", "Failed to roll back release to revision %s: %v", value, rollbackErr)
+		status.SetCondition(types.HelmAppCondition{
+			Type:    types.ConditionReleaseFailed,
+			Status:  types.StatusTrue,
+			Reason:  types.ReasonRollbackError,
+			Message: fmt.Sprintf("failed to roll back to revision %s: %v", value, rollbackErr),
+		})
+		if err := r.updateResourceStatus(ctx, o, status); err != nil {
+			log.Error(err, "Failed to update status after rollback failure")
+		}
+		if invalid {
+			return result, nil
+		}
+		return reconcile.Result{}, rollbackErr
+	}
+
+	log.Info("Rolled back release", "revision", revision)
+	r.EventRecorder.Eventf(o, "Normal", "RolledBack", "Rolled back release to revision %d", revision)
+	status.RemoveCondition(types.ConditionReleaseFailed)
+	status.SetCondition(types.HelmAppCondition{
+		Type:    types.ConditionDeployed,
+		Status:  types.StatusTrue,
+		Reason:  types.ReasonRollbackSuccessful,
+		Message: fmt.Sprintf("Rolled back to revision %d. Upgrades resume when the spec changes.", revision),
+	})
+	status.Rollback = &types.HelmAppRollback{
+		Revision:   revision,
+		Generation: o.GetGeneration(),
+		Timestamp:  metav1.Now(),
+	}
+	r.setHistory(ctx, manager, status)
+	return result, r.updateResourceStatus(ctx, o, status)
+}
+
+// setHistory records the revisions of the release managed by manager in
+// status, along with the subcharts enabled in its deployed revision and the
+// images rewritten when it was applied.
+func (r HelmOperatorReconciler) setHistory(ctx context.Context, manager release.Manager, status *types.HelmAppStatus) {
+	releases, err := manager.History()
+	if err != nil {
+		log.Error(err, "Failed to get release history")
+		return
+	}
+	status.SetHistory(releaseRevisions(releases)...)
+	deployed := deployedRevision(releases)
+	status.Subcharts = manager.Subcharts(deployed)
+	status.Images = manager.Images(ctx, deployed)
+}
+
+// deployedRevision returns the deployed revision of releases, if any.
+func deployedRevision(releases []*rpb.Release) *rpb.Release {
+	var deployed *rpb.Release
+	for _, rel := range releases {
+		if rel.Info != nil && rel.Info.Status == rpb.StatusDeployed && (deployed == nil || rel.Version > deployed.Version) {
+			deployed = rel
+		}
+	}
+	return deployed
+}
+
+// releaseRevisions summarizes releases for the status of a CR.
+func releaseRevisions(releases []*rpb.Release) []types.HelmAppReleaseRevision {
+	revisions := make([]types.HelmAppReleaseRevision, 0, len(releases))
+	for _, rel := range releases {
+		rev := types.HelmAppReleaseRevision{Revision: rel.Version}
+		if rel.Chart != nil && rel.Chart.Metadata != nil {
+			rev.ChartVersion = rel.Chart.Metadata.Version
+			rev.AppVersion = rel.Chart.Metadata.AppVersion
+		}
+		if rel.Info != nil {
+			rev.Status = rel.Info.Status.String()
+			rev.Description = rel.Info.Description
+			rev.Timestamp = metav1.NewTime(rel.Info.LastDeployed.Time)
+		}
+		revisions = append(revisions, rev)
+	}
+	return revisions
+}
+
+// checkReadiness sets the Ready condition based on whether the resources of
+// rel are ready. Instead of blocking until the resources converge, the
+// returned result requeues the CR shortly while the wait timeout, measured
+// from the last deployment of rel, has not elapsed.
+func (r HelmOperatorReconciler) checkReadiness(ctx context.Context, o *unstructured.Unstructured, manager release.Manager,
+	rel *rpb.Release, status *types.HelmAppStatus, result reconcile.Result) reconcile.Result {
+	wait, waitForJobs, timeout := r.waitOptionsFor(o)
+	if !wait {
+		status.RemoveCondition(types.ConditionReady)
+		return result
+	}
+
+	notReady, err := manager.NotReadyResources(ctx, rel.Manifest, waitForJobs)
+	if err != nil {
+		log.Error(err, "Failed to check readiness of release resources")
+		status.SetCondition(types.HelmAppCondition{
+			Type:    types.ConditionReady,
+			Status:  types.StatusUnknown,
+			Reason:  types.ReasonReadinessCheckError,
+			Message: err.Error(),
+		})
+		return result
+	}
+	if len(notReady) == 0 {
+		status.SetCondition(types.HelmAppCondition{
+			Type:   types.ConditionReady,
+			Status: types.StatusTrue,
+			Reason: types.ReasonResourcesReady,
+		})
+		return result
+	}
+
+	var lastDeployed time.Time
+	if rel.Info != nil {
+		lastDeployed = rel.Info.LastDeployed.Time
+	}
+	if time.Since(lastDeployed) > timeout {
+		status.SetCondition(types.HelmAppCondition{
+			Type:   types.ConditionReady,
+			Status: types.StatusFalse,
+			Reason: types.ReasonReadinessTimeout,
+			Message: fmt.Sprintf("Resources did not become ready within %s: %s",
+				timeout, strings.Join(notReady, ", ")),
+		})
+		return result
+	}
+
+	status.SetCondition(types.HelmAppCondition{
+		Type:    types.ConditionReady,
+		Status:  types.StatusFalse,
+		Reason:  types.ReasonResourcesNotReady,
+		Message: fmt.Sprintf("Waiting for resources to become ready: %s", strings.Join(notReady, ", ")),
+	})
+	if result.RequeueAfter == 0 || result.RequeueAfter > readinessRequeueInterval {
+		result.RequeueAfter = readinessRequeueInterval
+	}
+	return result
+}
+
+// waitOptionsFor returns whether to wait for release resources to become
+// ready, whether to include jobs and how long to wait. Annotations on o take
+// precedence over the reconciler's settings from the watches.yaml file.
+func (r HelmOperatorReconciler) waitOptionsFor(o *unstructured.Unstructured) (bool, bool, time.Duration) {
+	wait := readBoolAnnotationWithDefault(o, helmWaitAnnotation, r.Wait)
+	waitForJobs := readBoolAnnotationWithDefault(o, helmWaitForJobsAnnotation, r.WaitForJobs)
+	timeout := r.WaitTimeout
+	if val, ok := o.GetAnnotations()[helmWaitTimeoutAnnotation]; ok {
+		d, err := time.ParseDuration(val)
+		if err != nil {
+			log.Error(err, "error parsing annotation", "annotation", helmWaitTimeoutAnnotation)
+		} else {
+			timeout = d
+		}
+	}
+	return wait, waitForJobs, timeout
+}
+
+// setTestsPending marks the tests of a newly deployed release as pending, so
+// that they are run by runPendingTests once its resources are ready.
+func setTestsPending(status *types.HelmAppStatus) {
+	status.SetCondition(types.HelmAppCondition{
+		Type:    types.ConditionTested,
+		Status:  types.StatusUnknown,
+		Reason:  types.ReasonTestsPending,
+		Message: "Waiting for resources to become ready before running tests",
+	})
+}
+
+// runPendingTests runs the tests of rel if they are pending and its resources
+// are ready. While the resources are not ready and the wait timeout, measured
+// from the last deployment of rel, has not elapsed, the CR is requeued
+// shortly instead; once it has elapsed, the tests are run regardless, so that
+// they report the failure. If the tests of an upgrade fail and
+// RollbackOnTestFailure is set, the release is rolled back to its previous
+// revision, upgrades are suspended until the spec of o changes, and an error
+// is returned.
+func (r HelmOperatorReconciler) runPendingTests(ctx context.Context, o *unstructured.Unstructured, manager release.Manager,
+	rel *rpb.Release, status *types.HelmAppStatus, result reconcile.Result) (reconcile.Result, error) {
+	tested := status.GetCondition(types.ConditionTested)
+	if !r.RunTests || tested == nil || tested.Reason != types.ReasonTestsPending {
+		return result, nil
+	}
+
+	_, waitForJobs, timeout := r.waitOptionsFor(o)
+	notReady, err := manager.NotReadyResources(ctx, rel.Manifest, waitForJobs)
+	if err != nil {
+		log.Error(err, "Failed to check readiness of release resources before running tests")
+	}
+	var lastDeployed time.Time
+	if rel.Info != nil {
+		lastDeployed = rel.Info.LastDeployed.Time
+	}
+	if (err != nil || len(notReady) > 0) && time.Since(lastDeployed) <= timeout {
+		if result.RequeueAfter == 0 || result.RequeueAfter > readinessRequeueInterval {
+			result.RequeueAfter = readinessRequeueInterval
+		}
+		return result, nil
+	}
+
+	if err := r.testRelease(manager, status); err != nil {
+		log.Error(err, "Release tests failed")
+		// There is no previous revision to roll back to, so failing tests
+		// of a new installation are only reported on the CR.
+		if !r.RollbackOnTestFailure || rel.Version <= 1 {
+			return result, nil
+		}
+		forceRollback := readBoolAnnotationWithDefault(o, helmRollbackForceAnnotation, true)
+		if rollbackErr := r.rollBack(ctx, manager, release.ForceRollback(forceRollback)); rollbackErr != nil {
+			log.Error(rollbackErr, "Error rolling back release")
+			status.SetCondition(types.HelmAppCondition{
+				Type:    types.ConditionReleaseFailed,
+				Status:  types.StatusTrue,
+				Reason:  types.ReasonRollbackError,
+				Message: fmt.Sprintf("failed to roll back upgrade after release tests failed: %v", rollbackErr),
+			})
+			return reconcile.Result{}, fmt.Errorf("failed to roll back release after tests failed: %w", rollbackErr)
+		}
+		status.SetCondition(types.HelmAppCondition{
+			Type:    types.ConditionReleaseFailed,
+			Status:  types.StatusTrue,
+			Reason:  types.ReasonUpgradeError,
+			Message: fmt.Sprintf("upgrade rolled back after release tests failed: %v", err),
+		})
+		// Like after a rollback requested with the rollback-to annotation,
+		// upgrades are suspended until the spec changes, since the same
+		// upgrade would fail its tests again.
+		status.Rollback = &types.HelmAppRollback{
+			Revision:   rel.Version - 1,
+			Generation: o.GetGeneration(),
+			Timestamp:  metav1.Now(),
+		}
+		r.setHistory(ctx, manager, status)
+		return reconcile.Result{}, err
+	}
+	return result, nil
+}
+
+// testRelease runs the test hooks of the release managed by manager and
+// records the result of each test in the Tested condition. An error is
+// returned if any test failed or the tests could not be run.
+func (r HelmOperatorReconciler) testRelease(manager release.Manager, status *types.HelmAppStatus) error {
+	testedRelease, err := manager.TestRelease(release.TestTimeout(r.TestTimeout))
+	results := testResults(testedRelease)
+	if err != nil {
+		message := err.Error()
+		if results != "" {
+			message = fmt.Sprintf("%s (%s)", message, results)
+		}
+		status.SetCondition(types.HelmAppCondition{
+			Type:    types.ConditionTested,
+			Status:  types.StatusFalse,
+			Reason:  types.ReasonTestsFailed,
+			Message: message,
+		})
+		return err
+	}
+	if results == "" {
+		results = "No tests defined"
+	}
+	status.SetCondition(types.HelmAppCondition{
+		Type:    types.ConditionTested,
+		Status:  types.StatusTrue,
+		Reason:  types.ReasonTestsPassed,
+		Message: results,
+	})
+	return nil
+}
+
+// testResults summarizes the last run phase of each test hook of rel.
+func testResults(rel *rpb.Release) string {
+	if rel == nil {
+		return ""
+	}
+	var results []string
+	for _, h := range rel.Hooks {
+		for _, e := range h.Events {
+			if e == rpb.HookTest {
+				results = append(results, fmt.Sprintf("%s: %s", h.Name, h.LastRun.Phase))
+				break
+			}
+		}
+	}
+	return strings.Join(results, ", ")
+}
+
+// recordDiff logs the line diffs of the resources changed by a release
+// operation and emits an event on o summarizing the changes. The message of
+// the event starts with operation and is truncated to the maximum length of
+// event messages.
+func (r HelmOperatorReconciler) recordDiff(log logr.Logger, o *unstructured.Unstructured, operation string, summary diff.Summary) {
+	if summary.IsEmpty() {
+		return
+	}
+	log.V(1).Info("Release diff", "diff", summary)
+	prefix := operation + ": "
+	r.EventRecorder.Event(o, "Normal", "ReleaseDiff", prefix+summary.Message(maxEventMessageLength-len(prefix)))
+}
+
+// recordDeferredDriftCorrections sets the DriftDetected condition for the
+// resources that ReconcileRelease would correct outside of a maintenance
+// window, and records the start of the next window, in which they are
+// corrected.
+func (r HelmOperatorReconciler) recordDeferredDriftCorrections(status *types.HelmAppStatus,
+	corrections []release.ResourceCorrection, next *metav1.Time) {
+	if len(corrections) == 0 {
+		status.SetCondition(types.HelmAppCondition{
+			Type:   types.ConditionDriftDetected,
+			Status: types.StatusFalse,
+			Reason: types.ReasonNoDrift,
+		})
+		return
+	}
+	message := fmt.Sprintf("%d resource(s) drifted from the deployed release and will be corrected in the next maintenance window",
+		len(corrections))
+	if next != nil {
+		status.NextMaintenanceWindow = next
+		message = fmt.Sprintf("%s, starting at %s", message, next.UTC().Format(time.RFC3339))
+	}
+	status.SetCondition(types.HelmAppCondition{
+		Type:    types.ConditionDriftDetected,
+		Status:  types.StatusTrue,
+		Reason:  types.ReasonDriftDeferred,
+		Message: message,
+	})
+}
+
+// recordDriftCorrections records the resources corrected by ReconcileRelease
+// in the status of o, sets the DriftDetected condition and emits an event
+// for each correction.
+func (r HelmOperatorReconciler) recordDriftCorrections(o *unstructured.Unstructured, status *types.HelmAppStatus, corrections []release.ResourceCorrection) {
+	if len(corrections) == 0 {
+		status.SetCondition(types.HelmAppCondition{
+			Type:   types.ConditionDriftDetected,
+			Status: types.StatusFalse,
+			Reason: types.ReasonNoDrift,
+		})
+		return
+	}
+
+	now := metav1.Now()
+	driftCorrections := make([]types.HelmAppDriftCorrection, 0, len(corrections))
+	for _, c := range corrections {
+		apiVersion, kind := c.GroupVersionKind.ToAPIVersionAndKind()
+		driftCorrections = append(driftCorrections, types.HelmAppDriftCorrection{
+			APIVersion: apiVersion,
+			Kind:       kind,
+			Namespace:  c.Namespace,
+			Name:       c.Name,
+			Action:     string(c.Action),
+			Timestamp:  now,
+		})
+		metrics.AddDriftCorrection(r.GVK, string(c.Action))
+		r.EventRecorder.Eventf(o, "Warning", "DriftCorrected", "%s %s %s to match the deployed release",
+			c.Action, kind, namespacedName(c.Namespace, c.Name))
+	}
+	status.AddDriftCorrections(driftCorrections...)
+	status.SetCondition(types.HelmAppCondition{
+		Type:    types.ConditionDriftDetected,
+		Status:  types.StatusTrue,
+		Reason:  types.ReasonDriftCorrected,
+		Message: fmt.Sprintf("Corrected %d resource(s) that drifted from the deployed release", len(corrections)),
+	})
+}
+
+func namespacedName(namespace, name string) string {
+	if namespace == "" {
+		return name
+	}
+	return namespace + "/" + name
+}
+
 // returns the reconcile period that will be set to the RequeueAfter field in the reconciler. If any period
 // is specified in the custom resource's annotations, this will be returned. If not, the existing reconcile period
 // will be returned. An error will be thrown if the custom resource time period is not in proper format.
@@ -484,10 +1257,54 @@ func (r HelmOperatorReconciler) updateResource(ctx context.Context, o client.Obj
 }
 
 func (r HelmOperatorReconciler) updateResourceStatus(ctx context.Context, o *unstructured.Unstructured, status *types.HelmAppStatus) error {
-	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+	ctx, span := tracing.Start(ctx, "UpdateStatus")
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
 		o.Object["status"] = status
 		return r.Client.Status().Update(ctx, o)
 	})
+	tracing.End(span, err)
+	return err
+}
+
+// startOperation starts a span named name for the release operation op. The
+// returned function ends the span and records the operation in the release
+// operation metrics, unless the release was not found.
+func (r HelmOperatorReconciler) startOperation(ctx context.Context, op metrics.Operation, name string) (context.Context, func(error)) {
+	start := time.Now()
+	ctx, span := tracing.Start(ctx, name)
+	return ctx, func(err error) {
+		if errors.Is(err, driver.ErrReleaseNotFound) {
+			span.End()
+			return
+		}
+		tracing.End(span, err)
+		metrics.ObserveReleaseOperation(r.GVK, op, start, err)
+	}
+}
+
+// rollBack rolls back the release of manager and records the rollback in the
+// release operation metrics.
+func (r HelmOperatorReconciler) rollBack(ctx context.Context, manager release.Manager, opts ...release.RollBackOption) error {
+	_, done := r.startOperation(ctx, metrics.OperationRollback, "RollBack")
+	err := manager.RollBack(opts...)
+	done(err)
+	return err
+}
+
+// recordReleaseState records the state of the release of o, as reported by
+// status, in the releases metric.
+func (r HelmOperatorReconciler) recordReleaseState(o *unstructured.Unstructured, status *types.HelmAppStatus) {
+	key := client.ObjectKeyFromObject(o)
+	switch {
+	case o.GetDeletionTimestamp() != nil:
+		metrics.SetReleaseState(r.GVK, key, metrics.ReleaseStateUninstalling)
+	case status.IsConditionTrue(types.ConditionReleaseFailed):
+		metrics.SetReleaseState(r.GVK, key, metrics.ReleaseStateFailed)
+	case status.DeployedRelease != nil:
+		metrics.SetReleaseState(r.GVK, key, metrics.ReleaseStateDeployed)
+	default:
+		metrics.DeleteReleaseState(r.GVK, key)
+	}
 }
 
 func (r HelmOperatorReconciler) waitForDeletion(ctx context.Context, o client.Object) error {
@@ -15,11 +15,43 @@
 package controller
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	rpb "helm.sh/helm/v3/pkg/release"
+	helmtime "helm.sh/helm/v3/pkg/time"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	apitypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/operator-framework/operator-sdk/internal/helm/internal/diff"
+	"github.com/operator-framework/operator-sdk/internal/helm/internal/types"
+	"github.com/operator-framework/operator-sdk/internal/helm/maintenance"
+	"github.com/operator-framework/operator-sdk/internal/helm/metrics"
+	"github.com/operator-framework/operator-sdk/internal/helm/release"
+	"github.com/operator-framework/operator-sdk/internal/helm/tracing"
 )
 
 func TestDetermineReconcilePeriod(t *testing.T) {
@@ -251,3 +283,933 @@ func Test_readBoolAnnotationWithDefault(t *testing.T) {
 		})
 	}
 }
+
+func TestRecordDriftCorrections(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	r := HelmOperatorReconciler{EventRecorder: recorder}
+	o := &unstructured.Unstructured{}
+	status := &types.HelmAppStatus{}
+
+	r.recordDriftCorrections(o, status, nil)
+	assert.Empty(t, status.DriftCorrections)
+	assert.Equal(t, types.StatusFalse, status.Conditions[0].Status)
+	assert.Empty(t, recorder.Events)
+
+	r.recordDriftCorrections(o, status, []release.ResourceCorrection{
+		{
+			GroupVersionKind: schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Namespace:        "ns",
+			Name:             "test",
+			Action:           release.CorrectionPatched,
+		},
+		{
+			GroupVersionKind: schema.GroupVersionKind{Version: "v1", Kind: "Namespace"},
+			Name:             "test",
+			Action:           release.CorrectionCreated,
+		},
+	})
+	assert.Len(t, status.DriftCorrections, 2)
+	assert.Equal(t, "v1", status.DriftCorrections[0].APIVersion)
+	assert.Equal(t, "Namespace", status.DriftCorrections[0].Kind)
+	assert.Equal(t, "apps/v1", status.DriftCorrections[1].APIVersion)
+	assert.Equal(t, "ns", status.DriftCorrections[1].Namespace)
+	assert.Equal(t, "Patched", status.DriftCorrections[1].Action)
+	assert.Equal(t, types.ConditionDriftDetected, status.Conditions[0].Type)
+	assert.Equal(t, types.StatusTrue, status.Conditions[0].Status)
+	assert.Equal(t, types.ReasonDriftCorrected, status.Conditions[0].Reason)
+	assert.Equal(t, "Warning DriftCorrected Patched Deployment ns/test to match the deployed release", <-recorder.Events)
+	assert.Equal(t, "Warning DriftCorrected Created Namespace test to match the deployed release", <-recorder.Events)
+}
+
+func TestRecordDeferredDriftCorrections(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	r := HelmOperatorReconciler{EventRecorder: recorder}
+	status := &types.HelmAppStatus{}
+	next := metav1.NewTime(time.Date(2026, 10, 24, 22, 0, 0, 0, time.UTC))
+
+	r.recordDeferredDriftCorrections(status, nil, &next)
+	assert.Equal(t, types.StatusFalse, status.Conditions[0].Status)
+	assert.Nil(t, status.NextMaintenanceWindow)
+
+	r.recordDeferredDriftCorrections(status, []release.ResourceCorrection{{
+		GroupVersionKind: schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+		Namespace:        "ns",
+		Name:             "test",
+		Action:           release.CorrectionPatched,
+	}}, &next)
+	assert.Empty(t, status.DriftCorrections)
+	assert.Equal(t, &next, status.NextMaintenanceWindow)
+	assert.Equal(t, types.StatusTrue, status.Conditions[0].Status)
+	assert.Equal(t, types.ReasonDriftDeferred, status.Conditions[0].Reason)
+	assert.Equal(t, "1 resource(s) drifted from the deployed release and will be corrected in the next maintenance window, starting at 2026-10-24T22:00:00Z",
+		status.Conditions[0].Message)
+	assert.Empty(t, recorder.Events)
+}
+
+type fakeManager struct {
+	release.Manager
+	name     string
+	owner    apitypes.UID
+	rel      *rpb.Release
+	err      error
+	notReady []string
+	history  []*rpb.Release
+	adopted  int
+
+	subcharts []types.HelmAppSubchart
+	images    []types.HelmAppImage
+
+	// rolledBackTo records the revision of the last rollback.
+	rolledBackTo *int
+	// rollbackErr is returned by RollBack.
+	rollbackErr error
+}
+
+func (m fakeManager) RollBack(opts ...release.RollBackOption) error {
+	rollback := &action.Rollback{}
+	for _, o := range opts {
+		if err := o(rollback); err != nil {
+			return err
+		}
+	}
+	if m.rolledBackTo != nil {
+		*m.rolledBackTo = rollback.Version
+	}
+	return m.rollbackErr
+}
+
+func (m fakeManager) ReleaseName() string {
+	if m.name != "" {
+		return m.name
+	}
+	return "test"
+}
+
+func (m fakeManager) ReleaseOwner() (apitypes.UID, error) {
+	return m.owner, m.err
+}
+
+func (m fakeManager) History() ([]*rpb.Release, error) {
+	return m.history, nil
+}
+
+func (m fakeManager) TestRelease(...release.TestOption) (*rpb.Release, error) {
+	return m.rel, m.err
+}
+
+func (m fakeManager) NotReadyResources(context.Context, string, bool) ([]string, error) {
+	return m.notReady, m.err
+}
+
+func (m fakeManager) AdoptRelease(context.Context) (int, error) {
+	return m.adopted, m.err
+}
+
+func (m fakeManager) DryRunRelease(context.Context) (*rpb.Release, error) {
+	return m.rel, m.err
+}
+
+func (m fakeManager) Subcharts(*rpb.Release) []types.HelmAppSubchart {
+	return m.subcharts
+}
+
+func (m fakeManager) Images(context.Context, *rpb.Release) []types.HelmAppImage {
+	return m.images
+}
+
+func TestTestRelease(t *testing.T) {
+	testHook := func(name string, phase rpb.HookPhase) *rpb.Hook {
+		return &rpb.Hook{Name: name, Events: []rpb.HookEvent{rpb.HookTest}, LastRun: rpb.HookExecution{Phase: phase}}
+	}
+	rel := &rpb.Release{
+		Hooks: []*rpb.Hook{
+			{Name: "pre-install", Events: []rpb.HookEvent{rpb.HookPreInstall}},
+			testHook("test-connection", rpb.HookPhaseSucceeded),
+			testHook("test-api", rpb.HookPhaseFailed),
+		},
+	}
+
+	tests := []struct {
+		name    string
+		manager fakeManager
+		status  types.ConditionStatus
+		reason  types.HelmAppConditionReason
+		message string
+	}{
+		{
+			name:    "no tests",
+			manager: fakeManager{rel: &rpb.Release{}},
+			status:  types.StatusTrue,
+			reason:  types.ReasonTestsPassed,
+			message: "No tests defined",
+		},
+		{
+			name: "tests passed",
+			manager: fakeManager{rel: &rpb.Release{Hooks: []*rpb.Hook{
+				testHook("test-connection", rpb.HookPhaseSucceeded),
+			}}},
+			status:  types.StatusTrue,
+			reason:  types.ReasonTestsPassed,
+			message: "test-connection: Succeeded",
+		},
+		{
+			name:    "tests failed",
+			manager: fakeManager{rel: rel, err: errors.New("pod test-api failed")},
+			status:  types.StatusFalse,
+			reason:  types.ReasonTestsFailed,
+			message: "pod test-api failed (test-connection: Succeeded, test-api: Failed)",
+		},
+		{
+			name:    "tests not run",
+			manager: fakeManager{err: errors.New("release not found")},
+			status:  types.StatusFalse,
+			reason:  types.ReasonTestsFailed,
+			message: "release not found",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			status := &types.HelmAppStatus{}
+			err := HelmOperatorReconciler{}.testRelease(tc.manager, status)
+			assert.Equal(t, tc.manager.err, err)
+			assert.Equal(t, types.ConditionTested, status.Conditions[0].Type)
+			assert.Equal(t, tc.status, status.Conditions[0].Status)
+			assert.Equal(t, tc.reason, status.Conditions[0].Reason)
+			assert.Equal(t, tc.message, status.Conditions[0].Message)
+		})
+	}
+}
+
+func TestWaitOptionsFor(t *testing.T) {
+	r := HelmOperatorReconciler{Wait: false, WaitForJobs: true, WaitTimeout: time.Minute}
+
+	wait, waitForJobs, timeout := r.waitOptionsFor(&unstructured.Unstructured{})
+	assert.False(t, wait)
+	assert.True(t, waitForJobs)
+	assert.Equal(t, time.Minute, timeout)
+
+	o := &unstructured.Unstructured{}
+	o.SetAnnotations(map[string]string{
+		helmWaitAnnotation:        "true",
+		helmWaitForJobsAnnotation: "false",
+		helmWaitTimeoutAnnotation: "10m",
+	})
+	wait, waitForJobs, timeout = r.waitOptionsFor(o)
+	assert.True(t, wait)
+	assert.False(t, waitForJobs)
+	assert.Equal(t, 10*time.Minute, timeout)
+
+	o.SetAnnotations(map[string]string{helmWaitTimeoutAnnotation: "invalid"})
+	_, _, timeout = r.waitOptionsFor(o)
+	assert.Equal(t, time.Minute, timeout)
+}
+
+func TestCheckReadiness(t *testing.T) {
+	deployedAt := func(d time.Time) *rpb.Release {
+		return &rpb.Release{Info: &rpb.Info{LastDeployed: helmtime.Time{Time: d}}}
+	}
+	result := reconcile.Result{RequeueAfter: time.Minute}
+
+	tests := []struct {
+		name        string
+		wait        bool
+		manager     fakeManager
+		rel         *rpb.Release
+		expectCond  bool
+		status      types.ConditionStatus
+		reason      types.HelmAppConditionReason
+		expectAfter time.Duration
+	}{
+		{
+			name:        "wait disabled",
+			manager:     fakeManager{notReady: []string{"deployments/test"}},
+			rel:         deployedAt(time.Now()),
+			expectAfter: time.Minute,
+		},
+		{
+			name:        "resources ready",
+			wait:        true,
+			manager:     fakeManager{},
+			rel:         deployedAt(time.Now()),
+			expectCond:  true,
+			status:      types.StatusTrue,
+			reason:      types.ReasonResourcesReady,
+			expectAfter: time.Minute,
+		},
+		{
+			name:        "resources converging",
+			wait:        true,
+			manager:     fakeManager{notReady: []string{"deployments/test"}},
+			rel:         deployedAt(time.Now()),
+			expectCond:  true,
+			status:      types.StatusFalse,
+			reason:      types.ReasonResourcesNotReady,
+			expectAfter: readinessRequeueInterval,
+		},
+		{
+			name:        "readiness timeout",
+			wait:        true,
+			manager:     fakeManager{notReady: []string{"deployments/test"}},
+			rel:         deployedAt(time.Now().Add(-time.Hour)),
+			expectCond:  true,
+			status:      types.StatusFalse,
+			reason:      types.ReasonReadinessTimeout,
+			expectAfter: time.Minute,
+		},
+		{
+			name:        "readiness check error",
+			wait:        true,
+			manager:     fakeManager{err: errors.New("boom")},
+			rel:         deployedAt(time.Now()),
+			expectCond:  true,
+			status:      types.StatusUnknown,
+			reason:      types.ReasonReadinessCheckError,
+			expectAfter: time.Minute,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := HelmOperatorReconciler{Wait: tc.wait, WaitTimeout: 5 * time.Minute}
+			status := &types.HelmAppStatus{}
+			res := r.checkReadiness(context.TODO(), &unstructured.Unstructured{}, tc.manager, tc.rel, status, result)
+			assert.Equal(t, tc.expectAfter, res.RequeueAfter)
+			if !tc.expectCond {
+				assert.Empty(t, status.Conditions)
+				return
+			}
+			assert.Equal(t, types.ConditionReady, status.Conditions[0].Type)
+			assert.Equal(t, tc.status, status.Conditions[0].Status)
+			assert.Equal(t, tc.reason, status.Conditions[0].Reason)
+		})
+	}
+}
+
+func TestRunPendingTests(t *testing.T) {
+	deployed := func(version int, d time.Time) *rpb.Release {
+		return &rpb.Release{Version: version, Info: &rpb.Info{LastDeployed: helmtime.Time{Time: d}}}
+	}
+	result := reconcile.Result{RequeueAfter: time.Minute}
+
+	tests := []struct {
+		name           string
+		pending        bool
+		rollback       bool
+		manager        fakeManager
+		rel            *rpb.Release
+		reason         types.HelmAppConditionReason
+		expectErr      bool
+		expectRollback bool
+		expectFailed   types.HelmAppConditionReason
+		expectAfter    time.Duration
+	}{
+		{
+			name:        "tests not pending",
+			manager:     fakeManager{rel: &rpb.Release{}},
+			rel:         deployed(1, time.Now()),
+			reason:      types.ReasonTestsPassed,
+			expectAfter: time.Minute,
+		},
+		{
+			name:        "resources ready",
+			pending:     true,
+			manager:     fakeManager{rel: &rpb.Release{}},
+			rel:         deployed(1, time.Now()),
+			reason:      types.ReasonTestsPassed,
+			expectAfter: time.Minute,
+		},
+		{
+			name:        "resources converging",
+			pending:     true,
+			manager:     fakeManager{rel: &rpb.Release{}, notReady: []string{"deployments/test"}},
+			rel:         deployed(1, time.Now()),
+			reason:      types.ReasonTestsPending,
+			expectAfter: readinessRequeueInterval,
+		},
+		{
+			name:        "readiness timeout",
+			pending:     true,
+			manager:     fakeManager{rel: &rpb.Release{}, notReady: []string{"deployments/test"}},
+			rel:         deployed(1, time.Now().Add(-time.Hour)),
+			reason:      types.ReasonTestsPassed,
+			expectAfter: time.Minute,
+		},
+		{
+			name:        "install tests failed",
+			pending:     true,
+			rollback:    true,
+			manager:     fakeManager{err: errors.New("pod test-api failed")},
+			rel:         deployed(1, time.Now().Add(-time.Hour)),
+			reason:      types.ReasonTestsFailed,
+			expectAfter: time.Minute,
+		},
+		{
+			name:           "upgrade tests failed",
+			pending:        true,
+			rollback:       true,
+			manager:        fakeManager{err: errors.New("pod test-api failed")},
+			rel:            deployed(2, time.Now().Add(-time.Hour)),
+			reason:         types.ReasonTestsFailed,
+			expectErr:      true,
+			expectRollback: true,
+			expectFailed:   types.ReasonUpgradeError,
+		},
+		{
+			name:     "rollback after failed tests fails",
+			pending:  true,
+			rollback: true,
+			manager: fakeManager{err: errors.New("pod test-api failed"),
+				rollbackErr: errors.New("release: not found")},
+			rel:          deployed(2, time.Now().Add(-time.Hour)),
+			reason:       types.ReasonTestsFailed,
+			expectErr:    true,
+			expectFailed: types.ReasonRollbackError,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := HelmOperatorReconciler{RunTests: true, RollbackOnTestFailure: tc.rollback, WaitTimeout: 5 * time.Minute}
+			rolledBackTo := -1
+			tc.manager.rolledBackTo = &rolledBackTo
+			status := &types.HelmAppStatus{}
+			if tc.pending {
+				setTestsPending(status)
+			} else {
+				status.SetCondition(types.HelmAppCondition{
+					Type:   types.ConditionTested,
+					Status: types.StatusTrue,
+					Reason: types.ReasonTestsPassed,
+				})
+			}
+
+			o := &unstructured.Unstructured{}
+			o.SetGeneration(3)
+			res, err := r.runPendingTests(context.TODO(), o, tc.manager, tc.rel, status, result)
+			assert.Equal(t, tc.expectErr, err != nil)
+			assert.Equal(t, tc.expectAfter, res.RequeueAfter)
+			assert.Equal(t, tc.reason, status.GetCondition(types.ConditionTested).Reason)
+			assert.Equal(t, tc.expectErr, rolledBackTo == 0)
+			if tc.expectFailed != "" {
+				assert.Equal(t, tc.expectFailed, status.GetCondition(types.ConditionReleaseFailed).Reason)
+			} else {
+				assert.Nil(t, status.GetCondition(types.ConditionReleaseFailed))
+			}
+			if tc.expectRollback {
+				require.NotNil(t, status.Rollback)
+				assert.Equal(t, 1, status.Rollback.Revision)
+				assert.Equal(t, int64(3), status.Rollback.Generation)
+			} else {
+				assert.Nil(t, status.Rollback)
+			}
+		})
+	}
+}
+
+// upgradingManager is a manager whose release always requires an upgrade,
+// and whose tests always fail.
+type upgradingManager struct {
+	fakeManager
+	upgrades *int
+}
+
+func (m upgradingManager) Sync(context.Context) error {
+	return nil
+}
+
+func (m upgradingManager) IsInstalled() bool {
+	return true
+}
+
+func (m upgradingManager) IsUpgradeRequired() bool {
+	return true
+}
+
+func (m upgradingManager) UpgradeRelease(...release.UpgradeOption) (*rpb.Release, *rpb.Release, error) {
+	*m.upgrades++
+	upgraded := &rpb.Release{Name: "test", Version: m.rel.Version + 1, Info: &rpb.Info{}}
+	return m.rel, upgraded, nil
+}
+
+func (m upgradingManager) TestRelease(...release.TestOption) (*rpb.Release, error) {
+	return nil, errors.New("pod test-api failed")
+}
+
+func (m upgradingManager) ReconcileRelease(context.Context, ...release.ReconcileOption) (*rpb.Release, []release.ResourceCorrection, error) {
+	return m.rel, nil, nil
+}
+
+func TestReconcileRollbackOnTestFailure(t *testing.T) {
+	gvk := schema.GroupVersionKind{Group: "example.com", Version: "v1alpha1", Kind: "Nginx"}
+	o := &unstructured.Unstructured{}
+	o.SetGroupVersionKind(gvk)
+	o.SetNamespace("default")
+	o.SetName("test")
+	o.SetGeneration(1)
+	o.SetFinalizers([]string{uninstallFinalizer})
+	o.Object["status"] = map[string]any{"deployedRelease": map[string]any{"name": "test"}}
+	c := fake.NewClientBuilder().WithObjects(o).WithStatusSubresource(o).
+		WithInterceptorFuncs(interceptor.Funcs{SubResourceUpdate: updateStatusAsMap}).Build()
+	upgrades := 0
+	rolledBackTo := -1
+	manager := upgradingManager{
+		fakeManager: fakeManager{rel: &rpb.Release{Name: "test", Version: 1, Info: &rpb.Info{}}, rolledBackTo: &rolledBackTo},
+		upgrades:    &upgrades,
+	}
+	r := HelmOperatorReconciler{
+		Client:                c,
+		EventRecorder:         record.NewFakeRecorder(10),
+		GVK:                   gvk,
+		ManagerFactory:        fakeManagerFactory{manager: manager},
+		RunTests:              true,
+		RollbackOnTestFailure: true,
+	}
+	key := client.ObjectKeyFromObject(o)
+	defer metrics.DeleteReleaseState(gvk, key)
+
+	_, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: key})
+	assert.ErrorContains(t, err, "pod test-api failed")
+	assert.Equal(t, 1, upgrades)
+	assert.Equal(t, 0, rolledBackTo)
+
+	// The release is not upgraded again until the spec changes.
+	_, err = r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: key})
+	require.NoError(t, err)
+	assert.Equal(t, 1, upgrades)
+
+	updated := &unstructured.Unstructured{}
+	updated.SetGroupVersionKind(gvk)
+	require.NoError(t, c.Get(context.TODO(), key, updated))
+	status := types.StatusFor(updated)
+	require.NotNil(t, status.Rollback)
+	assert.Equal(t, 1, status.Rollback.Revision)
+	assert.Equal(t, updated.GetGeneration(), status.Rollback.Generation)
+	assert.Equal(t, types.ReasonRollbackSuccessful, status.GetCondition(types.ConditionDeployed).Reason)
+}
+
+// updateStatusAsMap converts the typed status set by the reconciler to a map
+// before updating it, since the fake client cannot deep copy typed fields of
+// unstructured objects.
+func updateStatusAsMap(ctx context.Context, c client.Client, subResource string, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+	u := obj.(*unstructured.Unstructured)
+	if status, ok := u.Object["status"].(*types.HelmAppStatus); ok {
+		m, err := status.ToMap()
+		if err != nil {
+			return err
+		}
+		u.Object["status"] = m
+	}
+	return c.SubResource(subResource).Update(ctx, obj, opts...)
+}
+
+func TestRollbackTo(t *testing.T) {
+	gvk := schema.GroupVersionKind{Group: "example.com", Version: "v1alpha1", Kind: "Nginx"}
+	newCR := func(revision string) *unstructured.Unstructured {
+		o := &unstructured.Unstructured{}
+		o.SetGroupVersionKind(gvk)
+		o.SetNamespace("default")
+		o.SetName("test")
+		o.SetGeneration(2)
+		o.SetAnnotations(map[string]string{helmRollbackToAnnotation: revision, "other": "value"})
+		return o
+	}
+	history := []*rpb.Release{
+		{Version: 3, Chart: &chart.Chart{Metadata: &chart.Metadata{Version: "1.0.0"}},
+			Info: &rpb.Info{Status: rpb.StatusDeployed, Description: "Rollback to 1"}},
+		{Version: 2, Chart: &chart.Chart{Metadata: &chart.Metadata{Version: "1.1.0"}},
+			Info: &rpb.Info{Status: rpb.StatusSuperseded, Description: "Upgrade complete"}},
+	}
+
+	tests := []struct {
+		name       string
+		revision   string
+		err        error
+		expectCond types.HelmAppCondition
+		expectTo   int
+	}{
+		{
+			name:       "rollback succeeds",
+			revision:   "1",
+			expectCond: types.HelmAppCondition{Type: types.ConditionDeployed, Status: types.StatusTrue, Reason: types.ReasonRollbackSuccessful},
+			expectTo:   1,
+		},
+		{
+			name:       "rollback fails",
+			revision:   "5",
+			err:        errors.New("release: not found"),
+			expectCond: types.HelmAppCondition{Type: types.ConditionReleaseFailed, Status: types.StatusTrue, Reason: types.ReasonRollbackError},
+			expectTo:   5,
+		},
+		{
+			name:       "invalid revision",
+			revision:   "latest",
+			expectCond: types.HelmAppCondition{Type: types.ConditionReleaseFailed, Status: types.StatusTrue, Reason: types.ReasonRollbackError},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			o := newCR(tc.revision)
+			c := fake.NewClientBuilder().WithObjects(o).WithStatusSubresource(o).
+				WithInterceptorFuncs(interceptor.Funcs{SubResourceUpdate: updateStatusAsMap}).Build()
+			recorder := record.NewFakeRecorder(10)
+			r := HelmOperatorReconciler{Client: c, EventRecorder: recorder}
+			rolledBackTo := 0
+			manager := fakeManager{rollbackErr: tc.err, history: history, rolledBackTo: &rolledBackTo}
+			status := &types.HelmAppStatus{}
+
+			_, err := r.rollbackTo(context.TODO(), logr.Discard(), o, manager, status, reconcile.Result{})
+			assert.Equal(t, tc.err, err)
+			assert.Equal(t, tc.expectTo, rolledBackTo)
+
+			// The annotation is kept if the rollback failed, so that it is
+			// retried.
+			updated := &unstructured.Unstructured{}
+			updated.SetGroupVersionKind(gvk)
+			require.NoError(t, c.Get(context.TODO(), client.ObjectKeyFromObject(o), updated))
+			if tc.err != nil {
+				assert.Equal(t, map[string]string{helmRollbackToAnnotation: tc.revision, "other": "value"}, updated.GetAnnotations())
+			} else {
+				assert.Equal(t, map[string]string{"other": "value"}, updated.GetAnnotations())
+			}
+
+			cond := status.Conditions[len(status.Conditions)-1]
+			assert.Equal(t, tc.expectCond.Type, cond.Type)
+			assert.Equal(t, tc.expectCond.Status, cond.Status)
+			assert.Equal(t, tc.expectCond.Reason, cond.Reason)
+			if tc.expectCond.Reason == types.ReasonRollbackSuccessful {
+				assert.Equal(t, &types.HelmAppRollback{Revision: 1, Generation: 2, Timestamp: status.Rollback.Timestamp}, status.Rollback)
+				assert.Len(t, status.History, 2)
+				assert.Equal(t, "1.0.0", status.History[0].ChartVersion)
+				assert.Equal(t, "deployed", status.History[0].Status)
+				assert.Equal(t, "Normal RolledBack Rolled back release to revision 1", <-recorder.Events)
+			} else {
+				assert.Nil(t, status.Rollback)
+				assert.Empty(t, status.History)
+				assert.Contains(t, <-recorder.Events, "Warning RollbackFailed")
+			}
+		})
+	}
+}
+
+func TestDeployedRevision(t *testing.T) {
+	newRelease := func(version int, status rpb.Status) *rpb.Release {
+		return &rpb.Release{Version: version, Info: &rpb.Info{Status: status}}
+	}
+	assert.Nil(t, deployedRevision(nil))
+	assert.Nil(t, deployedRevision([]*rpb.Release{newRelease(1, rpb.StatusFailed), {Version: 2}}))
+	assert.Equal(t, 2, deployedRevision([]*rpb.Release{
+		newRelease(3, rpb.StatusFailed),
+		newRelease(2, rpb.StatusDeployed),
+		newRelease(1, rpb.StatusSuperseded),
+	}).Version)
+}
+
+type failingManagerFactory struct {
+	release.ManagerFactory
+	err error
+}
+
+func (f failingManagerFactory) NewManager(context.Context, *unstructured.Unstructured, map[string]string, string) (release.Manager, error) {
+	return nil, f.err
+}
+
+type fakeManagerFactory struct {
+	release.ManagerFactory
+	manager release.Manager
+}
+
+func (f fakeManagerFactory) NewManager(context.Context, *unstructured.Unstructured, map[string]string, string) (release.Manager, error) {
+	return f.manager, nil
+}
+
+func TestReconcileTracing(t *testing.T) {
+	spans := tracetest.NewSpanRecorder()
+	defer otel.SetTracerProvider(otel.GetTracerProvider())
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)))
+
+	gvk := schema.GroupVersionKind{Group: "example.com", Version: "v1alpha1", Kind: "Nginx"}
+	o := &unstructured.Unstructured{}
+	o.SetGroupVersionKind(gvk)
+	o.SetNamespace("default")
+	o.SetName("test")
+	o.SetUID("6f1c2e4a")
+	r := HelmOperatorReconciler{
+		Client:         fake.NewClientBuilder().WithObjects(o).Build(),
+		GVK:            gvk,
+		ManagerFactory: failingManagerFactory{err: errors.New("failed to load chart")},
+	}
+
+	_, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: client.ObjectKeyFromObject(o)})
+	require.Error(t, err)
+
+	ended := spans.Ended()
+	require.Len(t, ended, 1)
+	assert.Equal(t, "Reconcile", ended[0].Name())
+	assert.Equal(t, codes.Error, ended[0].Status().Code)
+	assert.Equal(t, "failed to load chart", ended[0].Status().Description)
+	attrs := map[attribute.Key]string{}
+	for _, kv := range ended[0].Attributes() {
+		attrs[kv.Key] = kv.Value.AsString()
+	}
+	assert.Equal(t, map[attribute.Key]string{
+		tracing.AttributeGroup:     "example.com",
+		tracing.AttributeVersion:   "v1alpha1",
+		tracing.AttributeKind:      "Nginx",
+		tracing.AttributeNamespace: "default",
+		tracing.AttributeName:      "test",
+		tracing.AttributeUID:       "6f1c2e4a",
+	}, attrs)
+}
+
+func TestRecordDiff(t *testing.T) {
+	o := &unstructured.Unstructured{}
+	o.SetGroupVersionKind(schema.GroupVersionKind{Group: "example.com", Version: "v1alpha1", Kind: "Nginx"})
+	o.SetNamespace("default")
+	o.SetName("test")
+	recorder := record.NewFakeRecorder(10)
+	r := HelmOperatorReconciler{EventRecorder: recorder}
+
+	r.recordDiff(log, o, "Upgraded release", diff.Summary{})
+	assert.Empty(t, recorder.Events)
+
+	var manifest strings.Builder
+	for i := 0; i < 100; i++ {
+		fmt.Fprintf(&manifest, "---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config-%03d\n", i)
+	}
+	r.recordDiff(log, o, "Installed release", diff.Summarize("", manifest.String()))
+	event := <-recorder.Events
+	assert.True(t, strings.HasPrefix(event, "Normal ReleaseDiff Installed release: 100 added, 0 changed, 0 removed: "+
+		"+ConfigMap config-000, +ConfigMap config-001"), event)
+	assert.True(t, strings.HasSuffix(event, "more"), event)
+	assert.LessOrEqual(t, len(strings.TrimPrefix(event, "Normal ReleaseDiff ")), maxEventMessageLength)
+}
+
+func TestReconcilePaused(t *testing.T) {
+	gvk := schema.GroupVersionKind{Group: "example.com", Version: "v1alpha1", Kind: "Nginx"}
+	o := &unstructured.Unstructured{}
+	o.SetGroupVersionKind(gvk)
+	o.SetNamespace("default")
+	o.SetName("test")
+	o.SetFinalizers([]string{uninstallFinalizer})
+	o.SetAnnotations(map[string]string{helmPausedAnnotation: "true"})
+	c := fake.NewClientBuilder().WithObjects(o).WithStatusSubresource(o).
+		WithInterceptorFuncs(interceptor.Funcs{SubResourceUpdate: updateStatusAsMap}).Build()
+	// The fake manager panics if the release is synced, installed or
+	// upgraded, since it does not implement those methods.
+	r := HelmOperatorReconciler{
+		Client:          c,
+		EventRecorder:   record.NewFakeRecorder(10),
+		GVK:             gvk,
+		ManagerFactory:  fakeManagerFactory{manager: fakeManager{}},
+		ReconcilePeriod: time.Minute,
+	}
+
+	result, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: client.ObjectKeyFromObject(o)})
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, result.RequeueAfter)
+
+	updated := &unstructured.Unstructured{}
+	updated.SetGroupVersionKind(gvk)
+	require.NoError(t, c.Get(context.TODO(), client.ObjectKeyFromObject(o), updated))
+	status := types.StatusFor(updated)
+	assert.True(t, status.IsConditionTrue(types.ConditionPaused))
+	assert.Equal(t, types.ReasonPausedByAnnotation, status.Conditions[len(status.Conditions)-1].Reason)
+	assert.Nil(t, status.DeployedRelease)
+}
+
+func TestReconcileRecordsReleaseState(t *testing.T) {
+	gvk := schema.GroupVersionKind{Group: "example.com", Version: "v1alpha1", Kind: "ReleaseState"}
+	o := &unstructured.Unstructured{}
+	o.SetGroupVersionKind(gvk)
+	o.SetNamespace("default")
+	o.SetName("test")
+	o.SetFinalizers([]string{uninstallFinalizer})
+	o.SetAnnotations(map[string]string{helmPausedAnnotation: "true"})
+	o.Object["status"] = map[string]any{"deployedRelease": map[string]any{"name": "test"}}
+	c := fake.NewClientBuilder().WithObjects(o).WithStatusSubresource(o).
+		WithInterceptorFuncs(interceptor.Funcs{SubResourceUpdate: updateStatusAsMap}).Build()
+	r := HelmOperatorReconciler{
+		Client:         c,
+		EventRecorder:  record.NewFakeRecorder(10),
+		GVK:            gvk,
+		ManagerFactory: fakeManagerFactory{manager: fakeManager{}},
+	}
+	registry := prometheus.NewRegistry()
+	metrics.RegisterReleaseMetrics(registry)
+	key := client.ObjectKeyFromObject(o)
+	defer metrics.DeleteReleaseState(gvk, key)
+
+	_, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: key})
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, releaseStateValue(t, registry, gvk, metrics.ReleaseStateDeployed))
+
+	// The state is recorded even if the status is unchanged, such as after a
+	// restart of the operator.
+	metrics.DeleteReleaseState(gvk, key)
+	_, err = r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: key})
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, releaseStateValue(t, registry, gvk, metrics.ReleaseStateDeployed))
+}
+
+// releaseStateValue returns the number of releases of gvk in state reported
+// by the releases gauge of registry.
+func releaseStateValue(t *testing.T, registry *prometheus.Registry, gvk schema.GroupVersionKind, state metrics.ReleaseState) float64 {
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if !strings.HasSuffix(family.GetName(), "_releases") {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, l := range m.GetLabel() {
+				labels[l.GetName()] = l.GetValue()
+			}
+			if labels["kind"] == gvk.Kind && labels["state"] == string(state) {
+				return m.GetGauge().GetValue()
+			}
+		}
+	}
+	return 0
+}
+
+func TestDeferChanges(t *testing.T) {
+	window, err := maintenance.Window{
+		Schedule: "0 22 * * sat",
+		Duration: metav1.Duration{Duration: 4 * time.Hour},
+	}.Parse()
+	require.NoError(t, err)
+	saturdayNight := time.Date(2026, 10, 17, 23, 0, 0, 0, time.UTC)
+	monday := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	next, deferred := HelmOperatorReconciler{}.deferChanges(monday)
+	assert.False(t, deferred)
+	assert.Nil(t, next)
+
+	r := HelmOperatorReconciler{MaintenanceWindow: window}
+	next, deferred = r.deferChanges(saturdayNight)
+	assert.False(t, deferred)
+	assert.Nil(t, next)
+
+	next, deferred = r.deferChanges(monday)
+	assert.True(t, deferred)
+	require.NotNil(t, next)
+	assert.True(t, time.Date(2026, 10, 24, 22, 0, 0, 0, time.UTC).Equal(next.Time), next.Time)
+}
+
+func TestAdoptRelease(t *testing.T) {
+	gvk := schema.GroupVersionKind{Group: "example.com", Version: "v1alpha1", Kind: "Nginx"}
+	o := &unstructured.Unstructured{}
+	o.SetGroupVersionKind(gvk)
+	o.SetNamespace("default")
+	o.SetName("test")
+	c := fake.NewClientBuilder().WithObjects(o).WithStatusSubresource(o).
+		WithInterceptorFuncs(interceptor.Funcs{SubResourceUpdate: updateStatusAsMap}).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := HelmOperatorReconciler{Client: c, EventRecorder: recorder, GVK: gvk}
+
+	status := &types.HelmAppStatus{}
+	require.NoError(t, r.adoptRelease(context.TODO(), o, fakeManager{adopted: 3}, status))
+	assert.Empty(t, status.Conditions)
+	assert.Equal(t, `Normal ReleaseAdopted Adopted existing release "test" and 3 of its resources`, <-recorder.Events)
+
+	err := r.adoptRelease(context.TODO(), o, fakeManager{err: errors.New("already controlled by Nginx other")}, status)
+	require.Error(t, err)
+	assert.True(t, status.IsConditionTrue(types.ConditionIrreconcilable))
+	assert.Equal(t, types.ReasonAdoptError, status.Conditions[0].Reason)
+	assert.Equal(t, "already controlled by Nginx other", status.Conditions[0].Message)
+}
+
+func TestCheckReleaseCollision(t *testing.T) {
+	gvk := schema.GroupVersionKind{Group: "example.com", Version: "v1alpha1", Kind: "Nginx"}
+	newCR := func(namespace, name string, created time.Time) *unstructured.Unstructured {
+		o := &unstructured.Unstructured{}
+		o.SetGroupVersionKind(gvk)
+		o.SetNamespace(namespace)
+		o.SetName(name)
+		o.SetUID(apitypes.UID(namespace + "/" + name))
+		o.SetCreationTimestamp(metav1.NewTime(created))
+		return o
+	}
+	now := time.Now().Truncate(time.Second)
+	older := newCR("team-a", "web", now.Add(-time.Hour))
+	newer := newCR("team-b", "web", now)
+	installed := newCR("team-c", "web", now.Add(time.Hour))
+	installed.Object["status"] = map[string]any{"deployedRelease": map[string]any{"name": "web", "namespace": "shared"}}
+	other := newCR("team-a", "api", now)
+	c := fake.NewClientBuilder().WithObjects(older, newer, installed, other).Build()
+
+	// All releases are installed in the same namespace.
+	naming, err := types.NewReleaseNaming("", "shared", "")
+	require.NoError(t, err)
+	r := HelmOperatorReconciler{Client: c, GVK: gvk, ReleaseNaming: naming}
+
+	check := func(o *unstructured.Unstructured, m fakeManager) error {
+		collision, err := r.checkReleaseCollision(context.TODO(), o, m)
+		require.NoError(t, err)
+		return collision
+	}
+	web := fakeManager{name: "web"}
+	assert.EqualError(t, check(newer, web), "release shared/web is already the release of Nginx team-c/web")
+	assert.NoError(t, check(other, fakeManager{name: "api"}))
+	// A release whose labels record the CR as its owner is its release, e.g.
+	// if its status was lost after installing it.
+	assert.NoError(t, check(newer, fakeManager{name: "web", owner: newer.GetUID()}))
+	assert.EqualError(t, check(newer, fakeManager{name: "web", owner: installed.GetUID()}),
+		"release shared/web is already the release of Nginx with UID team-c/web")
+	assert.EqualError(t, check(older, fakeManager{name: "web", owner: installed.GetUID()}),
+		"release shared/web is already the release of Nginx with UID team-c/web")
+
+	_, err = r.checkReleaseCollision(context.TODO(), newer, fakeManager{name: "web", err: errors.New("storage unavailable")})
+	assert.EqualError(t, err, "storage unavailable")
+
+	// CRs are not listed if the release records its owner.
+	listed := 0
+	r.Client = interceptor.NewClient(c, interceptor.Funcs{
+		List: func(ctx context.Context, cl client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+			listed++
+			return cl.List(ctx, list, opts...)
+		},
+	})
+	assert.NoError(t, check(newer, fakeManager{name: "web", owner: newer.GetUID()}))
+	assert.Error(t, check(older, fakeManager{name: "web", owner: newer.GetUID()}))
+	assert.Equal(t, 0, listed)
+	assert.Error(t, check(newer, web))
+	assert.Equal(t, 1, listed)
+	r.Client = c
+
+	require.NoError(t, c.Delete(context.TODO(), installed))
+	assert.EqualError(t, check(newer, web), "release shared/web is already the release of Nginx team-a/web")
+	assert.NoError(t, check(older, web))
+
+	// Releases of CRs of the same kind cannot collide by default.
+	r.ReleaseNaming = types.ReleaseNaming{}
+	assert.NoError(t, check(newer, web))
+}
+
+func TestReleaseCollision(t *testing.T) {
+	gvk := schema.GroupVersionKind{Group: "example.com", Version: "v1alpha1", Kind: "Nginx"}
+	o := &unstructured.Unstructured{}
+	o.SetGroupVersionKind(gvk)
+	o.SetNamespace("default")
+	o.SetName("test")
+	o.SetFinalizers([]string{uninstallFinalizer})
+	c := fake.NewClientBuilder().WithObjects(o).WithStatusSubresource(o).
+		WithInterceptorFuncs(interceptor.Funcs{SubResourceUpdate: updateStatusAsMap}).Build()
+	r := HelmOperatorReconciler{Client: c, GVK: gvk}
+	collision := errors.New("release default/test is already the release of Nginx other/test")
+
+	status := &types.HelmAppStatus{}
+	_, err := r.releaseCollision(context.TODO(), o, status, collision)
+	assert.Equal(t, collision, err)
+	assert.True(t, status.IsConditionTrue(types.ConditionIrreconcilable))
+	assert.Equal(t, types.ReasonReleaseNameCollision, status.Conditions[0].Reason)
+
+	// A deleted CR releases its finalizer without uninstalling the release.
+	require.NoError(t, c.Delete(context.TODO(), o))
+	deleted := &unstructured.Unstructured{}
+	deleted.SetGroupVersionKind(gvk)
+	require.NoError(t, c.Get(context.TODO(), client.ObjectKeyFromObject(o), deleted))
+	_, err = r.releaseCollision(context.TODO(), deleted, &types.HelmAppStatus{}, collision)
+	require.NoError(t, err)
+	err = c.Get(context.TODO(), client.ObjectKeyFromObject(o), deleted)
+	assert.True(t, apierrors.IsNotFound(err), err)
+}
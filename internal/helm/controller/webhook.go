@@ -0,0 +1,108 @@
+// Copyright 2026 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/operator-framework/operator-sdk/internal/helm/internal/types"
+)
+
+// ValidatingWebhookPath returns the path at which the validating admission
+// webhook of the custom resources of gvk is served. It is the path used by
+// Go operators scaffolded by kubebuilder.
+func ValidatingWebhookPath(gvk schema.GroupVersionKind) string {
+	return fmt.Sprintf("/validate-%s-%s-%s", strings.ReplaceAll(gvk.Group, ".", "-"), gvk.Version,
+		strings.ToLower(gvk.Kind))
+}
+
+// validator is the validating admission webhook of the custom resources
+// reconciled by a HelmOperatorReconciler. It rejects custom resources whose
+// release cannot be rendered, or collides with another release.
+type validator struct {
+	reconciler *HelmOperatorReconciler
+	decoder    admission.Decoder
+}
+
+var _ admission.Handler = &validator{}
+
+func newValidator(r *HelmOperatorReconciler) *validator {
+	return &validator{reconciler: r, decoder: admission.NewDecoder(runtime.NewScheme())}
+}
+
+func (v *validator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1.Create && req.Operation != admissionv1.Update {
+		return admission.Allowed("")
+	}
+	o := &unstructured.Unstructured{}
+	if err := v.decoder.DecodeRaw(req.Object, o); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	// The operator updates the metadata of custom resources being deleted to
+	// remove its finalizer, which must never be rejected.
+	if o.GetDeletionTimestamp() != nil {
+		return admission.Allowed("")
+	}
+	if req.Operation == admissionv1.Update {
+		old := &unstructured.Unstructured{}
+		if err := v.decoder.DecodeRaw(req.OldObject, old); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		if equality.Semantic.DeepEqual(old.Object["spec"], o.Object["spec"]) {
+			return admission.Allowed("spec is unchanged")
+		}
+	}
+
+	if err := v.reconciler.validateRelease(ctx, o); err != nil {
+		log.V(1).Info("Rejected custom resource", "namespace", o.GetNamespace(), "name", o.GetName(),
+			"kind", o.GetKind(), "reason", err.Error())
+		return admission.Denied(err.Error())
+	}
+	return admission.Allowed("")
+}
+
+// validateRelease returns an error if the release of o cannot be installed or
+// upgraded: its values cannot be merged, its chart cannot be rendered with
+// them or they do not match the values schema of the chart, or the release
+// collides with the release of another chart or custom resource.
+func (r HelmOperatorReconciler) validateRelease(ctx context.Context, o *unstructured.Unstructured) error {
+	manager, err := r.ManagerFactory.NewManager(ctx, o, r.OverrideValues, r.DryRunOption)
+	if err != nil {
+		return err
+	}
+	if types.StatusFor(o).DeployedRelease == nil {
+		collision, err := r.checkReleaseCollision(ctx, o, manager)
+		if err != nil {
+			return err
+		}
+		if collision != nil {
+			return collision
+		}
+	}
+	if _, err := manager.DryRunRelease(ctx); err != nil {
+		return fmt.Errorf("failed to render release: %w", err)
+	}
+	return nil
+}
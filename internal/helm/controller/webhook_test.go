@@ -0,0 +1,160 @@
+// Copyright 2026 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	apitypes "k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/operator-framework/operator-sdk/internal/helm/internal/types"
+)
+
+func TestValidatingWebhookPath(t *testing.T) {
+	gvk := schema.GroupVersionKind{Group: "cache.example.com", Version: "v1alpha1", Kind: "Nginx"}
+	assert.Equal(t, "/validate-cache-example-com-v1alpha1-nginx", ValidatingWebhookPath(gvk))
+}
+
+func TestValidatorHandle(t *testing.T) {
+	gvk := schema.GroupVersionKind{Group: "example.com", Version: "v1alpha1", Kind: "Nginx"}
+	newCR := func(namespace, name string, replicas int64) *unstructured.Unstructured {
+		o := &unstructured.Unstructured{Object: map[string]any{"spec": map[string]any{"replicas": replicas}}}
+		o.SetGroupVersionKind(gvk)
+		o.SetNamespace(namespace)
+		o.SetName(name)
+		o.SetUID(apitypes.UID(namespace + "/" + name))
+		o.SetCreationTimestamp(metav1.Now())
+		return o
+	}
+	raw := func(o *unstructured.Unstructured) runtime.RawExtension {
+		b, err := json.Marshal(o)
+		require.NoError(t, err)
+		return runtime.RawExtension{Raw: b}
+	}
+	request := func(op admissionv1.Operation, o, old *unstructured.Unstructured) admission.Request {
+		req := admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{Operation: op, Object: raw(o)}}
+		if old != nil {
+			req.OldObject = raw(old)
+		}
+		return req
+	}
+	renderErr := errors.New(`execution error at (test/templates/deployment.yaml:8:20): replicas is required`)
+
+	installed := newCR("team-a", "test", 1)
+	installed.Object["status"] = map[string]any{"deployedRelease": map[string]any{"name": "test", "namespace": "shared"}}
+	naming, err := types.NewReleaseNaming("", "shared", "")
+	require.NoError(t, err)
+	newValidatorFor := func(f types.ReleaseNaming, factory fakeManagerFactory) *validator {
+		return newValidator(&HelmOperatorReconciler{
+			Client:         fake.NewClientBuilder().WithObjects(installed).Build(),
+			GVK:            gvk,
+			ManagerFactory: factory,
+			ReleaseNaming:  f,
+		})
+	}
+	valid := fakeManagerFactory{manager: fakeManager{}}
+	invalid := fakeManagerFactory{manager: fakeManager{err: renderErr}}
+
+	deleting := newCR("team-b", "test", 1)
+	now := metav1.Now()
+	deleting.SetDeletionTimestamp(&now)
+	deleting.SetFinalizers([]string{uninstallFinalizer})
+
+	tests := []struct {
+		name      string
+		validator *validator
+		req       admission.Request
+		allowed   bool
+		message   string
+	}{
+		{
+			name:      "valid resources are allowed",
+			validator: newValidatorFor(types.ReleaseNaming{}, valid),
+			req:       request(admissionv1.Create, newCR("team-b", "test", 2), nil),
+			allowed:   true,
+		},
+		{
+			name:      "resources failing to render are denied",
+			validator: newValidatorFor(types.ReleaseNaming{}, invalid),
+			req:       request(admissionv1.Create, newCR("team-b", "test", 0), nil),
+			message:   "failed to render release: " + renderErr.Error(),
+		},
+		{
+			name:      "resources colliding with another release are denied",
+			validator: newValidatorFor(naming, valid),
+			req:       request(admissionv1.Create, newCR("team-b", "test", 2), nil),
+			message:   "release shared/test is already the release of Nginx team-a/test",
+		},
+		{
+			name:      "updates of the spec are validated",
+			validator: newValidatorFor(types.ReleaseNaming{}, invalid),
+			req:       request(admissionv1.Update, newCR("team-b", "test", 0), newCR("team-b", "test", 2)),
+			message:   "failed to render release: " + renderErr.Error(),
+		},
+		{
+			name:      "updates of the metadata are allowed",
+			validator: newValidatorFor(types.ReleaseNaming{}, invalid),
+			req:       request(admissionv1.Update, newCR("team-b", "test", 0), newCR("team-b", "test", 0)),
+			allowed:   true,
+			message:   "spec is unchanged",
+		},
+		{
+			name:      "resources being deleted are allowed",
+			validator: newValidatorFor(types.ReleaseNaming{}, invalid),
+			req:       request(admissionv1.Update, deleting, newCR("team-b", "test", 2)),
+			allowed:   true,
+		},
+		{
+			name:      "deletions are allowed",
+			validator: newValidatorFor(types.ReleaseNaming{}, invalid),
+			req:       admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{Operation: admissionv1.Delete}},
+			allowed:   true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.TODO(), time.Minute)
+			defer cancel()
+			resp := tc.validator.Handle(ctx, tc.req)
+			assert.Equal(t, tc.allowed, resp.Allowed)
+			if resp.Result != nil {
+				assert.Equal(t, tc.message, resp.Result.Message)
+			}
+		})
+	}
+
+	// Errors creating the release manager, such as invalid values, are
+	// reported as well.
+	r := &HelmOperatorReconciler{
+		GVK:            gvk,
+		ManagerFactory: failingManagerFactory{err: errors.New("failed to parse override values")},
+	}
+	resp := newValidator(r).Handle(context.TODO(), request(admissionv1.Create, newCR("team-b", "test", 2), nil))
+	assert.False(t, resp.Allowed)
+	assert.Equal(t, "failed to parse override values", resp.Result.Message)
+}
@@ -16,14 +16,21 @@ package flags
 
 import (
 	"crypto/tls"
+	"os"
+	"path/filepath"
 	"runtime"
 	"time"
 
 	"github.com/spf13/pflag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
+
+	"github.com/operator-framework/operator-sdk/internal/helm/config"
+	"github.com/operator-framework/operator-sdk/internal/helm/storage"
 )
 
 // Flags - Options to be used by a helm operator
@@ -40,6 +47,24 @@ type Flags struct {
 	EnableHTTP2             bool
 	SecureMetrics           bool
 	MetricsRequireRBAC      bool
+	ServerSideApply         bool
+	ForceConflicts          bool
+	ChartCacheDir           string
+	ReloadCharts            bool
+	MaxReleaseHistory       int
+	ReleaseStorage          string
+	ReleaseStorageConn      string
+	ImageMirrorsFile        string
+	PinImageDigests         bool
+	TracingEndpoint         string
+	TracingProtocol         string
+	TracingInsecure         bool
+	TracingSampleRatio      float64
+	EnableWebhook           bool
+	WebhookPort             int
+	WebhookCertDir          string
+	ManagerConfigPath       string
+	PrintConfig             bool
 
 	// If not nil, used to deduce which flags were set in the CLI.
 	flagSet *pflag.FlagSet
@@ -57,6 +82,98 @@ func (f *Flags) AddTo(flagSet *pflag.FlagSet) {
 		"Path to the watches file to use",
 	)
 
+	flagSet.StringVar(&f.ChartCacheDir,
+		"chart-cache-dir",
+		filepath.Join(os.TempDir(), "helm-operator", "charts"),
+		"Directory in which charts fetched from OCI registries and chart repositories are cached",
+	)
+
+	flagSet.BoolVar(&f.ReloadCharts,
+		"reload-charts",
+		true,
+		"Reload charts when their chart directory or the watches file changes, and reconcile all "+
+			"custom resources of the affected watches with the reloaded chart",
+	)
+
+	flagSet.IntVar(&f.MaxReleaseHistory,
+		"max-release-history",
+		1,
+		"Maximum number of revisions kept for each release, including the deployed revision. 0 keeps all revisions. "+
+			"Can be overridden per watch in the watches file.",
+	)
+
+	flagSet.StringVar(&f.ReleaseStorage,
+		"release-storage",
+		"secret",
+		`Backend in which Helm release records are stored: "secret", "configmap", "sql" (PostgreSQL) `+
+			`or "sqlite" (SQLite database file, for local testing). `+
+			"Can be overridden per watch in the watches file.",
+	)
+	flagSet.StringVar(&f.ReleaseStorageConn,
+		"release-storage-connection",
+		"",
+		"Connection string of the database of the sql or sqlite release storage backend. "+
+			"Defaults to the value of the HELM_DRIVER_SQL_CONNECTION_STRING environment variable.",
+	)
+
+	// Image flags.
+	flagSet.StringVar(&f.ImageMirrorsFile,
+		"image-mirrors",
+		"",
+		"Path to a file of ImageContentSourcePolicy or ImageDigestMirrorSet resources. The images of the pods and "+
+			"workloads of releases are rewritten to the first mirror of their source when they are applied.",
+	)
+	flagSet.BoolVar(&f.PinImageDigests,
+		"pin-image-digests",
+		false,
+		"Resolve the tags of the images of the pods and workloads of releases to digests, in their mirror if any, "+
+			"and apply the images by digest",
+	)
+
+	// Tracing flags.
+	flagSet.StringVar(&f.TracingEndpoint,
+		"tracing-endpoint",
+		"",
+		"Host and port, or URL, of the OpenTelemetry collector to export traces of reconciliations to with OTLP. "+
+			"Tracing is enabled if this flag or the OTEL_EXPORTER_OTLP_ENDPOINT or "+
+			"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT environment variable is set.",
+	)
+	flagSet.StringVar(&f.TracingProtocol,
+		"tracing-protocol",
+		"",
+		`OTLP protocol used to export traces, "grpc" or "http/protobuf". Defaults to the value of the `+
+			`OTEL_EXPORTER_OTLP_TRACES_PROTOCOL or OTEL_EXPORTER_OTLP_PROTOCOL environment variable, or "grpc".`,
+	)
+	flagSet.BoolVar(&f.TracingInsecure,
+		"tracing-insecure",
+		false,
+		"Export traces without TLS",
+	)
+	flagSet.Float64Var(&f.TracingSampleRatio,
+		"tracing-sample-ratio",
+		1,
+		"Ratio of reconciliations traced, between 0 and 1",
+	)
+
+	// Webhook flags.
+	flagSet.BoolVar(&f.EnableWebhook,
+		"enable-webhook",
+		false,
+		"Serve a validating admission webhook for the custom resources of every watch, which rejects "+
+			"custom resources whose release fails to render or collides with another release",
+	)
+	flagSet.IntVar(&f.WebhookPort,
+		"webhook-port",
+		webhook.DefaultPort,
+		"Port the webhook server binds to",
+	)
+	flagSet.StringVar(&f.WebhookCertDir,
+		"webhook-cert-dir",
+		"",
+		"Directory containing the tls.crt and tls.key serving certificate of the webhook server. "+
+			"Defaults to <temp-dir>/k8s-webhook-server/serving-certs.",
+	)
+
 	// Controller flags.
 	flagSet.DurationVar(&f.ReconcilePeriod,
 		"reconcile-period",
@@ -66,14 +183,32 @@ func (f *Flags) AddTo(flagSet *pflag.FlagSet) {
 	flagSet.IntVar(&f.MaxConcurrentReconciles,
 		"max-concurrent-reconciles",
 		runtime.NumCPU(),
-		"Maximum number of concurrent reconciles for controllers.",
+		"Maximum number of concurrent reconciles for controllers. Can be overridden per watch in the watches file.",
+	)
+	flagSet.BoolVar(&f.ServerSideApply,
+		"server-side-apply",
+		false,
+		"Use server-side apply to correct drift of release resources. Can be overridden per watch in the watches file.",
+	)
+	flagSet.BoolVar(&f.ForceConflicts,
+		"force-conflicts",
+		false,
+		"Take ownership of fields managed by other field managers when server-side apply reports conflicts. "+
+			"Can be overridden per watch in the watches file.",
 	)
 
-	_ = flagSet.MarkDeprecated("config",
-		`controller-runtime has deprecated the ComponentConfig package 
-and as such, the ability to load the configuation from a file. Since the helm operator relies on controller-runtime
-this flag will be removed when upgrading to a version of controller-runtime where the ComponentConfig package has been removed.
-see https://github.com/kubernetes-sigs/controller-runtime/issues/895 for more information.`)
+	// Config file flags.
+	flagSet.StringVar(&f.ManagerConfigPath,
+		"config",
+		"",
+		"Path to a HelmOperatorConfig file configuring the manager and the defaults of watches. "+
+			"Flags set in the command line take precedence over the values of the file.",
+	)
+	flagSet.BoolVar(&f.PrintConfig,
+		"print-config",
+		false,
+		"Print the effective configuration, merged from the config file and the flags, and exit",
+	)
 
 	// TODO(2.0.0): remove
 	flagSet.StringVar(&f.MetricsBindAddress,
@@ -179,13 +314,22 @@ func (f *Flags) ToManagerOptions(options manager.Options) manager.Options {
 	disableHTTP2 := func(c *tls.Config) {
 		c.NextProtos = []string{"http/1.1"}
 	}
-	if !f.EnableHTTP2 {
+	if options.WebhookServer == nil {
 		options.WebhookServer = webhook.NewServer(webhook.Options{
-			TLSOpts: []func(*tls.Config){disableHTTP2},
+			Port:    f.WebhookPort,
+			CertDir: f.WebhookCertDir,
 		})
+	}
+	if !f.EnableHTTP2 {
+		// HTTP/2 is also disabled for a webhook server from the config file.
+		if server, ok := options.WebhookServer.(*webhook.DefaultServer); ok {
+			server.Options.TLSOpts = append(server.Options.TLSOpts, disableHTTP2)
+		}
 		options.Metrics.TLSOpts = append(options.Metrics.TLSOpts, disableHTTP2)
 	}
-	options.Metrics.SecureServing = f.SecureMetrics
+	if changed("metrics-secure") || !options.Metrics.SecureServing {
+		options.Metrics.SecureServing = f.SecureMetrics
+	}
 
 	if f.MetricsRequireRBAC {
 		// FilterProvider is used to protect the metrics endpoint with authn/authz.
@@ -193,7 +337,48 @@ func (f *Flags) ToManagerOptions(options manager.Options) manager.Options {
 		// can access the metrics endpoint. The RBAC are configured in 'config/rbac/kustomization.yaml'. More info:
 		// https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.18.4/pkg/metrics/filters#WithAuthenticationAndAuthorization
 		options.Metrics.FilterProvider = filters.WithAuthenticationAndAuthorization
+	} else if changed("metrics-require-rbac") {
+		options.Metrics.FilterProvider = nil
 	}
 
 	return options
 }
+
+// ApplyWatchDefaults sets the flags configuring the defaults of watches to
+// the values of d, unless they were set in the CLI.
+func (f *Flags) ApplyWatchDefaults(d config.WatchDefaults) {
+	changed := func(flagName string) bool {
+		return f.flagSet != nil && f.flagSet.Changed(flagName)
+	}
+
+	if d.ReconcilePeriod != nil && !changed("reconcile-period") {
+		f.ReconcilePeriod = d.ReconcilePeriod.Duration
+	}
+	if d.MaxConcurrentReconciles != nil && !changed("max-concurrent-reconciles") {
+		f.MaxConcurrentReconciles = *d.MaxConcurrentReconciles
+	}
+	if d.MaxReleaseHistory != nil && !changed("max-release-history") {
+		f.MaxReleaseHistory = *d.MaxReleaseHistory
+	}
+	if d.ReleaseStorage != "" && !changed("release-storage") {
+		f.ReleaseStorage = string(d.ReleaseStorage)
+	}
+	if d.ServerSideApply != nil && !changed("server-side-apply") {
+		f.ServerSideApply = *d.ServerSideApply
+	}
+	if d.ForceConflicts != nil && !changed("force-conflicts") {
+		f.ForceConflicts = *d.ForceConflicts
+	}
+}
+
+// WatchDefaults returns the defaults of watches configured by f.
+func (f *Flags) WatchDefaults() config.WatchDefaults {
+	return config.WatchDefaults{
+		ReconcilePeriod:         &metav1.Duration{Duration: f.ReconcilePeriod},
+		MaxConcurrentReconciles: ptr.To(f.MaxConcurrentReconciles),
+		MaxReleaseHistory:       ptr.To(f.MaxReleaseHistory),
+		ReleaseStorage:          storage.Backend(f.ReleaseStorage),
+		ServerSideApply:         ptr.To(f.ServerSideApply),
+		ForceConflicts:          ptr.To(f.ForceConflicts),
+	}
+}
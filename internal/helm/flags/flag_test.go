@@ -15,12 +15,21 @@
 package flags_test
 
 import (
+	"crypto/tls"
+	"time"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/spf13/pflag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
+	"github.com/operator-framework/operator-sdk/internal/helm/config"
 	"github.com/operator-framework/operator-sdk/internal/helm/flags"
+	"github.com/operator-framework/operator-sdk/internal/helm/storage"
 )
 
 var _ = Describe("Flags", func() {
@@ -34,6 +43,7 @@ var _ = Describe("Flags", func() {
 			f = &flags.Flags{}
 			flagSet = pflag.NewFlagSet("test", pflag.ExitOnError)
 			f.AddTo(flagSet)
+			options = manager.Options{}
 		})
 
 		When("the flag is set", func() {
@@ -64,6 +74,87 @@ var _ = Describe("Flags", func() {
 				Expect(f.ToManagerOptions(options).Metrics.BindAddress).To(Equal(expOptionValue))
 			})
 		})
+		When("the webhook flags are set", func() {
+			It("configures the webhook server", func() {
+				parseArgs(flagSet, "--webhook-port", "9444", "--webhook-cert-dir", "/certs")
+				server, ok := f.ToManagerOptions(options).WebhookServer.(*webhook.DefaultServer)
+				Expect(ok).To(BeTrue())
+				Expect(server.Options.Port).To(Equal(9444))
+				Expect(server.Options.CertDir).To(Equal("/certs"))
+			})
+		})
+		When("the options are loaded from a config file", func() {
+			It("keeps the secure metrics option unless the flag is set", func() {
+				options.Metrics.SecureServing = true
+				parseArgs(flagSet)
+				Expect(f.ToManagerOptions(options).Metrics.SecureServing).To(BeTrue())
+				parseArgs(flagSet, "--metrics-secure=false")
+				Expect(f.ToManagerOptions(options).Metrics.SecureServing).To(BeFalse())
+			})
+			It("disables HTTP/2 for the webhook server unless the flag is set", func() {
+				newServer := func() *webhook.DefaultServer {
+					return webhook.NewServer(webhook.Options{Port: 9444}).(*webhook.DefaultServer)
+				}
+				options.WebhookServer = newServer()
+				parseArgs(flagSet)
+				server, ok := f.ToManagerOptions(options).WebhookServer.(*webhook.DefaultServer)
+				Expect(ok).To(BeTrue())
+				Expect(server.Options.Port).To(Equal(9444))
+				Expect(server.Options.TLSOpts).To(HaveLen(1))
+				config := &tls.Config{}
+				server.Options.TLSOpts[0](config)
+				Expect(config.NextProtos).To(Equal([]string{"http/1.1"}))
+
+				options.WebhookServer = newServer()
+				parseArgs(flagSet, "--enable-http2")
+				server = f.ToManagerOptions(options).WebhookServer.(*webhook.DefaultServer)
+				Expect(server.Options.TLSOpts).To(BeEmpty())
+			})
+			It("keeps the metrics filter provider unless the flag is set", func() {
+				options.Metrics.FilterProvider = filters.WithAuthenticationAndAuthorization
+				parseArgs(flagSet)
+				Expect(f.ToManagerOptions(options).Metrics.FilterProvider).NotTo(BeNil())
+				parseArgs(flagSet, "--metrics-require-rbac=false")
+				Expect(f.ToManagerOptions(options).Metrics.FilterProvider).To(BeNil())
+			})
+		})
+	})
+
+	Describe("ApplyWatchDefaults", func() {
+		var (
+			f       *flags.Flags
+			flagSet *pflag.FlagSet
+		)
+		BeforeEach(func() {
+			f = &flags.Flags{}
+			flagSet = pflag.NewFlagSet("test", pflag.ExitOnError)
+			f.AddTo(flagSet)
+		})
+
+		It("applies the defaults of flags that are not set", func() {
+			parseArgs(flagSet, "--max-release-history", "5", "--server-side-apply=false")
+			f.ApplyWatchDefaults(config.WatchDefaults{
+				ReconcilePeriod:         &metav1.Duration{Duration: 5 * time.Minute},
+				MaxConcurrentReconciles: ptr.To(3),
+				MaxReleaseHistory:       ptr.To(2),
+				ReleaseStorage:          storage.BackendConfigMap,
+				ServerSideApply:         ptr.To(true),
+			})
+			Expect(f.ReconcilePeriod).To(Equal(5 * time.Minute))
+			Expect(f.MaxConcurrentReconciles).To(Equal(3))
+			Expect(f.MaxReleaseHistory).To(Equal(5))
+			Expect(f.ReleaseStorage).To(Equal("configmap"))
+			Expect(f.ServerSideApply).To(BeFalse())
+			Expect(f.ForceConflicts).To(BeFalse())
+			Expect(f.WatchDefaults()).To(Equal(config.WatchDefaults{
+				ReconcilePeriod:         &metav1.Duration{Duration: 5 * time.Minute},
+				MaxConcurrentReconciles: ptr.To(3),
+				MaxReleaseHistory:       ptr.To(5),
+				ReleaseStorage:          storage.BackendConfigMap,
+				ServerSideApply:         ptr.To(false),
+				ForceConflicts:          ptr.To(false),
+			}))
+		})
 	})
 })
 
@@ -0,0 +1,289 @@
+// Copyright 2026 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package images rewrites the container images of the resources of releases,
+// so that they are pulled from registry mirrors and pinned to digests.
+package images
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/distribution/reference"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/crane"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+const (
+	// KindImageContentSourcePolicy is the kind of the mirror sets of
+	// OpenShift 4.12 and earlier.
+	KindImageContentSourcePolicy = "ImageContentSourcePolicy"
+	// KindImageDigestMirrorSet is the kind of the mirror sets of OpenShift
+	// 4.13 and later.
+	KindImageDigestMirrorSet = "ImageDigestMirrorSet"
+
+	resolveTimeout = 30 * time.Second
+)
+
+// repositoryRegexp matches registry hosts and repositories without tags or
+// digests.
+var repositoryRegexp = regexp.MustCompile("^" + reference.NameRegexp.String() + "$")
+
+// Mirror maps the images of a source registry or repository to mirrors.
+type Mirror struct {
+	// Source is a registry host, such as quay.io, or a repository, such as
+	// docker.io/library/nginx. It matches the images in the source repository
+	// and in the repositories below it.
+	Source string `json:"source"`
+	// Mirrors are the repositories mirroring the source. Images are rewritten
+	// to the first mirror.
+	Mirrors []string `json:"mirrors"`
+}
+
+// mirrorSet is an ImageContentSourcePolicy or an ImageDigestMirrorSet.
+type mirrorSet struct {
+	metav1.TypeMeta `json:",inline"`
+	Spec            struct {
+		RepositoryDigestMirrors []Mirror `json:"repositoryDigestMirrors,omitempty"`
+		ImageDigestMirrors      []Mirror `json:"imageDigestMirrors,omitempty"`
+	} `json:"spec"`
+}
+
+// LoadMirrors loads the mirrors of the ImageContentSourcePolicy and
+// ImageDigestMirrorSet resources of the YAML file at path.
+func LoadMirrors(path string) ([]Mirror, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open image mirrors file: %w", err)
+	}
+	defer f.Close()
+	mirrors, err := LoadMirrorsReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("invalid image mirrors file %s: %w", path, err)
+	}
+	return mirrors, nil
+}
+
+// LoadMirrorsReader loads the mirrors of the ImageContentSourcePolicy and
+// ImageDigestMirrorSet resources read from reader.
+func LoadMirrorsReader(reader io.Reader) ([]Mirror, error) {
+	var mirrors []Mirror
+	decoder := utilyaml.NewYAMLOrJSONDecoder(reader, 4096)
+	for {
+		set := &mirrorSet{}
+		if err := decoder.Decode(set); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		switch set.Kind {
+		case "":
+			// Empty documents are skipped.
+			continue
+		case KindImageContentSourcePolicy:
+			mirrors = append(mirrors, set.Spec.RepositoryDigestMirrors...)
+		case KindImageDigestMirrorSet:
+			mirrors = append(mirrors, set.Spec.ImageDigestMirrors...)
+		default:
+			return nil, fmt.Errorf("unsupported kind %q: must be %q or %q", set.Kind, KindImageContentSourcePolicy,
+				KindImageDigestMirrorSet)
+		}
+	}
+	for _, m := range mirrors {
+		if err := m.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return mirrors, nil
+}
+
+// Validate returns an error if m is invalid.
+func (m Mirror) Validate() error {
+	if !repositoryRegexp.MatchString(m.Source) {
+		return fmt.Errorf("invalid source %q: must be a registry host or a repository", m.Source)
+	}
+	if len(m.Mirrors) == 0 {
+		return fmt.Errorf("source %q has no mirrors", m.Source)
+	}
+	for _, mirror := range m.Mirrors {
+		if !repositoryRegexp.MatchString(mirror) {
+			return fmt.Errorf("invalid mirror %q of source %q: must be a registry host or a repository", mirror, m.Source)
+		}
+	}
+	return nil
+}
+
+// matches returns true if the repository name, such as
+// docker.io/library/nginx, is the source of m or below it.
+func (m Mirror) matches(name string) bool {
+	return name == m.Source || strings.HasPrefix(name, m.Source+"/")
+}
+
+// Rewrite records an image that was rewritten.
+type Rewrite struct {
+	// Source is the image of the rendered manifest.
+	Source string
+	// Image is the image it was rewritten to.
+	Image string
+}
+
+// Pins maps the images of rendered manifests to the images they were
+// rewritten to.
+type Pins map[string]string
+
+// Rewriter rewrites images to their mirrors, and optionally pins them to
+// digests. Digests are resolved each time an image is rewritten, unless the
+// image is pinned by the Pins passed to RewriteObject.
+type Rewriter struct {
+	mirrors []Mirror
+	// resolve returns the digest of the manifest of an image. Images are not
+	// pinned if it is nil.
+	resolve func(ctx context.Context, ref string) (string, error)
+}
+
+// NewRewriter returns a Rewriter rewriting images to mirrors. If pinDigests
+// is true, the tags of images are resolved to digests in their mirror, or
+// their registry if they have no mirror, with the credentials of the Docker
+// config file of the operator.
+func NewRewriter(mirrors []Mirror, pinDigests bool) *Rewriter {
+	r := &Rewriter{mirrors: mirrors}
+	if pinDigests {
+		r.resolve = resolveDigest
+	}
+	return r
+}
+
+func resolveDigest(ctx context.Context, ref string) (string, error) {
+	return crane.Digest(ref, crane.WithContext(ctx), crane.WithAuthFromKeychain(authn.DefaultKeychain))
+}
+
+// Rewrite returns the image rewritten to its mirror, and pinned to a digest.
+// Images that need no rewrite are returned as is. Image names without a
+// registry are normalized, so that nginx matches the docker.io/library/nginx
+// source.
+func (r *Rewriter) Rewrite(ctx context.Context, image string) (string, error) {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return "", fmt.Errorf("invalid image %q: %w", image, err)
+	}
+
+	name := named.Name()
+	source := ""
+	for _, m := range r.mirrors {
+		if m.matches(named.Name()) && len(m.Source) > len(source) {
+			source = m.Source
+			name = m.Mirrors[0] + strings.TrimPrefix(named.Name(), m.Source)
+		}
+	}
+	digested, isDigested := named.(reference.Digested)
+	if isDigested {
+		if source == "" {
+			return image, nil
+		}
+		return name + "@" + digested.Digest().String(), nil
+	}
+
+	tag := "latest"
+	if tagged, ok := named.(reference.Tagged); ok {
+		tag = tagged.Tag()
+	}
+	ref := name + ":" + tag
+	if r.resolve == nil {
+		if source == "" {
+			return image, nil
+		}
+		return ref, nil
+	}
+	digest, err := r.digest(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve digest of image %s: %w", ref, err)
+	}
+	return name + "@" + digest, nil
+}
+
+func (r *Rewriter) digest(ctx context.Context, ref string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, resolveTimeout)
+	defer cancel()
+	return r.resolve(ctx, ref)
+}
+
+// podSpecPaths returns the paths of the pod specs of the resources of kind.
+func podSpecPaths(kind string) [][]string {
+	switch kind {
+	case "Pod":
+		return [][]string{{"spec"}}
+	case "PodTemplate":
+		return [][]string{{"template", "spec"}}
+	case "Deployment", "ReplicaSet", "StatefulSet", "DaemonSet", "Job", "ReplicationController":
+		return [][]string{{"spec", "template", "spec"}}
+	case "CronJob":
+		return [][]string{{"spec", "jobTemplate", "spec", "template", "spec"}}
+	}
+	return nil
+}
+
+// RewriteObject rewrites the images of the containers of the pod spec of u,
+// if u is a pod or a workload, and returns the images that were rewritten.
+// If r pins digests, the images of pins are rewritten to their pinned image
+// instead of being resolved again.
+func (r *Rewriter) RewriteObject(ctx context.Context, u *unstructured.Unstructured, pins Pins) ([]Rewrite, error) {
+	var rewrites []Rewrite
+	for _, path := range podSpecPaths(u.GetKind()) {
+		field, ok, err := unstructured.NestedFieldNoCopy(u.Object, path...)
+		if err != nil || !ok {
+			continue
+		}
+		podSpec, ok := field.(map[string]any)
+		if !ok {
+			continue
+		}
+		for _, field := range []string{"initContainers", "containers", "ephemeralContainers"} {
+			containers, ok := podSpec[field].([]any)
+			if !ok {
+				continue
+			}
+			for _, c := range containers {
+				container, ok := c.(map[string]any)
+				if !ok {
+					continue
+				}
+				image, ok := container["image"].(string)
+				if !ok || image == "" {
+					continue
+				}
+				rewritten, ok := pins[image]
+				if !ok || r.resolve == nil {
+					if rewritten, err = r.Rewrite(ctx, image); err != nil {
+						return nil, fmt.Errorf("%s %s: %w", u.GetKind(), u.GetName(), err)
+					}
+				}
+				if rewritten != image {
+					container["image"] = rewritten
+					rewrites = append(rewrites, Rewrite{Source: image, Image: rewritten})
+				}
+			}
+		}
+	}
+	return rewrites, nil
+}
@@ -0,0 +1,203 @@
+// Copyright 2026 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package images
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+const digest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+func TestLoadMirrorsReader(t *testing.T) {
+	mirrors, err := LoadMirrorsReader(strings.NewReader(`apiVersion: operator.openshift.io/v1alpha1
+kind: ImageContentSourcePolicy
+metadata:
+  name: library
+spec:
+  repositoryDigestMirrors:
+  - source: docker.io/library
+    mirrors: [mirror.example.com/library]
+---
+---
+apiVersion: config.openshift.io/v1
+kind: ImageDigestMirrorSet
+metadata:
+  name: quay
+spec:
+  imageDigestMirrors:
+  - source: quay.io
+    mirrors: [mirror.example.com/quay, backup.example.com/quay]
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []Mirror{
+		{Source: "docker.io/library", Mirrors: []string{"mirror.example.com/library"}},
+		{Source: "quay.io", Mirrors: []string{"mirror.example.com/quay", "backup.example.com/quay"}},
+	}, mirrors)
+
+	for _, tc := range []struct {
+		name string
+		data string
+		err  string
+	}{
+		{
+			name: "unsupported kind",
+			data: "apiVersion: config.openshift.io/v1\nkind: ImageTagMirrorSet\n",
+			err:  `unsupported kind "ImageTagMirrorSet"`,
+		},
+		{
+			name: "source with tag",
+			data: "kind: ImageDigestMirrorSet\nspec:\n  imageDigestMirrors:\n  - source: quay.io/app:1.0\n    mirrors: [mirror.example.com/app]\n",
+			err:  `invalid source "quay.io/app:1.0"`,
+		},
+		{
+			name: "no mirrors",
+			data: "kind: ImageDigestMirrorSet\nspec:\n  imageDigestMirrors:\n  - source: quay.io\n",
+			err:  `source "quay.io" has no mirrors`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := LoadMirrorsReader(strings.NewReader(tc.data))
+			assert.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestRewrite(t *testing.T) {
+	r := NewRewriter([]Mirror{
+		{Source: "docker.io/library", Mirrors: []string{"mirror.example.com/library"}},
+		{Source: "quay.io", Mirrors: []string{"mirror.example.com/quay"}},
+		{Source: "quay.io/team/app", Mirrors: []string{"mirror.example.com/app"}},
+	}, false)
+	for image, expected := range map[string]string{
+		"nginx":                             "mirror.example.com/library/nginx:latest",
+		"nginx:1.27":                        "mirror.example.com/library/nginx:1.27",
+		"docker.io/library/nginx@" + digest: "mirror.example.com/library/nginx@" + digest,
+		"quay.io/team/tool:v1":              "mirror.example.com/quay/team/tool:v1",
+		"quay.io/team/app:v1":               "mirror.example.com/app:v1",
+		"quay.io/team/application:v1":       "mirror.example.com/quay/team/application:v1",
+		"ghcr.io/team/app:v1":               "ghcr.io/team/app:v1",
+		"busybox/tool":                      "busybox/tool",
+	} {
+		rewritten, err := r.Rewrite(context.TODO(), image)
+		require.NoError(t, err)
+		assert.Equal(t, expected, rewritten, image)
+	}
+
+	_, err := r.Rewrite(context.TODO(), "Invalid/Image")
+	assert.ErrorContains(t, err, `invalid image "Invalid/Image"`)
+}
+
+func TestRewritePinDigests(t *testing.T) {
+	srv := httptest.NewServer(registry.New())
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "http://")
+	img, err := random.Image(64, 1)
+	require.NoError(t, err)
+	require.NoError(t, crane.Push(img, host+"/library/nginx:1.27"))
+	imgDigest, err := img.Digest()
+	require.NoError(t, err)
+
+	r := NewRewriter([]Mirror{{Source: "docker.io/library", Mirrors: []string{host + "/library"}}}, true)
+	rewritten, err := r.Rewrite(context.TODO(), "nginx:1.27")
+	require.NoError(t, err)
+	assert.Equal(t, host+"/library/nginx@"+imgDigest.String(), rewritten)
+
+	// Moved tags are resolved again, unless their image is pinned.
+	other, err := random.Image(64, 1)
+	require.NoError(t, err)
+	require.NoError(t, crane.Push(other, host+"/library/nginx:1.27"))
+	otherDigest, err := other.Digest()
+	require.NoError(t, err)
+	rewritten, err = r.Rewrite(context.TODO(), "nginx:1.27")
+	require.NoError(t, err)
+	assert.Equal(t, host+"/library/nginx@"+otherDigest.String(), rewritten)
+	pod := &unstructured.Unstructured{Object: map[string]any{
+		"kind": "Pod",
+		"spec": map[string]any{"containers": []any{map[string]any{"image": "nginx:1.27"}}},
+	}}
+	rewrites, err := r.RewriteObject(context.TODO(), pod, Pins{"nginx:1.27": host + "/library/nginx@" + imgDigest.String()})
+	require.NoError(t, err)
+	assert.Equal(t, []Rewrite{{Source: "nginx:1.27", Image: host + "/library/nginx@" + imgDigest.String()}}, rewrites)
+
+	// Images with a digest are not resolved.
+	rewritten, err = r.Rewrite(context.TODO(), "nginx@"+digest)
+	require.NoError(t, err)
+	assert.Equal(t, host+"/library/nginx@"+digest, rewritten)
+
+	_, err = r.Rewrite(context.TODO(), "nginx:missing")
+	assert.ErrorContains(t, err, "failed to resolve digest of image "+host+"/library/nginx:missing")
+}
+
+func TestRewriteObject(t *testing.T) {
+	r := NewRewriter([]Mirror{{Source: "docker.io/library", Mirrors: []string{"mirror.example.com/library"}}}, false)
+
+	u := &unstructured.Unstructured{}
+	require.NoError(t, yaml.Unmarshal([]byte(`apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: backup
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          initContainers:
+          - name: init
+            image: busybox:1.36
+          containers:
+          - name: backup
+            image: ghcr.io/team/backup:v1
+          - name: proxy
+            image: nginx:1.27
+`), &u.Object))
+	rewrites, err := r.RewriteObject(context.TODO(), u, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []Rewrite{
+		{Source: "busybox:1.36", Image: "mirror.example.com/library/busybox:1.36"},
+		{Source: "nginx:1.27", Image: "mirror.example.com/library/nginx:1.27"},
+	}, rewrites)
+	// Pins are ignored when digests are not pinned.
+	pod := &unstructured.Unstructured{Object: map[string]any{
+		"kind": "Pod",
+		"spec": map[string]any{"containers": []any{map[string]any{"image": "busybox:1.36"}}},
+	}}
+	rewrites, err = r.RewriteObject(context.TODO(), pod, Pins{"busybox:1.36": "busybox@sha256:0"})
+	require.NoError(t, err)
+	assert.Equal(t, []Rewrite{{Source: "busybox:1.36", Image: "mirror.example.com/library/busybox:1.36"}}, rewrites)
+	containers, _, err := unstructured.NestedSlice(u.Object, "spec", "jobTemplate", "spec", "template", "spec", "containers")
+	require.NoError(t, err)
+	assert.Equal(t, "ghcr.io/team/backup:v1", containers[0].(map[string]any)["image"])
+	assert.Equal(t, "mirror.example.com/library/nginx:1.27", containers[1].(map[string]any)["image"])
+
+	// Images of other resources are not rewritten.
+	cm := &unstructured.Unstructured{Object: map[string]any{
+		"kind": "ConfigMap",
+		"spec": map[string]any{"containers": []any{map[string]any{"image": "nginx"}}},
+	}}
+	rewrites, err = r.RewriteObject(context.TODO(), cm, nil)
+	require.NoError(t, err)
+	assert.Empty(t, rewrites)
+}
@@ -16,45 +16,244 @@ package diff
 
 import (
 	"bytes"
-	"regexp"
+	"fmt"
+	"sort"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/sergi/go-diff/diffmatchpatch"
+	"helm.sh/helm/v3/pkg/releaseutil"
+	"sigs.k8s.io/yaml"
 )
 
-// Generate generates a diff between a and b, in color.
-func Generate(a, b string) string {
-	dmp := diffmatchpatch.New()
+// Action is the change of a resource between two manifests.
+type Action string
+
+const (
+	ActionAdded   Action = "added"
+	ActionRemoved Action = "removed"
+	ActionChanged Action = "changed"
+)
+
+// redacted replaces the diff of resources whose contents must not be logged.
+const redacted = "<redacted>"
+
+// ResourceDiff is the change of a single resource between two manifests.
+type ResourceDiff struct {
+	Action     Action `json:"action"`
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+	// Diff contains the added and removed lines of a changed resource. It is
+	// redacted for Secrets.
+	Diff string `json:"diff,omitempty"`
+}
+
+// Summary summarizes the changes between two release manifests, resource by
+// resource.
+type Summary struct {
+	Added     int            `json:"added"`
+	Removed   int            `json:"removed"`
+	Changed   int            `json:"changed"`
+	Resources []ResourceDiff `json:"resources,omitempty"`
+}
+
+type manifestResource struct {
+	ResourceDiff
+	content string
+}
+
+// Summarize returns the changes between the release manifests a and b.
+// Resources are sorted by kind, namespace and name.
+func Summarize(a, b string) Summary {
+	before, after := parseManifest(a), parseManifest(b)
+
+	var s Summary
+	for key, r := range after {
+		old, ok := before[key]
+		switch {
+		case !ok:
+			r.Action = ActionAdded
+			s.Added++
+		case old.content != r.content:
+			r.Action = ActionChanged
+			r.Diff = lines(old.content, r.content)
+			if r.Kind == "Secret" {
+				r.Diff = redacted
+			}
+			s.Changed++
+		default:
+			continue
+		}
+		s.Resources = append(s.Resources, r.ResourceDiff)
+	}
+	for key, r := range before {
+		if _, ok := after[key]; !ok {
+			r.Action = ActionRemoved
+			s.Removed++
+			s.Resources = append(s.Resources, r.ResourceDiff)
+		}
+	}
+	sort.Slice(s.Resources, func(i, j int) bool {
+		ri, rj := s.Resources[i], s.Resources[j]
+		if ri.Kind != rj.Kind {
+			return ri.Kind < rj.Kind
+		}
+		if ri.Namespace != rj.Namespace {
+			return ri.Namespace < rj.Namespace
+		}
+		return ri.Name < rj.Name
+	})
+	return s
+}
+
+// IsEmpty returns true if no resource changed.
+func (s Summary) IsEmpty() bool {
+	return len(s.Resources) == 0
+}
+
+// WithoutDiffs returns s without the diffs of changed resources.
+func (s Summary) WithoutDiffs() Summary {
+	out := s
+	out.Resources = make([]ResourceDiff, len(s.Resources))
+	for i, r := range s.Resources {
+		r.Diff = ""
+		out.Resources[i] = r
+	}
+	return out
+}
+
+// String returns a single-line description of the changed resources, e.g.
+// "1 added, 1 changed, 0 removed: +ConfigMap default/a, ~Deployment default/b".
+func (s Summary) String() string {
+	return s.Message(0)
+}
+
+// Message returns the description of the changed resources returned by
+// String, truncated to at most maxLength bytes if maxLength is positive.
+// Resources that do not fit are counted instead of listed, and only the
+// counts are returned if no resource fits.
+func (s Summary) Message(maxLength int) string {
+	counts := fmt.Sprintf("%d added, %d changed, %d removed", s.Added, s.Changed, s.Removed)
+	if s.IsEmpty() {
+		return counts
+	}
+	msg := counts + ": "
+	for i, r := range s.Resources {
+		item := r.String()
+		if i > 0 {
+			item = ", " + item
+		}
+		if maxLength > 0 {
+			// Leave room to count the resources after this one, in case they
+			// do not fit.
+			rest := ""
+			if i < len(s.Resources)-1 {
+				rest = fmt.Sprintf(" and %d more", len(s.Resources)-i-1)
+			}
+			if len(msg)+len(item)+len(rest) > maxLength {
+				if i == 0 {
+					return truncate(counts, maxLength)
+				}
+				return truncate(fmt.Sprintf("%s and %d more", msg, len(s.Resources)-i), maxLength)
+			}
+		}
+		msg += item
+	}
+	return msg
+}
+
+// truncate truncates s to at most maxLength bytes, without splitting a
+// multi-byte character.
+func truncate(s string, maxLength int) string {
+	if len(s) <= maxLength {
+		return s
+	}
+	for maxLength > 0 && !utf8.RuneStart(s[maxLength]) {
+		maxLength--
+	}
+	return s[:maxLength]
+}
 
-	wSrc, wDst, warray := dmp.DiffLinesToRunes(a, b)
+// String returns the action and identity of r, e.g. "~Deployment default/b".
+func (r ResourceDiff) String() string {
+	prefix := map[Action]string{ActionAdded: "+", ActionRemoved: "-", ActionChanged: "~"}[r.Action]
+	name := r.Name
+	if r.Namespace != "" {
+		name = r.Namespace + "/" + r.Name
+	}
+	return prefix + r.Kind + " " + name
+}
+
+// parseManifest returns the resources in manifest by API version, kind,
+// namespace and name. Documents that are not resources are ignored.
+func parseManifest(manifest string) map[string]manifestResource {
+	resources := map[string]manifestResource{}
+	for _, doc := range releaseutil.SplitManifests(manifest) {
+		var head releaseutil.SimpleHead
+		if err := yaml.Unmarshal([]byte(doc), &head); err != nil || head.Kind == "" || head.Metadata == nil {
+			continue
+		}
+		var meta struct {
+			Metadata struct {
+				Namespace string `json:"namespace"`
+			} `json:"metadata"`
+		}
+		_ = yaml.Unmarshal([]byte(doc), &meta)
+		r := manifestResource{
+			ResourceDiff: ResourceDiff{
+				APIVersion: head.Version,
+				Kind:       head.Kind,
+				Namespace:  meta.Metadata.Namespace,
+				Name:       head.Metadata.Name,
+			},
+			content: strings.TrimSpace(withoutComments(doc)),
+		}
+		key := strings.Join([]string{r.APIVersion, r.Kind, r.Namespace, r.Name}, "/")
+		resources[key] = r
+	}
+	return resources
+}
+
+// withoutComments removes the comment lines, such as the "# Source:" lines
+// added by Helm, from a manifest document.
+func withoutComments(doc string) string {
+	var buf bytes.Buffer
+	for _, line := range strings.SplitAfter(doc, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		buf.WriteString(line)
+	}
+	return buf.String()
+}
+
+// lines returns the lines added to and removed from a in b, prefixed with
+// "+" and "-".
+func lines(a, b string) string {
+	dmp := diffmatchpatch.New()
+	wSrc, wDst, warray := dmp.DiffLinesToRunes(a+"\n", b+"\n")
 	diffs := dmp.DiffMainRunes(wSrc, wDst, false)
 	diffs = dmp.DiffCharsToLines(diffs, warray)
-	var buff bytes.Buffer
-	for _, diff := range diffs {
-		text := diff.Text
-
-		switch diff.Type {
+	var buf bytes.Buffer
+	for _, d := range diffs {
+		switch d.Type {
 		case diffmatchpatch.DiffInsert:
-			_, _ = buff.WriteString("\x1b[32m")
-			_, _ = buff.WriteString(prefixLines(text, "+"))
-			_, _ = buff.WriteString("\x1b[0m")
+			buf.WriteString(prefixLines(d.Text, "+"))
 		case diffmatchpatch.DiffDelete:
-			_, _ = buff.WriteString("\x1b[31m")
-			_, _ = buff.WriteString(prefixLines(text, "-"))
-			_, _ = buff.WriteString("\x1b[0m")
-		case diffmatchpatch.DiffEqual:
-			_, _ = buff.WriteString(prefixLines(text, " "))
+			buf.WriteString(prefixLines(d.Text, "-"))
 		}
 	}
-	return buff.String()
+	return strings.TrimSuffix(buf.String(), "\n")
 }
 
 func prefixLines(s, prefix string) string {
 	var buf bytes.Buffer
 	lines := strings.Split(s, "\n")
-	ls := regexp.MustCompile("^")
 	for _, line := range lines[:len(lines)-1] {
-		buf.WriteString(ls.ReplaceAllString(line, prefix))
+		buf.WriteString(prefix)
+		buf.WriteString(line)
 		buf.WriteString("\n")
 	}
 	return buf.String()
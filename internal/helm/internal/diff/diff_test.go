@@ -0,0 +1,147 @@
+// Copyright 2026 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const previousManifest = `---
+# Source: nginx/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: nginx-config
+data:
+  index.html: hello
+---
+# Source: nginx/templates/secret.yaml
+apiVersion: v1
+kind: Secret
+metadata:
+  name: nginx-secret
+stringData:
+  password: old-password
+---
+# Source: nginx/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: nginx
+spec:
+  ports:
+  - port: 80
+---
+# Source: nginx/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx
+  namespace: default
+spec:
+  replicas: 1
+`
+
+const upgradedManifest = `---
+# Source: nginx/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx
+  namespace: default
+spec:
+  replicas: 2
+---
+# Source: nginx/templates/secret.yaml
+apiVersion: v1
+kind: Secret
+metadata:
+  name: nginx-secret
+stringData:
+  password: new-password
+---
+# Source: nginx/templates/service-renamed.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: nginx
+spec:
+  ports:
+  - port: 80
+---
+# Source: nginx/templates/ingress.yaml
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: nginx
+`
+
+func TestSummarize(t *testing.T) {
+	s := Summarize(previousManifest, upgradedManifest)
+	assert.Equal(t, Summary{
+		Added:   1,
+		Removed: 1,
+		Changed: 2,
+		Resources: []ResourceDiff{
+			{Action: ActionRemoved, APIVersion: "v1", Kind: "ConfigMap", Name: "nginx-config"},
+			{Action: ActionChanged, APIVersion: "apps/v1", Kind: "Deployment", Namespace: "default", Name: "nginx",
+				Diff: "-  replicas: 1\n+  replicas: 2"},
+			{Action: ActionAdded, APIVersion: "networking.k8s.io/v1", Kind: "Ingress", Name: "nginx"},
+			{Action: ActionChanged, APIVersion: "v1", Kind: "Secret", Name: "nginx-secret", Diff: "<redacted>"},
+		},
+	}, s)
+	assert.Equal(t, "1 added, 2 changed, 1 removed: -ConfigMap nginx-config, ~Deployment default/nginx, "+
+		"+Ingress nginx, ~Secret nginx-secret", s.String())
+
+	for _, r := range s.WithoutDiffs().Resources {
+		assert.Empty(t, r.Diff)
+	}
+	// WithoutDiffs does not modify s.
+	assert.NotEmpty(t, s.Resources[1].Diff)
+}
+
+func TestSummarizeInstallAndUninstall(t *testing.T) {
+	installed := Summarize("", previousManifest)
+	assert.Equal(t, 4, installed.Added)
+	assert.Equal(t, "4 added, 0 changed, 0 removed: +ConfigMap nginx-config, +Deployment default/nginx, "+
+		"+Secret nginx-secret, +Service nginx", installed.String())
+
+	uninstalled := Summarize(previousManifest, "")
+	assert.Equal(t, 4, uninstalled.Removed)
+
+	unchanged := Summarize(previousManifest, previousManifest)
+	assert.True(t, unchanged.IsEmpty())
+	assert.Equal(t, "0 added, 0 changed, 0 removed", unchanged.String())
+}
+
+func TestSummaryMessage(t *testing.T) {
+	s := Summarize(previousManifest, upgradedManifest)
+	full := s.String()
+	assert.Equal(t, full, s.Message(len(full)))
+
+	assert.Equal(t, "1 added, 2 changed, 1 removed: -ConfigMap nginx-config, ~Deployment default/nginx and 2 more",
+		s.Message(100))
+	assert.Equal(t, "1 added, 2 changed, 1 removed", s.Message(60))
+	assert.Len(t, s.Message(10), 10)
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "données", truncate("données", 8))
+	assert.Equal(t, "donné", truncate("données", 6))
+	assert.Equal(t, "donn", truncate("données", 5))
+	assert.Equal(t, "", truncate("é", 1))
+}
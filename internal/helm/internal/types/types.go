@@ -15,11 +15,19 @@
 package types
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
 
+	sprig "github.com/go-task/slim-sprig"
+	"helm.sh/helm/v3/pkg/chartutil"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 type HelmAppList struct {
@@ -53,6 +61,104 @@ type HelmAppCondition struct {
 type HelmAppRelease struct {
 	Name     string `json:"name,omitempty"`
 	Manifest string `json:"manifest,omitempty"`
+	// Namespace is the namespace of the release of a cluster-scoped custom
+	// resource. The release of a namespaced custom resource is in its
+	// namespace.
+	Namespace string `json:"namespace,omitempty"`
+
+	// Revision, ManifestDigest and ManifestRef are set instead of Manifest
+	// when the manifest is stored outside of the status. ManifestRef is not
+	// set when the manifest is only stored in a release record of a SQL
+	// backend.
+	Revision       int                 `json:"revision,omitempty"`
+	ManifestDigest string              `json:"manifestDigest,omitempty"`
+	ManifestRef    *HelmAppManifestRef `json:"manifestRef,omitempty"`
+}
+
+// HelmAppManifestRef references the object containing the manifest of a
+// release, in the namespace of the release.
+type HelmAppManifestRef struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	Key  string `json:"key,omitempty"`
+}
+
+// ManifestStorage is where the manifest of the deployed release of a custom
+// resource is stored.
+type ManifestStorage string
+
+const (
+	// ManifestStorageStatus stores the manifest in the status of the custom
+	// resource.
+	ManifestStorageStatus ManifestStorage = "status"
+	// ManifestStorageRelease stores a reference to the Helm release Secret
+	// containing the manifest in the status of the custom resource.
+	ManifestStorageRelease ManifestStorage = "release"
+	// ManifestStorageConfigMap stores the compressed manifest in a ConfigMap
+	// owned by the custom resource, and a reference to it in the status of
+	// the custom resource.
+	ManifestStorageConfigMap ManifestStorage = "configMap"
+)
+
+// HelmAppDriftCorrection records a release resource that was created or
+// patched because it no longer matched the deployed release manifest.
+type HelmAppDriftCorrection struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+	Action     string `json:"action"`
+
+	Timestamp metav1.Time `json:"timestamp"`
+}
+
+// MaxDriftCorrections is the maximum number of drift corrections kept in the
+// status of a custom resource.
+const MaxDriftCorrections = 10
+
+// HelmAppReleaseRevision summarizes a revision of a release kept in the Helm
+// storage backend.
+type HelmAppReleaseRevision struct {
+	Revision     int    `json:"revision"`
+	ChartVersion string `json:"chartVersion,omitempty"`
+	AppVersion   string `json:"appVersion,omitempty"`
+	Status       string `json:"status,omitempty"`
+	Description  string `json:"description,omitempty"`
+
+	Timestamp metav1.Time `json:"timestamp,omitempty"`
+}
+
+// MaxReleaseHistory is the maximum number of release revisions kept in the
+// status of a custom resource.
+const MaxReleaseHistory = 10
+
+// HelmAppSubchart records whether a dependency of the chart of a release is
+// enabled in its deployed revision by its condition and tags.
+type HelmAppSubchart struct {
+	// Name is the name of the subchart in the values of the release, which is
+	// its alias if it has one.
+	Name    string `json:"name"`
+	Chart   string `json:"chart"`
+	Version string `json:"version,omitempty"`
+	Enabled bool   `json:"enabled"`
+}
+
+// HelmAppImage records an image of the deployed revision of a release that
+// was rewritten to a mirror or pinned to a digest when it was applied.
+type HelmAppImage struct {
+	// Source is the image rendered from the chart.
+	Source string `json:"source"`
+	// Image is the image applied to the cluster.
+	Image string `json:"image"`
+}
+
+// HelmAppRollback records a manual rollback of a release. Upgrades of the
+// release are suspended until the generation of the custom resource changes.
+type HelmAppRollback struct {
+	Revision   int   `json:"revision"`
+	Generation int64 `json:"generation"`
+
+	Timestamp metav1.Time `json:"timestamp"`
 }
 
 const (
@@ -60,23 +166,53 @@ const (
 	ConditionDeployed       HelmAppConditionType = "Deployed"
 	ConditionReleaseFailed  HelmAppConditionType = "ReleaseFailed"
 	ConditionIrreconcilable HelmAppConditionType = "Irreconcilable"
+	ConditionApplyConflict  HelmAppConditionType = "ApplyConflict"
+	ConditionDriftDetected  HelmAppConditionType = "DriftDetected"
+	ConditionTested         HelmAppConditionType = "Tested"
+	ConditionReady          HelmAppConditionType = "Ready"
+	ConditionPaused         HelmAppConditionType = "Paused"
 
 	StatusTrue    ConditionStatus = "True"
 	StatusFalse   ConditionStatus = "False"
 	StatusUnknown ConditionStatus = "Unknown"
 
-	ReasonInstallSuccessful   HelmAppConditionReason = "InstallSuccessful"
-	ReasonUpgradeSuccessful   HelmAppConditionReason = "UpgradeSuccessful"
-	ReasonUninstallSuccessful HelmAppConditionReason = "UninstallSuccessful"
-	ReasonInstallError        HelmAppConditionReason = "InstallError"
-	ReasonUpgradeError        HelmAppConditionReason = "UpgradeError"
-	ReasonReconcileError      HelmAppConditionReason = "ReconcileError"
-	ReasonUninstallError      HelmAppConditionReason = "UninstallError"
+	ReasonInstallSuccessful    HelmAppConditionReason = "InstallSuccessful"
+	ReasonUpgradeSuccessful    HelmAppConditionReason = "UpgradeSuccessful"
+	ReasonUninstallSuccessful  HelmAppConditionReason = "UninstallSuccessful"
+	ReasonInstallError         HelmAppConditionReason = "InstallError"
+	ReasonUpgradeError         HelmAppConditionReason = "UpgradeError"
+	ReasonReconcileError       HelmAppConditionReason = "ReconcileError"
+	ReasonUninstallError       HelmAppConditionReason = "UninstallError"
+	ReasonFieldManagerConflict HelmAppConditionReason = "FieldManagerConflict"
+	ReasonDriftCorrected       HelmAppConditionReason = "DriftCorrected"
+	ReasonNoDrift              HelmAppConditionReason = "NoDrift"
+	ReasonTestsPassed          HelmAppConditionReason = "TestsPassed"
+	ReasonTestsFailed          HelmAppConditionReason = "TestsFailed"
+	ReasonTestsPending         HelmAppConditionReason = "TestsPending"
+	ReasonResourcesReady       HelmAppConditionReason = "ResourcesReady"
+	ReasonResourcesNotReady    HelmAppConditionReason = "ResourcesNotReady"
+	ReasonReadinessTimeout     HelmAppConditionReason = "ReadinessTimeout"
+	ReasonReadinessCheckError  HelmAppConditionReason = "ReadinessCheckError"
+	ReasonRollbackSuccessful   HelmAppConditionReason = "RollbackSuccessful"
+	ReasonRollbackError        HelmAppConditionReason = "RollbackError"
+	ReasonPausedByAnnotation   HelmAppConditionReason = "PausedByAnnotation"
+	ReasonUpgradeDeferred      HelmAppConditionReason = "UpgradeDeferred"
+	ReasonDriftDeferred        HelmAppConditionReason = "DriftDeferred"
+	ReasonAdoptError           HelmAppConditionReason = "AdoptError"
+	ReasonReleaseNameCollision HelmAppConditionReason = "ReleaseNameCollision"
 )
 
 type HelmAppStatus struct {
-	Conditions      []HelmAppCondition `json:"conditions"`
-	DeployedRelease *HelmAppRelease    `json:"deployedRelease,omitempty"`
+	Conditions       []HelmAppCondition       `json:"conditions"`
+	DeployedRelease  *HelmAppRelease          `json:"deployedRelease,omitempty"`
+	DriftCorrections []HelmAppDriftCorrection `json:"driftCorrections,omitempty"`
+	History          []HelmAppReleaseRevision `json:"history,omitempty"`
+	Subcharts        []HelmAppSubchart        `json:"subcharts,omitempty"`
+	Images           []HelmAppImage           `json:"images,omitempty"`
+	Rollback         *HelmAppRollback         `json:"rollback,omitempty"`
+	// NextMaintenanceWindow is the start of the maintenance window to which
+	// a pending upgrade or drift corrections of the release are deferred.
+	NextMaintenanceWindow *metav1.Time `json:"nextMaintenanceWindow,omitempty"`
 }
 
 func (s *HelmAppStatus) ToMap() (map[string]any, error) {
@@ -129,6 +265,60 @@ func (s *HelmAppStatus) RemoveCondition(conditionType HelmAppConditionType) *Hel
 	return s
 }
 
+// IsConditionTrue returns true if the status object has a condition of the
+// passed condition type with status True.
+func (s *HelmAppStatus) IsConditionTrue(conditionType HelmAppConditionType) bool {
+	for _, c := range s.Conditions {
+		if c.Type == conditionType {
+			return c.Status == StatusTrue
+		}
+	}
+	return false
+}
+
+// GetCondition returns the condition of the passed condition type, or nil if
+// the status object has none.
+func (s *HelmAppStatus) GetCondition(conditionType HelmAppConditionType) *HelmAppCondition {
+	for i := range s.Conditions {
+		if s.Conditions[i].Type == conditionType {
+			return &s.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// AddDriftCorrections records drift corrections on the status object, most
+// recent first. Only the last MaxDriftCorrections corrections are kept.
+// AddDriftCorrections does not update the resource in the cluster.
+func (s *HelmAppStatus) AddDriftCorrections(corrections ...HelmAppDriftCorrection) *HelmAppStatus {
+	out := make([]HelmAppDriftCorrection, 0, len(corrections)+len(s.DriftCorrections))
+	for i := len(corrections) - 1; i >= 0; i-- {
+		out = append(out, corrections[i])
+	}
+	out = append(out, s.DriftCorrections...)
+	if len(out) > MaxDriftCorrections {
+		out = out[:MaxDriftCorrections]
+	}
+	s.DriftCorrections = out
+	return s
+}
+
+// SetHistory records the revisions of a release on the status object, most
+// recent first. Only the last MaxReleaseHistory revisions are kept.
+// SetHistory does not update the resource in the cluster.
+func (s *HelmAppStatus) SetHistory(revisions ...HelmAppReleaseRevision) *HelmAppStatus {
+	out := make([]HelmAppReleaseRevision, len(revisions))
+	copy(out, revisions)
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Revision > out[j].Revision
+	})
+	if len(out) > MaxReleaseHistory {
+		out = out[:MaxReleaseHistory]
+	}
+	s.History = out
+	return s
+}
+
 // StatusFor safely returns a typed status block from a custom resource.
 func StatusFor(cr *unstructured.Unstructured) *HelmAppStatus {
 	switch s := cr.Object["status"].(type) {
@@ -144,3 +334,138 @@ func StatusFor(cr *unstructured.Unstructured) *HelmAppStatus {
 		return &HelmAppStatus{}
 	}
 }
+
+// ReleaseNaming configures the names and namespaces of the releases of custom
+// resources. By default, the release of a custom resource has its name and is
+// in its namespace. The release namespace is the namespace in which release
+// resources are installed by default and release records are stored.
+type ReleaseNaming struct {
+	// Name is a template of the release name.
+	Name *template.Template
+	// Namespace is a template of the release namespace, used if FieldPath is
+	// not set in the custom resource.
+	Namespace *template.Template
+	// FieldPath is a dot-separated path to a string field of the custom
+	// resource containing the release namespace, e.g. "spec.targetNamespace".
+	FieldPath string
+}
+
+// ReleaseTemplateData is the data with which the release name and namespace
+// templates are rendered.
+type ReleaseTemplateData struct {
+	Name        string
+	Namespace   string
+	Group       string
+	Version     string
+	Kind        string
+	Labels      map[string]string
+	Annotations map[string]string
+}
+
+// NewReleaseNaming parses the release name and namespace templates. Empty
+// templates are not set.
+func NewReleaseNaming(name, namespace, fieldPath string) (ReleaseNaming, error) {
+	n := ReleaseNaming{FieldPath: fieldPath}
+	var err error
+	if name != "" {
+		if n.Name, err = parseReleaseTemplate("releaseName", name); err != nil {
+			return n, err
+		}
+	}
+	if namespace != "" {
+		if n.Namespace, err = parseReleaseTemplate("releaseNamespace", namespace); err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+func parseReleaseTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(sprig.TxtFuncMap()).Option("missingkey=error").Parse(text)
+}
+
+// IsDefault returns true if releases have the name and namespace of their
+// custom resource.
+func (n ReleaseNaming) IsDefault() bool {
+	return n.Name == nil && n.Namespace == nil && n.FieldPath == ""
+}
+
+// NameFor returns the name of the release of cr. Once installed, a release
+// keeps the name recorded in the status of cr, even if the template changes.
+func (n ReleaseNaming) NameFor(cr *unstructured.Unstructured) (string, error) {
+	if deployed := StatusFor(cr).DeployedRelease; deployed != nil && deployed.Name != "" {
+		return deployed.Name, nil
+	}
+	if n.Name == nil {
+		return cr.GetName(), nil
+	}
+	name, err := renderReleaseTemplate(n.Name, cr)
+	if err != nil {
+		return "", fmt.Errorf("failed to render release name: %w", err)
+	}
+	if err := chartutil.ValidateReleaseName(name); err != nil {
+		return "", fmt.Errorf("invalid release name %q: %w", name, err)
+	}
+	return name, nil
+}
+
+// NamespaceFor returns the namespace of the release of cr: the value of
+// FieldPath in cr if set, then the rendered Namespace template if set, then
+// the namespace of cr. Once installed, a release stays in the namespace
+// recorded in the status of cr, even if the configured namespace changes.
+func (n ReleaseNaming) NamespaceFor(cr *unstructured.Unstructured) (string, error) {
+	if deployed := StatusFor(cr).DeployedRelease; deployed != nil {
+		if deployed.Namespace != "" {
+			return deployed.Namespace, nil
+		}
+		// Releases installed in the namespace of their custom resource do not
+		// record their namespace.
+		if cr.GetNamespace() != "" {
+			return cr.GetNamespace(), nil
+		}
+	}
+
+	namespace := ""
+	if n.FieldPath != "" {
+		ns, _, err := unstructured.NestedString(cr.Object, strings.Split(n.FieldPath, ".")...)
+		if err != nil {
+			return "", fmt.Errorf("failed to get release namespace from %s: %w", n.FieldPath, err)
+		}
+		namespace = ns
+	}
+	if namespace == "" && n.Namespace != nil {
+		ns, err := renderReleaseTemplate(n.Namespace, cr)
+		if err != nil {
+			return "", fmt.Errorf("failed to render release namespace: %w", err)
+		}
+		namespace = ns
+	}
+	if namespace == "" {
+		namespace = cr.GetNamespace()
+	}
+	if namespace == "" {
+		return "", fmt.Errorf("release namespace of cluster-scoped %s %s is not set", cr.GetKind(), cr.GetName())
+	}
+	if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+		return "", fmt.Errorf("invalid release namespace %q: %s", namespace, strings.Join(errs, ", "))
+	}
+	return namespace, nil
+}
+
+func renderReleaseTemplate(t *template.Template, cr *unstructured.Unstructured) (string, error) {
+	gvk := cr.GroupVersionKind()
+	data := ReleaseTemplateData{
+		Name:        cr.GetName(),
+		Namespace:   cr.GetNamespace(),
+		Group:       gvk.Group,
+		Version:     gvk.Version,
+		Kind:        gvk.Kind,
+		Labels:      cr.GetLabels(),
+		Annotations: cr.GetAnnotations(),
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
@@ -18,6 +18,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
@@ -59,6 +60,57 @@ func TestRemoveCondition(t *testing.T) {
 	assert.Empty(t, actual.Conditions)
 }
 
+func TestIsConditionTrue(t *testing.T) {
+	status := newTestStatus()
+	assert.True(t, status.IsConditionTrue(ConditionDeployed))
+	assert.False(t, status.IsConditionTrue(ConditionReleaseFailed))
+
+	status.SetCondition(HelmAppCondition{Type: ConditionDeployed, Status: StatusFalse})
+	assert.False(t, status.IsConditionTrue(ConditionDeployed))
+}
+
+func TestAddDriftCorrections(t *testing.T) {
+	status := newTestStatus()
+	for i := 0; i < MaxDriftCorrections; i++ {
+		status.AddDriftCorrections(HelmAppDriftCorrection{Kind: "ConfigMap", Name: "old", Action: "Patched"})
+	}
+	status.AddDriftCorrections(
+		HelmAppDriftCorrection{Kind: "Deployment", Name: "first", Action: "Patched"},
+		HelmAppDriftCorrection{Kind: "Service", Name: "second", Action: "Created"},
+	)
+
+	assert.Len(t, status.DriftCorrections, MaxDriftCorrections)
+	assert.Equal(t, "second", status.DriftCorrections[0].Name)
+	assert.Equal(t, "first", status.DriftCorrections[1].Name)
+	assert.Equal(t, "old", status.DriftCorrections[2].Name)
+
+	newStatus, err := status.ToMap()
+	assert.NoError(t, err)
+	resource := newTestResource()
+	resource.Object["status"] = newStatus
+	assert.Equal(t, status.DriftCorrections, StatusFor(resource).DriftCorrections)
+}
+
+func TestSetHistory(t *testing.T) {
+	status := newTestStatus()
+	revisions := make([]HelmAppReleaseRevision, 0, MaxReleaseHistory+2)
+	for i := 1; i <= MaxReleaseHistory+2; i++ {
+		revisions = append(revisions, HelmAppReleaseRevision{Revision: i, Status: "superseded"})
+	}
+	status.SetHistory(revisions...)
+
+	assert.Len(t, status.History, MaxReleaseHistory)
+	assert.Equal(t, MaxReleaseHistory+2, status.History[0].Revision)
+	assert.Equal(t, 3, status.History[MaxReleaseHistory-1].Revision)
+	assert.Equal(t, 1, revisions[0].Revision)
+
+	newStatus, err := status.ToMap()
+	assert.NoError(t, err)
+	resource := newTestResource()
+	resource.Object["status"] = newStatus
+	assert.Equal(t, status.History, StatusFor(resource).History)
+}
+
 func TestStatusForEmpty(t *testing.T) {
 	status := StatusFor(newTestResource())
 
@@ -133,3 +185,101 @@ func newTestStatusRaw() map[string]any {
 		"deployedRelease": map[string]any{"name": "SomeRelease"},
 	}
 }
+
+func TestReleaseNamingNameFor(t *testing.T) {
+	cr := &unstructured.Unstructured{Object: map[string]any{"spec": map[string]any{}}}
+	cr.SetAPIVersion("example.com/v1alpha1")
+	cr.SetKind("Nginx")
+	cr.SetNamespace(testNamespaceName)
+	cr.SetName("test")
+	cr.SetLabels(map[string]string{"team": "web"})
+
+	name, err := ReleaseNaming{}.NameFor(cr)
+	assert.NoError(t, err)
+	assert.Equal(t, "test", name)
+
+	n, err := NewReleaseNaming(`{{ .Name }}-{{ .Kind | lower }}-{{ .Labels.team }}`, "", "")
+	require.NoError(t, err)
+	name, err = n.NameFor(cr)
+	assert.NoError(t, err)
+	assert.Equal(t, "test-nginx-web", name)
+
+	cr.SetLabels(nil)
+	_, err = n.NameFor(cr)
+	assert.ErrorContains(t, err, "failed to render release name")
+
+	n, err = NewReleaseNaming(`{{ .Name }}_{{ .Kind }}`, "", "")
+	require.NoError(t, err)
+	_, err = n.NameFor(cr)
+	assert.ErrorContains(t, err, `invalid release name "test_Nginx"`)
+
+	// The name of an installed release does not change.
+	cr.Object["status"] = map[string]any{"deployedRelease": map[string]any{"name": "installed"}}
+	name, err = n.NameFor(cr)
+	assert.NoError(t, err)
+	assert.Equal(t, "installed", name)
+
+	_, err = NewReleaseNaming(`{{ .Name `, "", "")
+	assert.Error(t, err)
+}
+
+func TestReleaseNamingNamespaceFor(t *testing.T) {
+	n, err := NewReleaseNaming("", "{{ .Namespace }}-releases", "spec.targetNamespace")
+	require.NoError(t, err)
+	assert.False(t, n.IsDefault())
+	assert.True(t, ReleaseNaming{}.IsDefault())
+
+	namespaced := &unstructured.Unstructured{Object: map[string]any{"spec": map[string]any{}}}
+	namespaced.SetNamespace(testNamespaceName)
+	ns, err := ReleaseNaming{}.NamespaceFor(namespaced)
+	assert.NoError(t, err)
+	assert.Equal(t, testNamespaceName, ns)
+	ns, err = n.NamespaceFor(namespaced)
+	assert.NoError(t, err)
+	assert.Equal(t, testNamespaceName+"-releases", ns)
+
+	namespaced.Object["spec"] = map[string]any{"targetNamespace": "other"}
+	ns, err = n.NamespaceFor(namespaced)
+	assert.NoError(t, err)
+	assert.Equal(t, "other", ns)
+
+	// Releases installed before the namespace was configured stay in the
+	// namespace of their custom resource.
+	namespaced.Object["status"] = map[string]any{"deployedRelease": map[string]any{"name": "test"}}
+	ns, err = n.NamespaceFor(namespaced)
+	assert.NoError(t, err)
+	assert.Equal(t, testNamespaceName, ns)
+
+	cr := &unstructured.Unstructured{Object: map[string]any{"spec": map[string]any{}}}
+	cr.SetKind("Nginx")
+	cr.SetName("test")
+	n, err = NewReleaseNaming("", "default-target", "spec.targetNamespace")
+	require.NoError(t, err)
+	ns, err = n.NamespaceFor(cr)
+	assert.NoError(t, err)
+	assert.Equal(t, "default-target", ns)
+
+	cr.Object["spec"] = map[string]any{"targetNamespace": "target"}
+	ns, err = n.NamespaceFor(cr)
+	assert.NoError(t, err)
+	assert.Equal(t, "target", ns)
+
+	// The namespace of an installed release does not change.
+	cr.Object["status"] = map[string]any{"deployedRelease": map[string]any{"name": "test", "namespace": "installed"}}
+	ns, err = n.NamespaceFor(cr)
+	assert.NoError(t, err)
+	assert.Equal(t, "installed", ns)
+
+	cr.Object["spec"] = map[string]any{"targetNamespace": 1}
+	delete(cr.Object, "status")
+	_, err = n.NamespaceFor(cr)
+	assert.ErrorContains(t, err, "failed to get release namespace from spec.targetNamespace")
+
+	cr.Object["spec"] = map[string]any{"targetNamespace": "Not_Valid"}
+	_, err = n.NamespaceFor(cr)
+	assert.ErrorContains(t, err, `invalid release namespace "Not_Valid"`)
+
+	cr.Object["spec"] = map[string]any{}
+	_, err = ReleaseNaming{}.NamespaceFor(cr)
+	assert.EqualError(t, err, "release namespace of cluster-scoped Nginx test is not set")
+}
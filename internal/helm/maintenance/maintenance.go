@@ -0,0 +1,106 @@
+// Copyright 2026 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package maintenance implements maintenance windows, recurring periods of
+// time during which releases may be upgraded.
+package maintenance
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Window configures a maintenance window. A window starts at each time
+// matching Schedule and lasts for Duration.
+type Window struct {
+	// Schedule is a cron expression with five fields (minute, hour, day of
+	// month, month and day of week), or one of the descriptors @yearly,
+	// @monthly, @weekly, @daily and @hourly.
+	Schedule string `json:"schedule"`
+	// Duration is how long each window lasts.
+	Duration metav1.Duration `json:"duration"`
+	// TimeZone is the IANA time zone in which Schedule is evaluated. It
+	// defaults to UTC.
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// Validate returns an error if w cannot be parsed.
+func (w Window) Validate() error {
+	_, err := w.Parse()
+	return err
+}
+
+// Parse returns the Schedule of w.
+func (w Window) Parse() (*Schedule, error) {
+	if w.Duration.Duration <= 0 {
+		return nil, errors.New("duration must be positive")
+	}
+	loc := time.UTC
+	if w.TimeZone != "" {
+		var err error
+		if loc, err = time.LoadLocation(w.TimeZone); err != nil {
+			return nil, fmt.Errorf("invalid time zone %q: %w", w.TimeZone, err)
+		}
+	}
+	spec, err := parseCron(w.Schedule)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: %w", w.Schedule, err)
+	}
+	spec.Location = loc
+	return &Schedule{spec: spec, duration: w.Duration.Duration}, nil
+}
+
+// parseCron parses a standard cron expression. Descriptors that do not
+// start windows at fixed times, such as @every, and time zone prefixes, which
+// are set with the TimeZone of windows instead, are rejected.
+func parseCron(expr string) (*cron.SpecSchedule, error) {
+	expr = strings.TrimSpace(expr)
+	if strings.HasPrefix(expr, "TZ=") || strings.HasPrefix(expr, "CRON_TZ=") {
+		return nil, errors.New("time zones must be set with timeZone")
+	}
+	s, err := cron.ParseStandard(expr)
+	if err != nil {
+		return nil, err
+	}
+	spec, ok := s.(*cron.SpecSchedule)
+	if !ok {
+		return nil, errors.New("descriptor must be one of @yearly, @annually, @monthly, @weekly, @daily, @midnight or @hourly")
+	}
+	return spec, nil
+}
+
+// Schedule computes the maintenance windows of a Window.
+type Schedule struct {
+	spec     *cron.SpecSchedule
+	duration time.Duration
+}
+
+// Contains returns true if t is within a maintenance window.
+func (s *Schedule) Contains(t time.Time) bool {
+	// The window containing t, if any, starts after t-duration.
+	start, ok := s.Next(t.Add(-s.duration))
+	return ok && !start.After(t)
+}
+
+// Next returns the start of the first window strictly after t, and false if
+// the schedule does not match any time in the next five years.
+func (s *Schedule) Next(t time.Time) (time.Time, bool) {
+	next := s.spec.Next(t)
+	return next, !next.IsZero()
+}
@@ -0,0 +1,101 @@
+// Copyright 2026 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maintenance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func mustParse(t *testing.T, schedule string, duration time.Duration, timeZone string) *Schedule {
+	s, err := Window{Schedule: schedule, Duration: metav1.Duration{Duration: duration}, TimeZone: timeZone}.Parse()
+	require.NoError(t, err)
+	return s
+}
+
+func date(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestScheduleNext(t *testing.T) {
+	testCases := []struct {
+		schedule string
+		timeZone string
+		from     string
+		expected string
+	}{
+		{schedule: "0 2 * * *", from: "2026-10-17T10:00:00Z", expected: "2026-10-18T02:00:00Z"},
+		{schedule: "0 2 * * *", from: "2026-10-17T01:59:30Z", expected: "2026-10-17T02:00:00Z"},
+		// Next is strictly after the given time.
+		{schedule: "0 2 * * *", from: "2026-10-17T02:00:00Z", expected: "2026-10-18T02:00:00Z"},
+		{schedule: "*/15 * * * *", from: "2026-10-17T10:07:00Z", expected: "2026-10-17T10:15:00Z"},
+		{schedule: "30 22 * * sat,sun", from: "2026-10-14T00:00:00Z", expected: "2026-10-17T22:30:00Z"},
+		{schedule: "0 0 * * 0", from: "2026-10-17T00:00:00Z", expected: "2026-10-18T00:00:00Z"},
+		{schedule: "0 9-17/4 * * mon-fri", from: "2026-10-16T14:00:00Z", expected: "2026-10-16T17:00:00Z"},
+		{schedule: "0 0 1 */3 *", from: "2026-10-17T00:00:00Z", expected: "2027-01-01T00:00:00Z"},
+		{schedule: "@monthly", from: "2026-12-17T00:00:00Z", expected: "2027-01-01T00:00:00Z"},
+		{schedule: "0 0 29 feb *", from: "2026-10-17T00:00:00Z", expected: "2028-02-29T00:00:00Z"},
+		// Either day field matches if both are restricted.
+		{schedule: "0 0 13 * fri", from: "2026-10-17T00:00:00Z", expected: "2026-10-23T00:00:00Z"},
+		{schedule: "0 2 * * *", timeZone: "Europe/Paris", from: "2026-10-17T10:00:00Z", expected: "2026-10-18T00:00:00Z"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.schedule, func(t *testing.T) {
+			s := mustParse(t, tc.schedule, time.Hour, tc.timeZone)
+			next, ok := s.Next(date(tc.from))
+			require.True(t, ok)
+			assert.True(t, date(tc.expected).Equal(next), "expected %s, got %s", tc.expected, next)
+		})
+	}
+
+	s := mustParse(t, "0 0 30 2 *", time.Hour, "")
+	_, ok := s.Next(date("2026-10-17T00:00:00Z"))
+	assert.False(t, ok)
+}
+
+func TestScheduleContains(t *testing.T) {
+	s := mustParse(t, "0 22 * * sat", 4*time.Hour, "")
+	assert.False(t, s.Contains(date("2026-10-17T21:59:00Z")))
+	assert.True(t, s.Contains(date("2026-10-17T22:00:00Z")))
+	assert.True(t, s.Contains(date("2026-10-18T01:59:59Z")))
+	assert.False(t, s.Contains(date("2026-10-18T02:00:00Z")))
+	assert.False(t, s.Contains(date("2026-10-20T23:00:00Z")))
+}
+
+func TestWindowValidate(t *testing.T) {
+	hour := metav1.Duration{Duration: time.Hour}
+	assert.NoError(t, Window{Schedule: "0 2 * * *", Duration: hour}.Validate())
+	assert.NoError(t, Window{Schedule: "@weekly", Duration: hour, TimeZone: "America/New_York"}.Validate())
+
+	assert.ErrorContains(t, Window{Schedule: "0 2 * * *"}.Validate(), "duration must be positive")
+	assert.ErrorContains(t, Window{Schedule: "0 2 * *", Duration: hour}.Validate(), "expected exactly 5 fields")
+	assert.ErrorContains(t, Window{Schedule: "60 2 * * *", Duration: hour}.Validate(), "above maximum")
+	assert.ErrorContains(t, Window{Schedule: "0 5-2 * * *", Duration: hour}.Validate(), "beyond end of range")
+	assert.ErrorContains(t, Window{Schedule: "*/0 2 * * *", Duration: hour}.Validate(), "step of range")
+	assert.ErrorContains(t, Window{Schedule: "0 2 * * someday", Duration: hour}.Validate(), "failed to parse int")
+	assert.ErrorContains(t, Window{Schedule: "@every 1h", Duration: hour}.Validate(), "descriptor must be one of")
+	assert.ErrorContains(t, Window{Schedule: "CRON_TZ=Europe/Paris 0 2 * * *", Duration: hour}.Validate(),
+		"time zones must be set with timeZone")
+	assert.ErrorContains(t, Window{Schedule: "0 2 * * *", Duration: hour, TimeZone: "Mars/Olympus"}.Validate(),
+		"invalid time zone")
+}
@@ -15,7 +15,12 @@
 package metrics
 
 import (
+	"sync"
+	"time"
+
 	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	apitypes "k8s.io/apimachinery/pkg/types"
 
 	sdkVersion "github.com/operator-framework/operator-sdk/internal/version"
 )
@@ -24,6 +29,33 @@ const (
 	subsystem = "helm_operator"
 )
 
+// Operation is a release operation performed by the helm-operator.
+type Operation string
+
+const (
+	OperationInstall        Operation = "install"
+	OperationUpgrade        Operation = "upgrade"
+	OperationRollback       Operation = "rollback"
+	OperationUninstall      Operation = "uninstall"
+	OperationDriftReconcile Operation = "drift_reconcile"
+)
+
+// ReleaseState is the state of the release of a custom resource.
+type ReleaseState string
+
+const (
+	ReleaseStateDeployed     ReleaseState = "deployed"
+	ReleaseStateFailed       ReleaseState = "failed"
+	ReleaseStateUninstalling ReleaseState = "uninstalling"
+)
+
+const (
+	resultSuccess = "success"
+	resultError   = "error"
+)
+
+var gvkLabels = []string{"group", "version", "kind"}
+
 var (
 	buildInfo = prometheus.NewGauge(
 		prometheus.GaugeOpts{
@@ -42,3 +74,146 @@ func RegisterBuildInfo(r prometheus.Registerer) {
 	buildInfo.Set(1)
 	r.MustRegister(buildInfo)
 }
+
+var (
+	releaseOperationDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Subsystem: subsystem,
+			Name:      "release_operation_duration_seconds",
+			Help:      "Duration of release operations in seconds",
+			Buckets:   []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300},
+		},
+		append(gvkLabels, "operation", "result"),
+	)
+	releaseOperations = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: subsystem,
+			Name:      "release_operations_total",
+			Help:      "Total number of release operations",
+		},
+		append(gvkLabels, "operation", "result"),
+	)
+	releases = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: subsystem,
+			Name:      "releases",
+			Help:      "Number of releases by state",
+		},
+		append(gvkLabels, "state"),
+	)
+	driftCorrections = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: subsystem,
+			Name:      "drift_corrections_total",
+			Help:      "Total number of release resources created or patched because they drifted from the deployed release",
+		},
+		append(gvkLabels, "action"),
+	)
+	chartRenderDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Subsystem: subsystem,
+			Name:      "chart_render_duration_seconds",
+			Help:      "Duration of chart renderings to determine whether a release must be upgraded, in seconds",
+			Buckets:   []float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+		},
+		gvkLabels,
+	)
+	dependentWatches = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: subsystem,
+			Name:      "dependent_watches",
+			Help:      "Number of deployed releases containing resources of each watched dependent resource kind",
+		},
+		gvkLabels,
+	)
+)
+
+// RegisterReleaseMetrics registers the metrics of release operations.
+func RegisterReleaseMetrics(r prometheus.Registerer) {
+	r.MustRegister(releaseOperationDuration, releaseOperations, releases, driftCorrections, chartRenderDuration,
+		dependentWatches)
+}
+
+func gvkLabelValues(gvk schema.GroupVersionKind, values ...string) []string {
+	return append([]string{gvk.Group, gvk.Version, gvk.Kind}, values...)
+}
+
+// ObserveReleaseOperation records a release operation on a custom resource of
+// gvk that started at start and failed if err is not nil.
+func ObserveReleaseOperation(gvk schema.GroupVersionKind, op Operation, start time.Time, err error) {
+	result := resultSuccess
+	if err != nil {
+		result = resultError
+	}
+	labels := gvkLabelValues(gvk, string(op), result)
+	releaseOperationDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+	releaseOperations.WithLabelValues(labels...).Inc()
+}
+
+// ObserveChartRender records the rendering of a chart for a custom resource of
+// gvk that started at start.
+func ObserveChartRender(gvk schema.GroupVersionKind, start time.Time) {
+	chartRenderDuration.WithLabelValues(gvkLabelValues(gvk)...).Observe(time.Since(start).Seconds())
+}
+
+// AddDriftCorrection records a release resource of a custom resource of gvk
+// that was corrected with action.
+func AddDriftCorrection(gvk schema.GroupVersionKind, action string) {
+	driftCorrections.WithLabelValues(gvkLabelValues(gvk, action)...).Inc()
+}
+
+// SetDependentWatch records that dependent resources of gvk are watched, and
+// contained in the deployed release of n custom resources.
+func SetDependentWatch(gvk schema.GroupVersionKind, n int) {
+	dependentWatches.WithLabelValues(gvkLabelValues(gvk)...).Set(float64(n))
+}
+
+// DeleteDependentWatch records that dependent resources of gvk are no longer
+// watched.
+func DeleteDependentWatch(gvk schema.GroupVersionKind) {
+	dependentWatches.DeleteLabelValues(gvkLabelValues(gvk)...)
+}
+
+var releaseStates = &releaseStateTracker{states: map[schema.GroupVersionKind]map[apitypes.NamespacedName]ReleaseState{}}
+
+// releaseStateTracker tracks the release state of each custom resource to
+// maintain the releases gauge.
+type releaseStateTracker struct {
+	mu     sync.Mutex
+	states map[schema.GroupVersionKind]map[apitypes.NamespacedName]ReleaseState
+}
+
+// SetReleaseState records the state of the release of the custom resource of
+// gvk named key.
+func SetReleaseState(gvk schema.GroupVersionKind, key apitypes.NamespacedName, state ReleaseState) {
+	releaseStates.set(gvk, key, state)
+}
+
+// DeleteReleaseState stops tracking the release of the custom resource of gvk
+// named key.
+func DeleteReleaseState(gvk schema.GroupVersionKind, key apitypes.NamespacedName) {
+	releaseStates.set(gvk, key, "")
+}
+
+func (t *releaseStateTracker) set(gvk schema.GroupVersionKind, key apitypes.NamespacedName, state ReleaseState) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	states, ok := t.states[gvk]
+	if !ok {
+		states = map[apitypes.NamespacedName]ReleaseState{}
+		t.states[gvk] = states
+	}
+	old, tracked := states[key]
+	if tracked && old == state {
+		return
+	}
+	if tracked {
+		releases.WithLabelValues(gvkLabelValues(gvk, string(old))...).Dec()
+	}
+	if state == "" {
+		delete(states, key)
+		return
+	}
+	states[key] = state
+	releases.WithLabelValues(gvkLabelValues(gvk, string(state))...).Inc()
+}
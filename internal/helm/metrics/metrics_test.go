@@ -0,0 +1,111 @@
+// Copyright 2026 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	apitypes "k8s.io/apimachinery/pkg/types"
+)
+
+var testGVK = schema.GroupVersionKind{Group: "example.com", Version: "v1alpha1", Kind: "Nginx"}
+
+func TestRegisterReleaseMetrics(t *testing.T) {
+	r := prometheus.NewPedanticRegistry()
+	RegisterReleaseMetrics(r)
+
+	ObserveReleaseOperation(testGVK, OperationInstall, time.Now(), nil)
+	ObserveChartRender(testGVK, time.Now())
+	AddDriftCorrection(testGVK, "Patched")
+	SetDependentWatch(testGVK, 1)
+	defer DeleteDependentWatch(testGVK)
+	SetReleaseState(testGVK, apitypes.NamespacedName{Namespace: "default", Name: "registered"}, ReleaseStateDeployed)
+	defer DeleteReleaseState(testGVK, apitypes.NamespacedName{Namespace: "default", Name: "registered"})
+
+	families, err := r.Gather()
+	require.NoError(t, err)
+	var names []string
+	for _, f := range families {
+		names = append(names, f.GetName())
+	}
+	assert.ElementsMatch(t, []string{
+		"helm_operator_release_operation_duration_seconds",
+		"helm_operator_release_operations_total",
+		"helm_operator_releases",
+		"helm_operator_drift_corrections_total",
+		"helm_operator_chart_render_duration_seconds",
+		"helm_operator_dependent_watches",
+	}, names)
+}
+
+// value returns the value of the counter or gauge m.
+func value(t *testing.T, m prometheus.Metric) float64 {
+	var out dto.Metric
+	require.NoError(t, m.Write(&out))
+	if out.Counter != nil {
+		return out.Counter.GetValue()
+	}
+	return out.Gauge.GetValue()
+}
+
+func TestObserveReleaseOperation(t *testing.T) {
+	success := releaseOperations.WithLabelValues("example.com", "v1alpha1", "Nginx", "upgrade", "success")
+	failure := releaseOperations.WithLabelValues("example.com", "v1alpha1", "Nginx", "upgrade", "error")
+	successCount, failureCount := value(t, success), value(t, failure)
+
+	ObserveReleaseOperation(testGVK, OperationUpgrade, time.Now(), nil)
+	ObserveReleaseOperation(testGVK, OperationUpgrade, time.Now(), nil)
+	ObserveReleaseOperation(testGVK, OperationUpgrade, time.Now(), errors.New("upgrade failed"))
+
+	assert.Equal(t, successCount+2, value(t, success))
+	assert.Equal(t, failureCount+1, value(t, failure))
+
+	// Drift reconciliations are distinguished from reconciliations of CRs.
+	drift := releaseOperations.WithLabelValues("example.com", "v1alpha1", "Nginx", "drift_reconcile", "success")
+	driftCount := value(t, drift)
+	ObserveReleaseOperation(testGVK, OperationDriftReconcile, time.Now(), nil)
+	assert.Equal(t, driftCount+1, value(t, drift))
+}
+
+func TestSetReleaseState(t *testing.T) {
+	gvk := schema.GroupVersionKind{Group: "example.com", Version: "v1alpha1", Kind: "Memcached"}
+	deployed := releases.WithLabelValues("example.com", "v1alpha1", "Memcached", "deployed")
+	failed := releases.WithLabelValues("example.com", "v1alpha1", "Memcached", "failed")
+	a := apitypes.NamespacedName{Namespace: "default", Name: "a"}
+	b := apitypes.NamespacedName{Namespace: "default", Name: "b"}
+
+	SetReleaseState(gvk, a, ReleaseStateDeployed)
+	SetReleaseState(gvk, b, ReleaseStateDeployed)
+	SetReleaseState(gvk, b, ReleaseStateDeployed)
+	assert.Equal(t, 2.0, value(t, deployed))
+	assert.Equal(t, 0.0, value(t, failed))
+
+	SetReleaseState(gvk, a, ReleaseStateFailed)
+	assert.Equal(t, 1.0, value(t, deployed))
+	assert.Equal(t, 1.0, value(t, failed))
+
+	DeleteReleaseState(gvk, a)
+	DeleteReleaseState(gvk, b)
+	DeleteReleaseState(gvk, b)
+	assert.Equal(t, 0.0, value(t, deployed))
+	assert.Equal(t, 0.0, value(t, failed))
+}
@@ -0,0 +1,177 @@
+// Copyright 2026 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package postrenderer implements the post-renderers of the helm-operator,
+// which patch the manifests rendered from a chart before they are applied:
+// kustomize overlays and executables.
+package postrenderer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"helm.sh/helm/v3/pkg/postrender"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/krusty"
+	kustypes "sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/yaml"
+)
+
+// RenderedManifestsFile is the file of a kustomize overlay the rendered
+// manifests are written to. The kustomization of the overlay must list it in
+// its resources.
+const RenderedManifestsFile = "helm-output.yaml"
+
+// Options configures the post-renderer of a watch. Exactly one of Kustomize
+// and Exec must be set.
+type Options struct {
+	// Kustomize patches the rendered manifests with a kustomize overlay.
+	Kustomize *Kustomize `json:"kustomize,omitempty"`
+	// Exec patches the rendered manifests with an executable, which reads
+	// them from its standard input and writes the patched manifests to its
+	// standard output.
+	Exec *Exec `json:"exec,omitempty"`
+}
+
+// Kustomize configures a kustomize overlay.
+type Kustomize struct {
+	// Dir is the directory of the overlay, which contains a kustomization
+	// listing RenderedManifestsFile in its resources.
+	Dir string `json:"dir"`
+}
+
+// Exec configures an executable post-renderer.
+type Exec struct {
+	// Command is the path of the executable, or its name if it is in PATH.
+	Command string `json:"command"`
+	// Args are the arguments of the executable.
+	Args []string `json:"args,omitempty"`
+}
+
+// Validate returns an error if o is invalid.
+func (o Options) Validate() error {
+	switch {
+	case o.Kustomize == nil && o.Exec == nil:
+		return errors.New("kustomize or exec must be set")
+	case o.Kustomize != nil && o.Exec != nil:
+		return errors.New("kustomize and exec are mutually exclusive")
+	case o.Kustomize != nil && o.Kustomize.Dir == "":
+		return errors.New("kustomize.dir must be set")
+	case o.Exec != nil && o.Exec.Command == "":
+		return errors.New("exec.command must be set")
+	}
+	return nil
+}
+
+// New returns the post-renderer configured by o. The files of a kustomize
+// overlay are read once, so the operator must be restarted to use changes to
+// them.
+func (o Options) New() (postrender.PostRenderer, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+	if o.Exec != nil {
+		return postrender.NewExec(o.Exec.Command, o.Exec.Args...)
+	}
+	return newKustomize(o.Kustomize.Dir)
+}
+
+// kustomize runs a kustomize overlay on the rendered manifests. The files of
+// the overlay are kept in memory, and copied into a new in-memory file system
+// with the rendered manifests for each run, so that runs are independent.
+type kustomize struct {
+	dir   string
+	files map[string][]byte
+}
+
+func newKustomize(dir string) (*kustomize, error) {
+	k := &kustomize{dir: dir, files: map[string][]byte{}}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		k.files[filepath.ToSlash(rel)] = data
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read kustomize overlay %s: %w", dir, err)
+	}
+	if err := k.verifyKustomization(); err != nil {
+		return nil, fmt.Errorf("invalid kustomize overlay %s: %w", dir, err)
+	}
+	return k, nil
+}
+
+// verifyKustomization returns an error if the kustomization of the overlay
+// does not include the rendered manifests, which would otherwise be dropped
+// from the release, deleting its resources.
+func (k *kustomize) verifyKustomization() error {
+	for _, name := range konfig.RecognizedKustomizationFileNames() {
+		data, ok := k.files[name]
+		if !ok {
+			continue
+		}
+		kustomization := &kustypes.Kustomization{}
+		if err := yaml.Unmarshal(data, kustomization); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+		if !slices.Contains(kustomization.Resources, RenderedManifestsFile) {
+			return fmt.Errorf("%s must list %s in its resources", name, RenderedManifestsFile)
+		}
+		return nil
+	}
+	return fmt.Errorf("no kustomization file found: one of %v is required", konfig.RecognizedKustomizationFileNames())
+}
+
+// Run implements postrender.PostRenderer.
+func (k *kustomize) Run(renderedManifests *bytes.Buffer) (*bytes.Buffer, error) {
+	const root = "/overlay"
+	fSys := filesys.MakeFsInMemory()
+	for name, data := range k.files {
+		path := filepath.Join(root, name)
+		if err := fSys.MkdirAll(filepath.Dir(path)); err != nil {
+			return nil, err
+		}
+		if err := fSys.WriteFile(path, data); err != nil {
+			return nil, err
+		}
+	}
+	if err := fSys.WriteFile(filepath.Join(root, RenderedManifestsFile), renderedManifests.Bytes()); err != nil {
+		return nil, err
+	}
+
+	resources, err := krusty.MakeKustomizer(krusty.MakeDefaultOptions()).Run(fSys, root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run kustomize overlay %s: %w", k.dir, err)
+	}
+	out, err := resources.AsYaml()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode output of kustomize overlay %s: %w", k.dir, err)
+	}
+	return bytes.NewBuffer(out), nil
+}
@@ -0,0 +1,147 @@
+// Copyright 2026 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postrenderer
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const renderedManifests = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: test
+data:
+  key: value
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: test
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: registry.example.com/app:1.0.0
+`
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(data), 0o755))
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		options Options
+		err     string
+	}{
+		{name: "empty", options: Options{}, err: "kustomize or exec must be set"},
+		{
+			name:    "both",
+			options: Options{Kustomize: &Kustomize{Dir: "overlay"}, Exec: &Exec{Command: "kustomize"}},
+			err:     "kustomize and exec are mutually exclusive",
+		},
+		{name: "no dir", options: Options{Kustomize: &Kustomize{}}, err: "kustomize.dir must be set"},
+		{name: "no command", options: Options{Exec: &Exec{Args: []string{"-v"}}}, err: "exec.command must be set"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.EqualError(t, tc.options.Validate(), tc.err)
+		})
+	}
+	assert.NoError(t, Options{Kustomize: &Kustomize{Dir: "overlay"}}.Validate())
+}
+
+func TestKustomize(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"kustomization.yaml": `resources:
+- helm-output.yaml
+labels:
+- pairs:
+    team: platform
+patches:
+- path: patches/sidecar.yaml
+images:
+- name: registry.example.com/app
+  newName: mirror.example.com/app
+`,
+		"patches/sidecar.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: test
+spec:
+  template:
+    spec:
+      containers:
+      - name: sidecar
+        image: mirror.example.com/sidecar:1.0.0
+`,
+	})
+	pr, err := Options{Kustomize: &Kustomize{Dir: dir}}.New()
+	require.NoError(t, err)
+
+	// Overlays are run on in-memory copies, so they can be run repeatedly.
+	for range 2 {
+		out, err := pr.Run(bytes.NewBufferString(renderedManifests))
+		require.NoError(t, err)
+		assert.Contains(t, out.String(), "team: platform")
+		assert.Contains(t, out.String(), "name: sidecar")
+		assert.Contains(t, out.String(), "image: mirror.example.com/app:1.0.0")
+		assert.Contains(t, out.String(), "kind: ConfigMap")
+	}
+	assert.NoFileExists(t, filepath.Join(dir, RenderedManifestsFile))
+
+	_, err = pr.Run(bytes.NewBufferString("not: [valid"))
+	assert.ErrorContains(t, err, "failed to run kustomize overlay "+dir)
+}
+
+func TestKustomizeInvalidOverlay(t *testing.T) {
+	dir := t.TempDir()
+	_, err := Options{Kustomize: &Kustomize{Dir: dir}}.New()
+	assert.ErrorContains(t, err, "no kustomization file found")
+
+	writeFiles(t, dir, map[string]string{"kustomization.yaml": "resources:\n- deployment.yaml\n"})
+	_, err = Options{Kustomize: &Kustomize{Dir: dir}}.New()
+	assert.ErrorContains(t, err, "kustomization.yaml must list helm-output.yaml in its resources")
+
+	_, err = Options{Kustomize: &Kustomize{Dir: filepath.Join(dir, "missing")}}.New()
+	assert.ErrorContains(t, err, "failed to read kustomize overlay")
+}
+
+func TestExec(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "relabel.sh")
+	writeFiles(t, dir, map[string]string{"relabel.sh": "#!/bin/sh\nsed \"s/name: test/name: $1/\"\n"})
+
+	pr, err := Options{Exec: &Exec{Command: script, Args: []string{"patched"}}}.New()
+	require.NoError(t, err)
+	out, err := pr.Run(bytes.NewBufferString(renderedManifests))
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), "name: patched")
+	assert.NotContains(t, out.String(), "name: test")
+
+	_, err = Options{Exec: &Exec{Command: filepath.Join(dir, "missing.sh")}}.New()
+	assert.Error(t, err)
+}
@@ -0,0 +1,222 @@
+// Copyright 2026 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package queue configures the workqueues of the helm-operator controllers:
+// how failed reconciliations are rate limited, and which custom resources are
+// reconciled first.
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"golang.org/x/time/rate"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/priorityqueue"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/operator-framework/operator-sdk/internal/helm/internal/types"
+)
+
+var log = logf.Log.WithName("helm.queue")
+
+const (
+	defaultBaseDelay = 5 * time.Millisecond
+	defaultMaxDelay  = 1000 * time.Second
+	defaultBurst     = 100
+)
+
+// RateLimiter configures how the reconciliations of custom resources are
+// delayed when they are requeued after a failure.
+type RateLimiter struct {
+	// BaseDelay is the delay before the first retry of a failed
+	// reconciliation, which doubles with each failure. It defaults to 5ms.
+	BaseDelay metav1.Duration `json:"baseDelay,omitempty"`
+	// MaxDelay bounds the delay between retries. It defaults to 1000s.
+	MaxDelay metav1.Duration `json:"maxDelay,omitempty"`
+	// QPS bounds the rate of retries of all custom resources, per second. The
+	// rate is not bounded if QPS is 0.
+	QPS float64 `json:"qps,omitempty"`
+	// Burst is the number of retries allowed above QPS. It defaults to 100.
+	Burst int `json:"burst,omitempty"`
+}
+
+// Validate returns an error if r is invalid.
+func (r RateLimiter) Validate() error {
+	if r.BaseDelay.Duration < 0 || r.MaxDelay.Duration < 0 {
+		return errors.New("delays must not be negative")
+	}
+	if r.MaxDelay.Duration != 0 && r.MaxDelay.Duration < r.baseDelay() {
+		return fmt.Errorf("maxDelay %s must not be less than baseDelay %s", r.MaxDelay.Duration, r.baseDelay())
+	}
+	if r.QPS < 0 || r.Burst < 0 {
+		return errors.New("qps and burst must not be negative")
+	}
+	return nil
+}
+
+func (r RateLimiter) baseDelay() time.Duration {
+	if r.BaseDelay.Duration == 0 {
+		return defaultBaseDelay
+	}
+	return r.BaseDelay.Duration
+}
+
+// New returns the rate limiter configured by r: a per-item exponential
+// backoff, combined with an overall token bucket if QPS is set.
+func (r RateLimiter) New() workqueue.TypedRateLimiter[reconcile.Request] {
+	maxDelay := r.MaxDelay.Duration
+	if maxDelay == 0 {
+		maxDelay = defaultMaxDelay
+	}
+	exponential := workqueue.NewTypedItemExponentialFailureRateLimiter[reconcile.Request](r.baseDelay(), maxDelay)
+	if r.QPS == 0 {
+		return exponential
+	}
+	burst := r.Burst
+	if burst == 0 {
+		burst = defaultBurst
+	}
+	return workqueue.NewTypedMaxOfRateLimiter(
+		exponential,
+		&workqueue.TypedBucketRateLimiter[reconcile.Request]{Limiter: rate.NewLimiter(rate.Limit(r.QPS), burst)},
+	)
+}
+
+// PriorityRule assigns a priority to the reconciliations of the custom
+// resources it matches. Custom resources with a higher priority are
+// reconciled first.
+type PriorityRule struct {
+	// Selector matches custom resources by their labels.
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+	// NewResources matches custom resources whose release is not installed
+	// yet, such as newly created custom resources.
+	NewResources bool `json:"newResources,omitempty"`
+	// Priority is the priority of the matched custom resources. It may be
+	// negative to reconcile them after other custom resources, whose priority
+	// is 0.
+	Priority int `json:"priority"`
+}
+
+// Prioritizer assigns priorities to custom resources with priority rules.
+type Prioritizer struct {
+	rules []compiledRule
+}
+
+type compiledRule struct {
+	selector     labels.Selector
+	newResources bool
+	priority     int
+}
+
+// NewPrioritizer returns a Prioritizer applying rules. A custom resource
+// matched by several rules has the priority of the first of them.
+func NewPrioritizer(rules []PriorityRule) (*Prioritizer, error) {
+	p := &Prioritizer{}
+	for i, rule := range rules {
+		if rule.Selector == nil && !rule.NewResources {
+			return nil, fmt.Errorf("priority rule %d: selector or newResources must be set", i)
+		}
+		c := compiledRule{newResources: rule.NewResources, priority: rule.Priority}
+		if rule.Selector != nil {
+			selector, err := metav1.LabelSelectorAsSelector(rule.Selector)
+			if err != nil {
+				return nil, fmt.Errorf("priority rule %d: invalid selector: %w", i, err)
+			}
+			c.selector = selector
+		}
+		p.rules = append(p.rules, c)
+	}
+	return p, nil
+}
+
+// PriorityFor returns the priority of o, and false if no rule matches o. A
+// rule with both a selector and newResources matches custom resources
+// matching both.
+func (p *Prioritizer) PriorityFor(o *unstructured.Unstructured) (int, bool) {
+	for _, rule := range p.rules {
+		if rule.selector != nil && !rule.selector.Matches(labels.Set(o.GetLabels())) {
+			continue
+		}
+		if rule.newResources && types.StatusFor(o).DeployedRelease != nil {
+			continue
+		}
+		return rule.priority, true
+	}
+	return 0, false
+}
+
+// NewPriorityQueue returns a function creating the priority queue of the
+// controller of the custom resources of gvk, which are read from c. Every
+// reconciliation of a custom resource matched by a rule of p is queued with
+// the priority of the rule, whatever the event that triggered it. Other
+// reconciliations are queued with their default priority, which is lowered
+// for the initial reconciliations of existing custom resources.
+func NewPriorityQueue(c client.Reader, gvk schema.GroupVersionKind, p *Prioritizer) func(string,
+	workqueue.TypedRateLimiter[reconcile.Request]) workqueue.TypedRateLimitingInterface[reconcile.Request] {
+	return func(controllerName string,
+		rateLimiter workqueue.TypedRateLimiter[reconcile.Request]) workqueue.TypedRateLimitingInterface[reconcile.Request] {
+		q := priorityqueue.New(controllerName, func(o *priorityqueue.Opts[reconcile.Request]) {
+			o.Log = log.WithValues("controller", controllerName)
+			o.RateLimiter = rateLimiter
+		})
+		return &prioritizedQueue{PriorityQueue: q, priorityFor: func(req reconcile.Request) (int, bool) {
+			o := &unstructured.Unstructured{}
+			o.SetGroupVersionKind(gvk)
+			// The reader is backed by the cache of the manager, so custom
+			// resources are not read from the API server.
+			if err := c.Get(context.TODO(), req.NamespacedName, o); err != nil {
+				return 0, false
+			}
+			return p.PriorityFor(o)
+		}}
+	}
+}
+
+// prioritizedQueue overrides the priority of the requests added to a
+// priority queue with the priority returned by priorityFor, if any.
+type prioritizedQueue struct {
+	priorityqueue.PriorityQueue[reconcile.Request]
+	priorityFor func(reconcile.Request) (int, bool)
+}
+
+func (q *prioritizedQueue) Add(item reconcile.Request) {
+	q.AddWithOpts(priorityqueue.AddOpts{}, item)
+}
+
+func (q *prioritizedQueue) AddAfter(item reconcile.Request, duration time.Duration) {
+	q.AddWithOpts(priorityqueue.AddOpts{After: duration}, item)
+}
+
+func (q *prioritizedQueue) AddRateLimited(item reconcile.Request) {
+	q.AddWithOpts(priorityqueue.AddOpts{RateLimited: true}, item)
+}
+
+func (q *prioritizedQueue) AddWithOpts(opts priorityqueue.AddOpts, items ...reconcile.Request) {
+	for _, item := range items {
+		itemOpts := opts
+		if priority, ok := q.priorityFor(item); ok {
+			itemOpts.Priority = priority
+		}
+		q.PriorityQueue.AddWithOpts(itemOpts, item)
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright 2026 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	apitypes "k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/priorityqueue"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func duration(d time.Duration) metav1.Duration {
+	return metav1.Duration{Duration: d}
+}
+
+func TestRateLimiterValidate(t *testing.T) {
+	assert.NoError(t, RateLimiter{}.Validate())
+	assert.NoError(t, RateLimiter{BaseDelay: duration(time.Second), MaxDelay: duration(time.Minute), QPS: 5}.Validate())
+	assert.ErrorContains(t, RateLimiter{BaseDelay: duration(-time.Second)}.Validate(), "must not be negative")
+	assert.ErrorContains(t, RateLimiter{MaxDelay: duration(time.Millisecond)}.Validate(),
+		"maxDelay 1ms must not be less than baseDelay 5ms")
+	assert.ErrorContains(t, RateLimiter{QPS: -1}.Validate(), "qps and burst must not be negative")
+}
+
+func TestRateLimiterNew(t *testing.T) {
+	item := reconcile.Request{NamespacedName: apitypes.NamespacedName{Namespace: "default", Name: "test"}}
+
+	rl := RateLimiter{BaseDelay: duration(time.Second), MaxDelay: duration(3 * time.Second)}.New()
+	assert.Equal(t, time.Second, rl.When(item))
+	assert.Equal(t, 2*time.Second, rl.When(item))
+	assert.Equal(t, 3*time.Second, rl.When(item))
+	assert.Equal(t, 3, rl.NumRequeues(item))
+	rl.Forget(item)
+	assert.Equal(t, time.Second, rl.When(item))
+
+	// The bucket delays retries beyond its burst.
+	rl = RateLimiter{BaseDelay: duration(time.Millisecond), QPS: 1, Burst: 1}.New()
+	assert.Equal(t, time.Millisecond, rl.When(item))
+	assert.Greater(t, rl.When(item), 500*time.Millisecond)
+}
+
+func newCR(labels map[string]string, installed bool) *unstructured.Unstructured {
+	o := &unstructured.Unstructured{}
+	o.SetGroupVersionKind(schema.GroupVersionKind{Group: "example.com", Version: "v1alpha1", Kind: "Nginx"})
+	o.SetNamespace("default")
+	o.SetName("test")
+	o.SetLabels(labels)
+	if installed {
+		o.Object["status"] = map[string]any{"deployedRelease": map[string]any{"name": "test"}}
+	}
+	return o
+}
+
+func TestPrioritizer(t *testing.T) {
+	_, err := NewPrioritizer([]PriorityRule{{Priority: 10}})
+	assert.EqualError(t, err, "priority rule 0: selector or newResources must be set")
+	_, err = NewPrioritizer([]PriorityRule{{Selector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+		{Key: "tier", Operator: "Unknown"},
+	}}}})
+	assert.ErrorContains(t, err, "priority rule 0: invalid selector")
+
+	p, err := NewPrioritizer([]PriorityRule{
+		{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "critical"}}, Priority: 100},
+		{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "batch"}}, Priority: -10},
+		{NewResources: true, Priority: 10},
+	})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name      string
+		o         *unstructured.Unstructured
+		priority  int
+		isMatched bool
+	}{
+		{name: "labels", o: newCR(map[string]string{"tier": "critical"}, true), priority: 100, isMatched: true},
+		{name: "first rule", o: newCR(map[string]string{"tier": "critical"}, false), priority: 100, isMatched: true},
+		{name: "negative priority", o: newCR(map[string]string{"tier": "batch"}, true), priority: -10, isMatched: true},
+		{name: "new resources", o: newCR(nil, false), priority: 10, isMatched: true},
+		{name: "no match", o: newCR(nil, true)},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			priority, ok := p.PriorityFor(tc.o)
+			assert.Equal(t, tc.isMatched, ok)
+			assert.Equal(t, tc.priority, priority)
+		})
+	}
+}
+
+// recordingQueue records the options of the requests added to it.
+type recordingQueue struct {
+	priorityqueue.PriorityQueue[reconcile.Request]
+	added map[string]priorityqueue.AddOpts
+}
+
+func (q *recordingQueue) AddWithOpts(opts priorityqueue.AddOpts, items ...reconcile.Request) {
+	for _, item := range items {
+		q.added[item.Name] = opts
+	}
+}
+
+func TestPrioritizedQueue(t *testing.T) {
+	critical := newCR(map[string]string{"tier": "critical"}, true)
+	critical.SetName("critical")
+	other := newCR(nil, true)
+	other.SetName("other")
+	c := fake.NewClientBuilder().WithObjects(critical, other).Build()
+	p, err := NewPrioritizer([]PriorityRule{
+		{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "critical"}}, Priority: 100},
+	})
+	require.NoError(t, err)
+
+	rq := &recordingQueue{added: map[string]priorityqueue.AddOpts{}}
+	newQueue := NewPriorityQueue(c, critical.GroupVersionKind(), p)
+	q := newQueue("nginx-controller", RateLimiter{}.New()).(*prioritizedQueue)
+	defer q.PriorityQueue.ShutDown()
+	q.PriorityQueue = rq
+
+	request := func(name string) reconcile.Request {
+		return reconcile.Request{NamespacedName: apitypes.NamespacedName{Namespace: "default", Name: name}}
+	}
+	q.AddWithOpts(priorityqueue.AddOpts{Priority: -100}, request("critical"), request("other"))
+	q.AddRateLimited(request("deleted"))
+	assert.Equal(t, map[string]priorityqueue.AddOpts{
+		"critical": {Priority: 100},
+		"other":    {Priority: -100},
+		"deleted":  {RateLimited: true},
+	}, rq.added)
+
+	q.AddAfter(request("critical"), time.Minute)
+	assert.Equal(t, priorityqueue.AddOpts{After: time.Minute, Priority: 100}, rq.added["critical"])
+}
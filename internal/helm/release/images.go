@@ -0,0 +1,81 @@
+// Copyright 2026 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package release
+
+import (
+	"context"
+	"maps"
+	"sort"
+
+	rpb "helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/releaseutil"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"github.com/operator-framework/operator-sdk/internal/helm/images"
+	"github.com/operator-framework/operator-sdk/internal/helm/internal/types"
+)
+
+// pinnedImages returns the images pinned for manifest. The images applied
+// with the deployed release are pinned, so that drift corrections and dry
+// runs keep the digests the release was applied with, while the images of
+// the other revisions of the release are resolved again when they are
+// installed, upgraded to or rolled back to.
+func (m *manager) pinnedImages(manifest string) images.Pins {
+	if m.deployedRelease == nil || manifest != m.deployedRelease.Manifest {
+		return nil
+	}
+	return m.imagePins
+}
+
+// Images returns the images of rel, the deployed revision of the release,
+// that the image rewriter of the manager rewrites when they are applied.
+// Since the manifests of releases keep the images rendered from their chart,
+// the images are rewritten again, with the images applied by the manager or
+// pinned for rel. Images that cannot be rewritten are omitted.
+func (m manager) Images(ctx context.Context, rel *rpb.Release) []types.HelmAppImage {
+	if m.imageRewriter == nil || rel == nil {
+		return nil
+	}
+	pins := images.Pins{}
+	maps.Copy(pins, m.pinnedImages(rel.Manifest))
+	maps.Copy(pins, m.appliedImages)
+	seen := map[types.HelmAppImage]bool{}
+	var images []types.HelmAppImage
+	for _, manifest := range releaseutil.SplitManifests(rel.Manifest) {
+		u := &unstructured.Unstructured{}
+		if err := yaml.Unmarshal([]byte(manifest), &u.Object); err != nil || u.Object == nil {
+			continue
+		}
+		rewrites, err := m.imageRewriter.RewriteObject(ctx, u, pins)
+		if err != nil {
+			continue
+		}
+		for _, rw := range rewrites {
+			image := types.HelmAppImage{Source: rw.Source, Image: rw.Image}
+			if !seen[image] {
+				seen[image] = true
+				images = append(images, image)
+			}
+		}
+	}
+	sort.Slice(images, func(i, j int) bool {
+		if images[i].Source != images[j].Source {
+			return images[i].Source < images[j].Source
+		}
+		return images[i].Image < images[j].Image
+	})
+	return images
+}
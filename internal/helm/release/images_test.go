@@ -0,0 +1,87 @@
+// Copyright 2026 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package release
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	rpb "helm.sh/helm/v3/pkg/release"
+
+	"github.com/operator-framework/operator-sdk/internal/helm/images"
+	"github.com/operator-framework/operator-sdk/internal/helm/internal/types"
+)
+
+func TestManagerImages(t *testing.T) {
+	rel := &rpb.Release{Manifest: `---
+# Source: test/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: web
+        image: nginx:1.27
+      - name: exporter
+        image: quay.io/team/exporter:v1
+---
+# Source: test/templates/job.yaml
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: migrate
+spec:
+  template:
+    spec:
+      containers:
+      - name: migrate
+        image: nginx:1.27
+---
+# Source: test/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+`}
+	m := manager{imageRewriter: images.NewRewriter([]images.Mirror{
+		{Source: "docker.io/library", Mirrors: []string{"mirror.example.com/library"}},
+	}, false)}
+	assert.Equal(t, []types.HelmAppImage{
+		{Source: "nginx:1.27", Image: "mirror.example.com/library/nginx:1.27"},
+	}, m.Images(context.TODO(), rel))
+
+	assert.Nil(t, m.Images(context.TODO(), nil))
+	assert.Nil(t, manager{}.Images(context.TODO(), rel))
+
+	// The images of the deployed release are pinned, and the images applied
+	// by the manager override them.
+	m = manager{
+		imageRewriter:   images.NewRewriter(nil, true),
+		deployedRelease: rel,
+		imagePins: images.Pins{
+			"nginx:1.27":               "nginx@sha256:1",
+			"quay.io/team/exporter:v1": "quay.io/team/exporter@sha256:1",
+		},
+		appliedImages: images.Pins{"nginx:1.27": "nginx@sha256:2"},
+	}
+	assert.Equal(t, []types.HelmAppImage{
+		{Source: "nginx:1.27", Image: "nginx@sha256:2"},
+		{Source: "quay.io/team/exporter:v1", Image: "quay.io/team/exporter@sha256:1"},
+	}, m.Images(context.TODO(), rel))
+}
@@ -20,42 +20,63 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/operator-framework/operator-lib/handler"
 	jsonpatch "gomodules.xyz/jsonpatch/v3"
 	"helm.sh/helm/v3/pkg/action"
 	cpb "helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/kube"
+	"helm.sh/helm/v3/pkg/postrender"
 	rpb "helm.sh/helm/v3/pkg/release"
 	"helm.sh/helm/v3/pkg/releaseutil"
 	"helm.sh/helm/v3/pkg/storage"
 	"helm.sh/helm/v3/pkg/storage/driver"
 	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apiextv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	apitypes "k8s.io/apimachinery/pkg/types"
 	apiutilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/strategicpatch"
 	"k8s.io/cli-runtime/pkg/resource"
 	"k8s.io/client-go/discovery"
 
+	"github.com/operator-framework/operator-sdk/internal/helm/images"
 	"github.com/operator-framework/operator-sdk/internal/helm/internal/types"
 	"github.com/operator-framework/operator-sdk/internal/helm/manifestutil"
+	"github.com/operator-framework/operator-sdk/internal/helm/metrics"
+	"github.com/operator-framework/operator-sdk/internal/helm/tracing"
 )
 
 // Manager manages a Helm release. It can install, upgrade, reconcile,
 // and uninstall a release.
 type Manager interface {
 	ReleaseName() string
+	ReleaseOwner() (apitypes.UID, error)
 	IsInstalled() bool
 	IsUpgradeRequired() bool
-	Sync() error
+	Sync(context.Context) error
 	InstallRelease(...InstallOption) (*rpb.Release, error)
 	UpgradeRelease(...UpgradeOption) (*rpb.Release, *rpb.Release, error)
 	RollBack(...RollBackOption) error
-	ReconcileRelease(context.Context) (*rpb.Release, error)
+	History() ([]*rpb.Release, error)
+	ReleaseManifest(revision int) (string, error)
+	TestRelease(...TestOption) (*rpb.Release, error)
+	NotReadyResources(context.Context, string, bool) ([]string, error)
+	ReconcileRelease(context.Context, ...ReconcileOption) (*rpb.Release, []ResourceCorrection, error)
+	AdoptRelease(context.Context) (int, error)
+	DryRunRelease(context.Context) (*rpb.Release, error)
+	Subcharts(*rpb.Release) []types.HelmAppSubchart
+	Images(context.Context, *rpb.Release) []types.HelmAppImage
 	UninstallRelease(...UninstallOption) (*rpb.Release, error)
 	CleanupRelease(string) (bool, error)
 }
@@ -65,6 +86,8 @@ type manager struct {
 	storageBackend *storage.Storage
 	kubeClient     kube.Interface
 
+	gvk         schema.GroupVersionKind
+	ownerUID    apitypes.UID
 	releaseName string
 	namespace   string
 
@@ -75,14 +98,36 @@ type manager struct {
 	isUpgradeRequired bool
 	deployedRelease   *rpb.Release
 	chart             *cpb.Chart
+	subcharts         []cpb.Dependency
 
 	dryRunOption string
+	maxHistory   int
+	postRenderer postrender.PostRenderer
+
+	imageRewriter *images.Rewriter
+	// imagePins are the images applied with the deployed release, recorded
+	// in the status of the custom resource.
+	imagePins images.Pins
+	// appliedImages records the images rewritten by the kube client.
+	appliedImages images.Pins
 }
 
 type InstallOption func(*action.Install) error
 type UpgradeOption func(*action.Upgrade) error
 type UninstallOption func(*action.Uninstall) error
 type RollBackOption func(*action.Rollback) error
+type TestOption func(*action.ReleaseTesting) error
+type ReconcileOption func(*reconcileOptions) error
+
+type reconcileOptions struct {
+	serverSideApply bool
+	forceConflicts  bool
+	dryRun          bool
+}
+
+// FieldManager is the field manager used when release resources are applied
+// with server-side apply.
+const FieldManager = "helm-operator"
 
 // ReleaseName returns the name of the release.
 func (m manager) ReleaseName() string {
@@ -99,23 +144,15 @@ func (m manager) IsUpgradeRequired() bool {
 
 // Sync ensures the Helm storage backend is in sync with the status of the
 // custom resource.
-func (m *manager) Sync() error {
+func (m *manager) Sync(ctx context.Context) error {
 	// Get release history for this release name
 	releases, err := m.storageBackend.History(m.releaseName)
 	if err != nil && !notFoundErr(err) {
 		return fmt.Errorf("failed to retrieve release history: %w", err)
 	}
 
-	// Cleanup non-deployed release versions. If all release versions are
-	// non-deployed, this will ensure that failed installations are correctly
-	// retried.
-	for _, rel := range releases {
-		if rel.Info != nil && rel.Info.Status != rpb.StatusDeployed {
-			_, err := m.storageBackend.Delete(rel.Name, rel.Version)
-			if err != nil && !notFoundErr(err) {
-				return fmt.Errorf("failed to delete stale release version: %w", err)
-			}
-		}
+	if err := m.pruneHistory(releases); err != nil {
+		return err
 	}
 
 	// Load the most recently deployed release from the storage backend.
@@ -130,7 +167,7 @@ func (m *manager) Sync() error {
 	m.isInstalled = true
 
 	// Get the next candidate release to determine if an upgrade is necessary.
-	candidateRelease, err := m.getCandidateRelease(m.namespace, m.releaseName, m.chart, m.values)
+	candidateRelease, err := m.getCandidateRelease(ctx, m.namespace, m.releaseName, m.chart, m.values)
 	if err != nil {
 		return fmt.Errorf("failed to get candidate release: %w", err)
 	}
@@ -141,6 +178,62 @@ func (m *manager) Sync() error {
 	return nil
 }
 
+// pruneHistory deletes stale revisions of the release. Pending revisions are
+// left behind by interrupted operations and would block further operations.
+// If no revision is deployed, all revisions are deleted to ensure that failed
+// installations are correctly retried. Otherwise, the oldest revisions are
+// deleted so that at most maxHistory revisions, including the deployed one,
+// are kept. A maxHistory of 0 keeps all revisions.
+func (m manager) pruneHistory(releases []*rpb.Release) error {
+	releaseutil.Reverse(releases, releaseutil.SortByRevision)
+	kept := 0
+	for _, rel := range releases {
+		if rel.Info != nil && rel.Info.Status == rpb.StatusDeployed {
+			kept++
+		}
+	}
+	deployed := kept > 0
+
+	for _, rel := range releases {
+		if rel.Info == nil || rel.Info.Status == rpb.StatusDeployed {
+			continue
+		}
+		if deployed && !rel.Info.Status.IsPending() && (m.maxHistory == 0 || kept < m.maxHistory) {
+			kept++
+			continue
+		}
+		_, err := m.storageBackend.Delete(rel.Name, rel.Version)
+		if err != nil && !notFoundErr(err) {
+			return fmt.Errorf("failed to delete stale release version: %w", err)
+		}
+	}
+	return nil
+}
+
+// releaseLabels returns the labels of the release records of the release,
+// which record the custom resource that owns it.
+func (m manager) releaseLabels() map[string]string {
+	if m.ownerUID == "" {
+		return nil
+	}
+	return map[string]string{OwnerUIDLabel: string(m.ownerUID)}
+}
+
+// ReleaseOwner returns the UID of the custom resource that installed or last
+// upgraded the deployed release, as recorded in its labels. The UID is empty
+// if the release is not deployed, or was installed by another client or by an
+// earlier version of the operator.
+func (m manager) ReleaseOwner() (apitypes.UID, error) {
+	deployedRelease, err := m.getDeployedRelease()
+	if errors.Is(err, driver.ErrReleaseNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get deployed release: %w", err)
+	}
+	return apitypes.UID(deployedRelease.Labels[OwnerUIDLabel]), nil
+}
+
 func notFoundErr(err error) bool {
 	return err != nil && strings.Contains(err.Error(), "not found")
 }
@@ -156,13 +249,45 @@ func (m manager) getDeployedRelease() (*rpb.Release, error) {
 	return deployedRelease, nil
 }
 
-func (m manager) getCandidateRelease(namespace, name string, chart *cpb.Chart,
+func (m manager) getCandidateRelease(ctx context.Context, namespace, name string, chart *cpb.Chart,
 	values map[string]any) (*rpb.Release, error) {
+	defer metrics.ObserveChartRender(m.gvk, time.Now())
+	ctx, span := tracing.Start(ctx, "DryRunUpgrade", tracing.AttributeRelease.String(name))
 	upgrade := action.NewUpgrade(m.actionConfig)
 	upgrade.Namespace = namespace
 	upgrade.DryRun = true
 	upgrade.DryRunOption = m.dryRunOption
-	return upgrade.Run(name, chart, values)
+	upgrade.PostRenderer = m.postRenderer
+	rel, err := upgrade.RunWithContext(ctx, name, chart, values)
+	tracing.End(span, err)
+	return rel, err
+}
+
+// DryRunRelease renders the release as it would be upgraded if it is
+// installed, or installed otherwise, without changing the cluster or the
+// release storage. It returns the errors reported by Helm, such as template
+// rendering and values schema validation errors, and conflicts with
+// resources of other releases.
+func (m manager) DryRunRelease(ctx context.Context) (*rpb.Release, error) {
+	_, err := m.getDeployedRelease()
+	if err == nil {
+		return m.getCandidateRelease(ctx, m.namespace, m.releaseName, m.chart, m.values)
+	}
+	if !errors.Is(err, driver.ErrReleaseNotFound) {
+		return nil, fmt.Errorf("failed to get deployed release: %w", err)
+	}
+
+	defer metrics.ObserveChartRender(m.gvk, time.Now())
+	ctx, span := tracing.Start(ctx, "DryRunInstall", tracing.AttributeRelease.String(m.releaseName))
+	install := action.NewInstall(m.actionConfig)
+	install.ReleaseName = m.releaseName
+	install.Namespace = m.namespace
+	install.DryRun = true
+	install.DryRunOption = m.dryRunOption
+	install.PostRenderer = m.postRenderer
+	rel, err := install.RunWithContext(ctx, m.chart, m.values)
+	tracing.End(span, err)
+	return rel, err
 }
 
 // InstallRelease performs a Helm release install.
@@ -170,6 +295,8 @@ func (m manager) InstallRelease(opts ...InstallOption) (*rpb.Release, error) {
 	install := action.NewInstall(m.actionConfig)
 	install.ReleaseName = m.releaseName
 	install.Namespace = m.namespace
+	install.PostRenderer = m.postRenderer
+	install.Labels = m.releaseLabels()
 	for _, o := range opts {
 		if err := o(install); err != nil {
 			return nil, fmt.Errorf("failed to apply install option: %w", err)
@@ -213,6 +340,9 @@ var ErrUpgradeFailed = errors.New("upgrade failed; rollback required")
 func (m manager) UpgradeRelease(opts ...UpgradeOption) (*rpb.Release, *rpb.Release, error) {
 	upgrade := action.NewUpgrade(m.actionConfig)
 	upgrade.Namespace = m.namespace
+	upgrade.MaxHistory = m.maxHistory
+	upgrade.PostRenderer = m.postRenderer
+	upgrade.Labels = m.releaseLabels()
 
 	for _, o := range opts {
 		if err := o(upgrade); err != nil {
@@ -227,11 +357,9 @@ func (m manager) UpgradeRelease(opts ...UpgradeOption) (*rpb.Release, *rpb.Relea
 			// As of Helm 2.13, if UpgradeRelease returns a non-nil release, that
 			// means the release was also recorded in the release store.
 			// Therefore, we should perform the rollback when we have a non-nil
-			// release. Any rollback error here would be unexpected, so always
-			// log both the upgrade and rollback errors.
-			fmt.Printf("release upgrade failed; %v", err)
-
-			return nil, nil, ErrUpgradeFailed
+			// release. The upgrade error is wrapped so that it is reported
+			// along with any rollback error.
+			return nil, nil, fmt.Errorf("%w: %v", ErrUpgradeFailed, err)
 		}
 		return nil, nil, fmt.Errorf("failed to upgrade release: %w", err)
 	}
@@ -245,9 +373,19 @@ func ForceRollback(force bool) RollBackOption {
 	}
 }
 
+// RollbackVersion configures RollBack to roll back to the given revision of
+// the release instead of the previous one.
+func RollbackVersion(version int) RollBackOption {
+	return func(r *action.Rollback) error {
+		r.Version = version
+		return nil
+	}
+}
+
 // RollBack attempts to reverse any partially applied releases
 func (m manager) RollBack(opts ...RollBackOption) error {
 	rollback := action.NewRollback(m.actionConfig)
+	rollback.MaxHistory = m.maxHistory
 
 	for _, fn := range opts {
 		if err := fn(rollback); err != nil {
@@ -262,29 +400,148 @@ func (m manager) RollBack(opts ...RollBackOption) error {
 	return nil
 }
 
+// History returns the revisions of the release kept in the storage backend,
+// most recent first.
+func (m manager) History() ([]*rpb.Release, error) {
+	releases, err := m.storageBackend.History(m.releaseName)
+	if err != nil && !notFoundErr(err) {
+		return nil, fmt.Errorf("failed to retrieve release history: %w", err)
+	}
+	releaseutil.Reverse(releases, releaseutil.SortByRevision)
+	return releases, nil
+}
+
+// ReleaseManifest returns the manifest of a revision of the release from the
+// storage backend.
+func (m manager) ReleaseManifest(revision int) (string, error) {
+	rel, err := m.storageBackend.Get(m.releaseName, revision)
+	if err != nil {
+		return "", fmt.Errorf("failed to get revision %d of release: %w", revision, err)
+	}
+	return rel.Manifest, nil
+}
+
+// ReleaseSecretName returns the name of the Secret in which the Helm secrets
+// storage driver stores a revision of a release.
+func ReleaseSecretName(releaseName string, revision int) string {
+	return fmt.Sprintf("sh.helm.release.v1.%s.v%d", releaseName, revision)
+}
+
+func TestTimeout(timeout time.Duration) TestOption {
+	return func(t *action.ReleaseTesting) error {
+		t.Timeout = timeout
+		return nil
+	}
+}
+
+// TestRelease runs the test hooks of the deployed release. The returned
+// release records the result of each test hook, and is returned even if a
+// test failed.
+func (m manager) TestRelease(opts ...TestOption) (*rpb.Release, error) {
+	test := action.NewReleaseTesting(m.actionConfig)
+	test.Namespace = m.namespace
+
+	for _, fn := range opts {
+		if err := fn(test); err != nil {
+			return nil, fmt.Errorf("failed to apply test option: %w", err)
+		}
+	}
+
+	testedRelease, err := test.Run(m.releaseName)
+	if err != nil {
+		return testedRelease, fmt.Errorf("release test failed: %w", err)
+	}
+	return testedRelease, nil
+}
+
+// ServerSideApply configures ReconcileRelease to correct drift by applying
+// the deployed release manifest with server-side apply instead of computing
+// client-side patches. If forceConflicts is true, ownership of fields managed
+// by other field managers is taken over.
+func ServerSideApply(forceConflicts bool) ReconcileOption {
+	return func(o *reconcileOptions) error {
+		o.serverSideApply = true
+		o.forceConflicts = forceConflicts
+		return nil
+	}
+}
+
+// DryRun configures ReconcileRelease to only report the resources that
+// would be corrected, with server-side dry-run requests, without changing
+// them.
+func DryRun() ReconcileOption {
+	return func(o *reconcileOptions) error {
+		o.dryRun = true
+		return nil
+	}
+}
+
+// ApplyConflictError is returned by ReconcileRelease when server-side apply
+// reports field ownership conflicts with other field managers. All
+// non-conflicting resources have been applied when it is returned.
+type ApplyConflictError struct {
+	Conflicts []string
+}
+
+func (e *ApplyConflictError) Error() string {
+	return fmt.Sprintf("field ownership conflicts: %s", strings.Join(e.Conflicts, "; "))
+}
+
+// CorrectionAction describes how ReconcileRelease corrected a release resource.
+type CorrectionAction string
+
+const (
+	CorrectionCreated CorrectionAction = "Created"
+	CorrectionPatched CorrectionAction = "Patched"
+)
+
+// ResourceCorrection identifies a release resource that ReconcileRelease
+// created or patched because it had drifted from the deployed release's
+// manifest.
+type ResourceCorrection struct {
+	GroupVersionKind schema.GroupVersionKind
+	Namespace        string
+	Name             string
+	Action           CorrectionAction
+}
+
 // ReconcileRelease creates or patches resources as necessary to match the
-// deployed release's manifest.
-func (m manager) ReconcileRelease(ctx context.Context) (*rpb.Release, error) {
-	err := reconcileRelease(ctx, m.kubeClient, m.deployedRelease.Manifest)
-	return m.deployedRelease, err
+// deployed release's manifest. It returns the resources that were corrected,
+// or that would be corrected with the DryRun option.
+func (m manager) ReconcileRelease(ctx context.Context, opts ...ReconcileOption) (*rpb.Release, []ResourceCorrection, error) {
+	o := &reconcileOptions{}
+	for _, fn := range opts {
+		if err := fn(o); err != nil {
+			return nil, nil, fmt.Errorf("failed to apply reconcile option: %w", err)
+		}
+	}
+
+	if o.serverSideApply {
+		corrections, err := applyRelease(ctx, m.kubeClient, m.deployedRelease.Manifest, o.forceConflicts, o.dryRun)
+		return m.deployedRelease, corrections, err
+	}
+	corrections, err := reconcileRelease(ctx, m.kubeClient, m.deployedRelease.Manifest, o.dryRun)
+	return m.deployedRelease, corrections, err
 }
 
-func reconcileRelease(_ context.Context, kubeClient kube.Interface, expectedManifest string) error {
+func reconcileRelease(_ context.Context, kubeClient kube.Interface, expectedManifest string, dryRun bool) ([]ResourceCorrection, error) {
 	expectedInfos, err := kubeClient.Build(bytes.NewBufferString(expectedManifest), false)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return expectedInfos.Visit(func(expected *resource.Info, err error) error {
+	var corrections []ResourceCorrection
+	err = expectedInfos.Visit(func(expected *resource.Info, err error) error {
 		if err != nil {
 			return fmt.Errorf("visit error: %w", err)
 		}
 
-		helper := resource.NewHelper(expected.Client, expected.Mapping)
+		helper := resource.NewHelper(expected.Client, expected.Mapping).DryRun(dryRun)
 		existing, err := helper.Get(expected.Namespace, expected.Name)
 		if apierrors.IsNotFound(err) {
 			if _, err := helper.Create(expected.Namespace, true, expected.Object); err != nil {
 				return fmt.Errorf("create error: %s", err)
 			}
+			corrections = append(corrections, correctionFor(expected, CorrectionCreated))
 			return nil
 		} else if err != nil {
 			return fmt.Errorf("could not get object: %w", err)
@@ -305,13 +562,265 @@ func reconcileRelease(_ context.Context, kubeClient kube.Interface, expectedMani
 			return nil
 		}
 
-		_, err = helper.Patch(expected.Namespace, expected.Name, patchType, patch,
+		patched, err := helper.Patch(expected.Namespace, expected.Name, patchType, patch,
 			&metav1.PatchOptions{})
 		if err != nil {
 			return fmt.Errorf("patch error: %w", err)
 		}
+		if objectChanged(existing, patched) {
+			corrections = append(corrections, correctionFor(expected, CorrectionPatched))
+		}
 		return nil
 	})
+	return corrections, err
+}
+
+func applyRelease(_ context.Context, kubeClient kube.Interface, expectedManifest string, forceConflicts, dryRun bool) ([]ResourceCorrection, error) {
+	expectedInfos, err := kubeClient.Build(bytes.NewBufferString(expectedManifest), false)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		corrections []ResourceCorrection
+		conflicts   []string
+	)
+	err = expectedInfos.Visit(func(expected *resource.Info, err error) error {
+		if err != nil {
+			return fmt.Errorf("visit error: %w", err)
+		}
+
+		data, err := createApplyPatch(expected)
+		if err != nil {
+			return fmt.Errorf("error creating apply patch: %w", err)
+		}
+
+		// The existing object is only needed to tell whether the apply
+		// created or changed anything.
+		helper := resource.NewHelper(expected.Client, expected.Mapping).DryRun(dryRun)
+		existing, err := helper.Get(expected.Namespace, expected.Name)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("could not get object: %w", err)
+		}
+		exists := err == nil
+
+		applied, err := helper.Patch(expected.Namespace, expected.Name, apitypes.ApplyPatchType, data,
+			&metav1.PatchOptions{FieldManager: FieldManager, Force: &forceConflicts})
+		if apierrors.IsConflict(err) {
+			conflicts = append(conflicts, fmt.Sprintf("%s %s: %v", expected.Mapping.GroupVersionKind.Kind, expected.ObjectName(), err))
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("apply error: %w", err)
+		}
+		if !exists {
+			corrections = append(corrections, correctionFor(expected, CorrectionCreated))
+		} else if objectChanged(existing, applied) {
+			corrections = append(corrections, correctionFor(expected, CorrectionPatched))
+		}
+		return nil
+	})
+	if err != nil {
+		return corrections, err
+	}
+	if len(conflicts) > 0 {
+		return corrections, &ApplyConflictError{Conflicts: conflicts}
+	}
+	return corrections, nil
+}
+
+// AdoptRelease takes ownership of a deployed release that was installed by
+// another client, such as the Helm CLI. Every revision of the release is
+// stored again, labeled with the UID of the custom resource, so that its
+// release record is owned by the custom resource, and the owner reference, or owner annotations, and the chart label of the
+// custom resource are added to the existing resources of the deployed
+// release. Missing resources are left to ReconcileRelease. It returns the
+// number of resources that were updated.
+func (m manager) AdoptRelease(ctx context.Context) (int, error) {
+	releases, err := m.storageBackend.History(m.releaseName)
+	if err != nil {
+		return 0, fmt.Errorf("failed to retrieve release history: %w", err)
+	}
+	for _, rel := range releases {
+		if rel.Labels == nil {
+			rel.Labels = map[string]string{}
+		}
+		maps.Copy(rel.Labels, m.releaseLabels())
+		if err := m.storageBackend.Update(rel); err != nil {
+			return 0, fmt.Errorf("failed to update release record of revision %d: %w", rel.Version, err)
+		}
+	}
+	return adoptResources(ctx, m.kubeClient, m.deployedRelease.Manifest)
+}
+
+func adoptResources(_ context.Context, kubeClient kube.Interface, manifest string) (int, error) {
+	expectedInfos, err := kubeClient.Build(bytes.NewBufferString(manifest), false)
+	if err != nil {
+		return 0, err
+	}
+	adopted := 0
+	err = expectedInfos.Visit(func(expected *resource.Info, err error) error {
+		if err != nil {
+			return fmt.Errorf("visit error: %w", err)
+		}
+
+		helper := resource.NewHelper(expected.Client, expected.Mapping)
+		existing, err := helper.Get(expected.Namespace, expected.Name)
+		if apierrors.IsNotFound(err) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("could not get object: %w", err)
+		}
+
+		patch, err := createOwnershipPatch(existing, expected.Object)
+		if err != nil {
+			return fmt.Errorf("cannot adopt %s %s: %w", expected.Mapping.GroupVersionKind.Kind, expected.ObjectName(), err)
+		}
+		if patch == nil {
+			return nil
+		}
+		if _, err := helper.Patch(expected.Namespace, expected.Name, apitypes.MergePatchType, patch,
+			&metav1.PatchOptions{}); err != nil {
+			return fmt.Errorf("patch error: %w", err)
+		}
+		adopted++
+		return nil
+	})
+	return adopted, err
+}
+
+// ownershipMetadata are the labels and annotations set on release resources
+// to record their owner.
+var ownershipMetadata = []string{ChartLabel, handler.NamespacedNameAnnotation, handler.TypeAnnotation}
+
+// createOwnershipPatch returns a JSON merge patch adding the owner references
+// and ownership labels and annotations of expected to existing, or nil if
+// existing already has them. It returns an error if existing is controlled by
+// another owner.
+func createOwnershipPatch(existing, expected runtime.Object) ([]byte, error) {
+	existingMeta, err := meta.Accessor(existing)
+	if err != nil {
+		return nil, err
+	}
+	expectedMeta, err := meta.Accessor(expected)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := map[string]any{}
+	ownerRefs := existingMeta.GetOwnerReferences()
+	controller := metav1.GetControllerOfNoCopy(existingMeta)
+	changed := false
+	for _, ref := range expectedMeta.GetOwnerReferences() {
+		if ref.Controller != nil && *ref.Controller && controller != nil && controller.UID != ref.UID {
+			return nil, fmt.Errorf("already controlled by %s %s", controller.Kind, controller.Name)
+		}
+		if !slices.ContainsFunc(ownerRefs, func(r metav1.OwnerReference) bool { return r.UID == ref.UID }) {
+			ownerRefs = append(ownerRefs, ref)
+			changed = true
+		}
+	}
+	if changed {
+		metadata["ownerReferences"] = ownerRefs
+	}
+	if labels := missingEntries(existingMeta.GetLabels(), expectedMeta.GetLabels()); len(labels) > 0 {
+		metadata["labels"] = labels
+	}
+	if annotations := missingEntries(existingMeta.GetAnnotations(), expectedMeta.GetAnnotations()); len(annotations) > 0 {
+		metadata["annotations"] = annotations
+	}
+	if len(metadata) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(map[string]any{"metadata": metadata})
+}
+
+// missingEntries returns the ownership entries of expected that are missing
+// from, or different in, existing.
+func missingEntries(existing, expected map[string]string) map[string]string {
+	out := map[string]string{}
+	for _, k := range ownershipMetadata {
+		if v, ok := expected[k]; ok && existing[k] != v {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+func correctionFor(info *resource.Info, action CorrectionAction) ResourceCorrection {
+	c := ResourceCorrection{
+		GroupVersionKind: info.Object.GetObjectKind().GroupVersionKind(),
+		Namespace:        info.Namespace,
+		Name:             info.Name,
+		Action:           action,
+	}
+	if info.Mapping != nil {
+		c.GroupVersionKind = info.Mapping.GroupVersionKind
+	}
+	return c
+}
+
+// objectChanged returns true if the API server persisted a change to the
+// object between before and after. Changes that do not correct drift are
+// ignored: managedFields changes, such as the first apply of FieldManager or
+// fields ownership shared again after a Helm upgrade, status writes, and the
+// resourceVersion and generation they bump.
+func objectChanged(before, after runtime.Object) bool {
+	beforeContent, err := driftContent(before)
+	if err != nil {
+		return true
+	}
+	afterContent, err := driftContent(after)
+	if err != nil {
+		return true
+	}
+	return !equality.Semantic.DeepEqual(beforeContent, afterContent)
+}
+
+// driftContent returns a copy of the content of obj without its status and
+// the metadata updated by the API server.
+func driftContent(obj runtime.Object) (map[string]any, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	// The content is copied shallowly, since the content of unstructured
+	// objects is not copied by the converter.
+	out := make(map[string]any, len(content))
+	for k, v := range content {
+		if k != "status" {
+			out[k] = v
+		}
+	}
+	if metadata, ok := content["metadata"].(map[string]any); ok {
+		m := make(map[string]any, len(metadata))
+		for k, v := range metadata {
+			switch k {
+			case "managedFields", "resourceVersion", "generation":
+			default:
+				m[k] = v
+			}
+		}
+		out["metadata"] = m
+	}
+	return out, nil
+}
+
+// createApplyPatch returns the server-side apply configuration for the
+// expected object. The apply configuration must always carry the object's
+// apiVersion and kind, and must not set server-populated metadata.
+func createApplyPatch(expected *resource.Info) ([]byte, error) {
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(expected.Object)
+	if err != nil {
+		return nil, err
+	}
+	u := &unstructured.Unstructured{Object: obj}
+	if u.GetObjectKind().GroupVersionKind().Empty() && expected.Mapping != nil {
+		u.SetGroupVersionKind(expected.Mapping.GroupVersionKind)
+	}
+	unstructured.RemoveNestedField(u.Object, "metadata", "creationTimestamp")
+	unstructured.RemoveNestedField(u.Object, "metadata", "resourceVersion")
+	unstructured.RemoveNestedField(u.Object, "metadata", "managedFields")
+	return json.Marshal(u)
 }
 
 func createPatch(existing runtime.Object, expected *resource.Info) ([]byte, apitypes.PatchType, error) {
@@ -389,7 +898,50 @@ func createJSONMergePatch(existingJSON, expectedJSON []byte) ([]byte, error) {
 	return json.Marshal(patchOps)
 }
 
-// UninstallRelease performs a Helm release uninstall.
+// NotReadyResources returns the resources in manifest that are not ready yet,
+// using the same readiness checks as Helm's --wait option. Jobs are only
+// checked if waitForJobs is true. Resources that do not exist yet are
+// considered not ready.
+func (m manager) NotReadyResources(ctx context.Context, manifest string, waitForJobs bool) ([]string, error) {
+	clientSet, err := m.actionConfig.KubernetesClientSet()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Kubernetes client set: %w", err)
+	}
+	resources, err := m.kubeClient.Build(strings.NewReader(manifest), false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build resources from manifest: %w", err)
+	}
+	checker := kube.NewReadyChecker(clientSet, m.actionConfig.Log, kube.PausedAsReady(true), kube.CheckJobs(waitForJobs))
+
+	var notReady []string
+	for _, r := range resources {
+		ready, err := checker.IsReady(ctx, r)
+		if apierrors.IsNotFound(err) {
+			ready, err = false, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to check readiness of %s: %w", r.ObjectName(), err)
+		}
+		if !ready {
+			notReady = append(notReady, r.ObjectName())
+		}
+	}
+	return notReady, nil
+}
+
+// KeepHistory configures UninstallRelease to keep the release records in the
+// storage backend, marked as uninstalled, instead of deleting them.
+func KeepHistory(keep bool) UninstallOption {
+	return func(u *action.Uninstall) error {
+		u.KeepHistory = keep
+		return nil
+	}
+}
+
+// UninstallRelease performs a Helm release uninstall. A release that was
+// uninstalled while keeping its history is reported as not found when it is
+// uninstalled again while keeping its history, and its history is deleted
+// when it is uninstalled again without keeping its history.
 func (m manager) UninstallRelease(opts ...UninstallOption) (*rpb.Release, error) {
 	uninstall := action.NewUninstall(m.actionConfig)
 	for _, o := range opts {
@@ -397,6 +949,12 @@ func (m manager) UninstallRelease(opts ...UninstallOption) (*rpb.Release, error)
 			return nil, fmt.Errorf("failed to apply uninstall option: %w", err)
 		}
 	}
+	if uninstall.KeepHistory {
+		last, err := m.storageBackend.Last(m.releaseName)
+		if err == nil && last.Info != nil && last.Info.Status == rpb.StatusUninstalled {
+			return nil, driver.ErrReleaseNotFound
+		}
+	}
 	uninstallResponse, err := uninstall.Run(m.releaseName)
 	if uninstallResponse == nil {
 		return nil, err
@@ -15,9 +15,13 @@
 package release
 
 import (
+	"context"
 	"fmt"
+	"sync"
 
+	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/postrender"
 	helmrelease "helm.sh/helm/v3/pkg/release"
 	"helm.sh/helm/v3/pkg/storage"
 	"helm.sh/helm/v3/pkg/strvals"
@@ -25,116 +29,294 @@ import (
 	crmanager "sigs.k8s.io/controller-runtime/pkg/manager"
 
 	"github.com/operator-framework/operator-sdk/internal/helm/client"
+	"github.com/operator-framework/operator-sdk/internal/helm/images"
 	"github.com/operator-framework/operator-sdk/internal/helm/internal/types"
+	"github.com/operator-framework/operator-sdk/internal/helm/tracing"
 )
 
+// ChartLabel is the label set on release resources to the name of the chart
+// of their release.
+const ChartLabel = "helm.sdk.operatorframework.io/chart"
+
+// OwnerUIDLabel is the label of release records set to the UID of the custom
+// resource that installed or last upgraded the release.
+const OwnerUIDLabel = "helm.sdk.operatorframework.io/owner-uid"
+
 // ManagerFactory creates Managers that are specific to custom resources. It is
 // used by the HelmOperatorReconciler during resource reconciliation, and it
 // improves decoupling between reconciliation logic and the Helm backend
 // components used to manage releases.
+//
+// The chart is loaded once and cached by the factory until ReloadChart is
+// called.
 type ManagerFactory interface {
-	NewManager(r *unstructured.Unstructured, overrideValues map[string]string, dryRunOption string) (Manager, error)
+	NewManager(ctx context.Context, r *unstructured.Unstructured, overrideValues map[string]string, dryRunOption string) (Manager, error)
+	ReloadChart(chartDir string) error
+}
+
+// ValuesTransformer derives the values used to render the chart of a custom
+// resource from the values in its spec. namespace is the namespace of the
+// release of the custom resource.
+type ValuesTransformer interface {
+	TransformValues(ctx context.Context, cr *unstructured.Unstructured, namespace string, spec map[string]any) (map[string]any, error)
+}
+
+// ManagerFactoryOption configures a ManagerFactory.
+type ManagerFactoryOption func(*managerFactory)
+
+// WithValuesTransformer configures the factory to derive release values from
+// custom resources with t instead of using their spec as is.
+func WithValuesTransformer(t ValuesTransformer) ManagerFactoryOption {
+	return func(f *managerFactory) {
+		f.valuesTransformer = t
+	}
+}
+
+// WithMaxHistory configures the managers created by the factory to keep at
+// most maxHistory revisions of each release. A maxHistory of 0 keeps all
+// revisions. By default, only the deployed revision is kept.
+func WithMaxHistory(maxHistory int) ManagerFactoryOption {
+	return func(f *managerFactory) {
+		f.maxHistory = maxHistory
+	}
+}
+
+// WithReleaseNaming configures the names and namespaces of the releases of
+// custom resources.
+func WithReleaseNaming(n types.ReleaseNaming) ManagerFactoryOption {
+	return func(f *managerFactory) {
+		f.releaseNaming = n
+	}
+}
+
+// WithPostRenderer configures the managers created by the factory to patch
+// the manifests of releases with pr when they are installed, upgraded or
+// rendered with a dry-run, so that the post-rendered manifests are compared
+// to detect changes.
+func WithPostRenderer(pr postrender.PostRenderer) ManagerFactoryOption {
+	return func(f *managerFactory) {
+		f.postRenderer = pr
+	}
+}
+
+// WithImageRewriter configures the managers created by the factory to
+// rewrite the images of the resources of releases with r when they are
+// applied, so that they are pulled from mirrors and pinned to digests.
+func WithImageRewriter(r *images.Rewriter) ManagerFactoryOption {
+	return func(f *managerFactory) {
+		f.imageRewriter = r
+	}
 }
 
 type managerFactory struct {
-	mgr      crmanager.Manager
-	acg      client.ActionConfigGetter
+	mgr               crmanager.Manager
+	acg               client.ActionConfigGetter
+	valuesTransformer ValuesTransformer
+	maxHistory        int
+	releaseNaming     types.ReleaseNaming
+	postRenderer      postrender.PostRenderer
+	imageRewriter     *images.Rewriter
+
+	mu       sync.RWMutex
 	chartDir string
+	chart    *chart.Chart
 }
 
 // NewManagerFactory returns a new Helm manager factory capable of installing and uninstalling releases.
-func NewManagerFactory(mgr crmanager.Manager, acg client.ActionConfigGetter, chartDir string) ManagerFactory {
-	return &managerFactory{mgr, acg, chartDir}
+func NewManagerFactory(mgr crmanager.Manager, acg client.ActionConfigGetter, chartDir string, opts ...ManagerFactoryOption) ManagerFactory {
+	f := &managerFactory{mgr: mgr, acg: acg, chartDir: chartDir, maxHistory: 1}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
 }
 
-func (f managerFactory) NewManager(cr *unstructured.Unstructured, overrideValues map[string]string, dryRunOption string) (Manager, error) {
-	actionConfig, err := f.acg.ActionConfigFor(cr)
+func (f *managerFactory) NewManager(ctx context.Context, cr *unstructured.Unstructured, overrideValues map[string]string, dryRunOption string) (Manager, error) {
+	namespace, err := f.releaseNaming.NamespaceFor(cr)
+	if err != nil {
+		return nil, err
+	}
+	releaseName, err := f.releaseNaming.NameFor(cr)
+	if err != nil {
+		return nil, err
+	}
+	actionConfig, err := f.acg.ActionConfigFor(cr, namespace)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get helm action config: %w", err)
 	}
 
-	crChart, err := loader.LoadDir(f.chartDir)
+	_, span := tracing.Start(ctx, "LoadChart")
+	crChart, err := f.getChart()
+	tracing.End(span, err)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load chart dir: %w", err)
+		return nil, err
 	}
 
 	actionConfig.KubeClient = client.NewLabelInjectingClient(actionConfig.KubeClient, map[string]string{
-		"helm.sdk.operatorframework.io/chart": crChart.Name(),
+		ChartLabel: crChart.Name(),
 	})
 
-	releaseName, err := getReleaseName(actionConfig.Releases, crChart.Name(), cr)
-	if err != nil {
+	if err := checkReleaseChart(actionConfig.Releases, crChart.Name(), releaseName); err != nil {
 		return nil, fmt.Errorf("failed to get helm release name: %w", err)
 	}
 
-	crValues, ok := cr.Object["spec"].(map[string]any)
-	if !ok {
-		return nil, fmt.Errorf("failed to get spec: expected map[string]interface{}")
-	}
-
-	expOverrides, err := parseOverrides(overrideValues)
+	ctx, span = tracing.Start(ctx, "MergeValues", tracing.AttributeRelease.String(releaseName))
+	values, err := f.mergeValues(ctx, cr, namespace, overrideValues)
+	tracing.End(span, err)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse override values: %w", err)
+		return nil, err
 	}
-	values := mergeMaps(crValues, expOverrides)
 
-	return &manager{
+	status := types.StatusFor(cr)
+	m := &manager{
 		actionConfig:   actionConfig,
 		storageBackend: actionConfig.Releases,
 		kubeClient:     actionConfig.KubeClient,
 
+		gvk:         cr.GroupVersionKind(),
+		ownerUID:    cr.GetUID(),
 		releaseName: releaseName,
-		namespace:   cr.GetNamespace(),
+		namespace:   namespace,
 
 		chart:        crChart,
+		subcharts:    chartDependencies(crChart),
 		values:       values,
-		status:       types.StatusFor(cr),
+		status:       status,
 		dryRunOption: dryRunOption,
-	}, nil
+		maxHistory:   f.maxHistory,
+		postRenderer: f.postRenderer,
+
+		imageRewriter: f.imageRewriter,
+	}
+	if f.imageRewriter != nil {
+		m.imagePins = images.Pins{}
+		for _, image := range status.Images {
+			m.imagePins[image.Source] = image.Image
+		}
+		m.appliedImages = images.Pins{}
+		actionConfig.KubeClient = client.NewImageRewritingClient(ctx, actionConfig.KubeClient, f.imageRewriter,
+			m.pinnedImages, m.appliedImages)
+		m.kubeClient = actionConfig.KubeClient
+	}
+	return m, nil
 }
 
-// getReleaseName returns a release name for the CR.
-//
-// getReleaseName searches for a release using the CR name. If a release
-// cannot be found, or if it is found and was created by the chart managed
-// by this manager, the CR name is returned.
-//
-// If a release is found but it was created by another chart, that means we
-// have a release name collision, so return an error. This case is possible
-// because Kubernetes allows instances of different types to have the same name
-// in the same namespace.
+// ReloadChart loads the chart in chartDir and replaces the cached chart with
+// it. The cached chart is kept if the new chart cannot be loaded. Since the
+// chart name is used to select the resources cached by the operator, a chart
+// with a different name is rejected.
+func (f *managerFactory) ReloadChart(chartDir string) error {
+	newChart, err := loader.LoadDir(chartDir)
+	if err != nil {
+		return fmt.Errorf("failed to load chart dir: %w", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.chart != nil && f.chart.Name() != newChart.Name() {
+		return fmt.Errorf("chart name changed from %q to %q: the operator must be restarted to use the new chart",
+			f.chart.Name(), newChart.Name())
+	}
+	f.chartDir = chartDir
+	f.chart = newChart
+	return nil
+}
+
+// mergeValues returns the values of the release of cr: its spec, transformed
+// by the values transformer if any, with overrideValues merged into it.
+func (f *managerFactory) mergeValues(ctx context.Context, cr *unstructured.Unstructured, namespace string,
+	overrideValues map[string]string) (map[string]any, error) {
+	crValues, ok := cr.Object["spec"].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("failed to get spec: expected map[string]interface{}")
+	}
+	if f.valuesTransformer != nil {
+		var err error
+		crValues, err = f.valuesTransformer.TransformValues(ctx, cr, namespace, crValues)
+		if err != nil {
+			return nil, fmt.Errorf("failed to transform values: %w", err)
+		}
+	}
+
+	expOverrides, err := parseOverrides(overrideValues)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse override values: %w", err)
+	}
+	return mergeMaps(crValues, expOverrides), nil
+}
+
+// getChart returns a copy of the cached chart, loading it on first use.
+func (f *managerFactory) getChart() (*chart.Chart, error) {
+	f.mu.RLock()
+	cached := f.chart
+	f.mu.RUnlock()
+	if cached != nil {
+		return copyChart(cached), nil
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.chart == nil {
+		loaded, err := loader.LoadDir(f.chartDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load chart dir: %w", err)
+		}
+		f.chart = loaded
+	}
+	return copyChart(f.chart), nil
+}
+
+// copyChart returns a copy of c that can be used by a single release. Helm
+// modifies the values, dependency metadata and subcharts of a chart while
+// processing the values of a release, so these are copied; templates and
+// files are never modified and are shared with c.
+func copyChart(c *chart.Chart) *chart.Chart {
+	out := *c
+	if c.Metadata != nil {
+		metadata := *c.Metadata
+		metadata.Dependencies = nil
+		for _, d := range c.Metadata.Dependencies {
+			dep := *d
+			metadata.Dependencies = append(metadata.Dependencies, &dep)
+		}
+		out.Metadata = &metadata
+	}
+	deps := make([]*chart.Chart, 0, len(c.Dependencies()))
+	for _, d := range c.Dependencies() {
+		deps = append(deps, copyChart(d))
+	}
+	out.SetDependencies(deps...)
+	return &out
+}
+
+// checkReleaseChart returns an error if a release named releaseName exists
+// and was created by another chart than the chart managed by this manager.
 //
-// TODO(jlanford): As noted above, using the CR name as the release name raises
+// That means we have a release name collision, which is possible because
+// Kubernetes allows instances of different types to have the same name in
+// the same namespace, and release names may be rendered from templates.
 //
-//	the possibility of collision. We should move this logic to a validating
-//	admission webhook so that the CR owner receives immediate feedback of the
-//	collision. As is, the only indication of collision will be in the CR status
-//	and operator logs.
-func getReleaseName(storageBackend *storage.Storage, crChartName string,
-	cr *unstructured.Unstructured) (string, error) {
-	// If a release with the CR name does not exist, return the CR name.
-	releaseName := cr.GetName()
+// When the validating admission webhook is enabled, it runs this check so
+// that the CR owner receives immediate feedback of the collision. Otherwise,
+// the only indication of collision is in the CR status and operator logs.
+func checkReleaseChart(storageBackend *storage.Storage, crChartName, releaseName string) error {
 	history, exists, err := releaseHistory(storageBackend, releaseName)
-	if err != nil {
-		return "", err
-	}
-	if !exists {
-		return releaseName, nil
+	if err != nil || !exists {
+		return err
 	}
 
-	// If a release name with the CR name exists, but the release's chart is
-	// different than the chart managed by this operator, return an error
-	// because something else created the existing release.
+	// If a release with the name exists, but the release's chart is different
+	// than the chart managed by this operator, return an error because
+	// something else created the existing release.
 	if history[0].Chart == nil {
-		return "", fmt.Errorf("could not find chart metadata in release with name %q", releaseName)
+		return fmt.Errorf("could not find chart metadata in release with name %q", releaseName)
 	}
 	existingChartName := history[0].Chart.Name()
 	if existingChartName != crChartName {
-		return "", fmt.Errorf("duplicate release name: found existing release with name %q for chart %q",
+		return fmt.Errorf("duplicate release name: found existing release with name %q for chart %q",
 			releaseName, existingChartName)
 	}
-
-	return releaseName, nil
+	return nil
 }
 
 func releaseHistory(storageBackend *storage.Storage, releaseName string) ([]*helmrelease.Release, bool, error) {
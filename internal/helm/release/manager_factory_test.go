@@ -0,0 +1,94 @@
+// Copyright 2026 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package release
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+const testChartArchive = "../../plugins/helm/v1/chartutil/testdata/test-chart-1.2.3.tgz"
+
+func expandTestChart(t *testing.T) string {
+	dir := t.TempDir()
+	require.NoError(t, chartutil.ExpandFile(dir, testChartArchive))
+	return filepath.Join(dir, "test-chart")
+}
+
+func TestManagerFactoryReloadChart(t *testing.T) {
+	chartDir := expandTestChart(t)
+	f := &managerFactory{chartDir: chartDir}
+
+	c, err := f.getChart()
+	require.NoError(t, err)
+	assert.Equal(t, "1.2.3", c.Metadata.Version)
+
+	// The cached chart is used until the chart is reloaded.
+	chartFile := filepath.Join(chartDir, chartutil.ChartfileName)
+	require.NoError(t, chartutil.SaveChartfile(chartFile, &chart.Metadata{APIVersion: "v2", Name: "test-chart", Version: "1.2.4"}))
+	c, err = f.getChart()
+	require.NoError(t, err)
+	assert.Equal(t, "1.2.3", c.Metadata.Version)
+
+	require.NoError(t, f.ReloadChart(chartDir))
+	c, err = f.getChart()
+	require.NoError(t, err)
+	assert.Equal(t, "1.2.4", c.Metadata.Version)
+
+	// A chart with another name is rejected and the cached chart is kept.
+	require.NoError(t, chartutil.SaveChartfile(chartFile, &chart.Metadata{APIVersion: "v2", Name: "other-chart", Version: "1.2.5"}))
+	assert.ErrorContains(t, f.ReloadChart(chartDir), "chart name changed")
+
+	// A chart that cannot be loaded is rejected and the cached chart is kept.
+	require.NoError(t, os.Remove(chartFile))
+	assert.Error(t, f.ReloadChart(chartDir))
+
+	c, err = f.getChart()
+	require.NoError(t, err)
+	assert.Equal(t, "1.2.4", c.Metadata.Version)
+}
+
+func TestCopyChart(t *testing.T) {
+	sub := &chart.Chart{Metadata: &chart.Metadata{APIVersion: "v2", Name: "sub", Version: "0.1.0"}}
+	parent := &chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: "v2",
+			Name:       "parent",
+			Version:    "0.1.0",
+			Dependencies: []*chart.Dependency{
+				{Name: "sub", Version: "0.1.0", Condition: "sub.enabled"},
+			},
+		},
+		Values: map[string]any{"sub": map[string]any{"enabled": true}},
+	}
+	parent.SetDependencies(sub)
+
+	c := copyChart(parent)
+	require.NoError(t, chartutil.ProcessDependencies(c, map[string]any{"sub": map[string]any{"enabled": false}}))
+	assert.Empty(t, c.Dependencies())
+	assert.Empty(t, c.Metadata.Dependencies)
+
+	// Processing the values of the copy does not affect the original chart.
+	assert.Len(t, parent.Dependencies(), 1)
+	assert.Equal(t, parent, parent.Dependencies()[0].Parent())
+	assert.Len(t, parent.Metadata.Dependencies, 1)
+	assert.Equal(t, map[string]any{"sub": map[string]any{"enabled": true}}, parent.Values)
+}
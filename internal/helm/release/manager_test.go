@@ -15,9 +15,17 @@
 package release
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
 	"testing"
 
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	apitypes "k8s.io/apimachinery/pkg/types"
 	"k8s.io/cli-runtime/pkg/resource"
 
@@ -26,6 +34,14 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/action"
+	cpb "helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	rpb "helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage"
+	"helm.sh/helm/v3/pkg/storage/driver"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -213,3 +229,378 @@ func TestManagerGenerateStrategicMergePatch(t *testing.T) {
 		assert.Equal(t, test.patch, string(diff))
 	}
 }
+
+func TestManagerCreateApplyPatch(t *testing.T) {
+	deploymentMapping := &meta.RESTMapping{
+		GroupVersionKind: schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+	}
+
+	tests := []struct {
+		name  string
+		info  *resource.Info
+		patch string
+	}{
+		{
+			name: "typed object",
+			info: &resource.Info{
+				Object:  newTestDeployment([]v1.Container{{Name: "test1"}}),
+				Mapping: deploymentMapping,
+			},
+			patch: `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"test","namespace":"ns"},` +
+				`"spec":{"selector":null,"strategy":{},"template":{"metadata":{"creationTimestamp":null},"spec":{"containers":[{"name":"test1","resources":{}}]}}},"status":{}}`,
+		},
+		{
+			name: "typed object without type meta",
+			info: &resource.Info{
+				Object: &appsv1.Deployment{
+					ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "ns", ResourceVersion: "1"},
+				},
+				Mapping: deploymentMapping,
+			},
+			patch: `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"test","namespace":"ns"},` +
+				`"spec":{"selector":null,"strategy":{},"template":{"metadata":{"creationTimestamp":null},"spec":{"containers":null}}},"status":{}}`,
+		},
+		{
+			name: "unstructured object",
+			info: &resource.Info{
+				Object: newTestUnstructured([]any{
+					map[string]any{
+						"name": "test1",
+					},
+				}),
+			},
+			patch: `{"apiVersion":"myApi","kind":"MyResource","metadata":{"name":"test","namespace":"ns"},` +
+				`"spec":{"template":{"spec":{"containers":[{"name":"test1"}]}}}}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			patch, err := createApplyPatch(test.info)
+			assert.NoError(t, err)
+			assert.JSONEq(t, test.patch, string(patch))
+		})
+	}
+}
+
+func TestApplyConflictError(t *testing.T) {
+	err := error(&ApplyConflictError{Conflicts: []string{"Deployment ns/a: conflict", "Service ns/b: conflict"}})
+	assert.Equal(t, "field ownership conflicts: Deployment ns/a: conflict; Service ns/b: conflict", err.Error())
+
+	var conflictErr *ApplyConflictError
+	assert.True(t, errors.As(fmt.Errorf("wrapped: %w", err), &conflictErr))
+	assert.Len(t, conflictErr.Conflicts, 2)
+}
+
+func TestObjectChanged(t *testing.T) {
+	newDeployment := func(resourceVersion string, replicas int64, managers ...string) *unstructured.Unstructured {
+		managedFields := []any{}
+		for _, m := range managers {
+			managedFields = append(managedFields, map[string]any{"manager": m, "operation": "Apply"})
+		}
+		return &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata": map[string]any{
+				"name":            "test",
+				"resourceVersion": resourceVersion,
+				"generation":      int64(1),
+				"managedFields":   managedFields,
+			},
+			"spec":   map[string]any{"replicas": replicas},
+			"status": map[string]any{"readyReplicas": int64(0)},
+		}}
+	}
+
+	before := newDeployment("1", 2, "helm")
+	// A managedFields-only change, such as the first apply of the field
+	// manager of the operator, is not drift.
+	assert.False(t, objectChanged(before, newDeployment("2", 2, "helm", FieldManager)))
+
+	// A status write bumps the resource version, but is not drift either.
+	afterStatus := newDeployment("3", 2, "helm")
+	afterStatus.Object["status"] = map[string]any{"readyReplicas": int64(2)}
+	assert.False(t, objectChanged(before, afterStatus))
+
+	afterSpec := newDeployment("4", 3, "helm")
+	afterSpec.SetGeneration(2)
+	assert.True(t, objectChanged(before, afterSpec))
+
+	afterLabels := newDeployment("5", 2, "helm")
+	afterLabels.SetLabels(map[string]string{"app": "test"})
+	assert.True(t, objectChanged(before, afterLabels))
+
+	// The compared objects are not modified.
+	assert.Equal(t, "1", before.GetResourceVersion())
+	assert.Contains(t, before.Object, "status")
+}
+
+func TestManagerPruneHistory(t *testing.T) {
+	newStorage := func(statuses ...rpb.Status) *storage.Storage {
+		s := storage.Init(driver.NewMemory())
+		for i, status := range statuses {
+			require.NoError(t, s.Create(&rpb.Release{Name: "test", Version: i + 1, Info: &rpb.Info{Status: status}}))
+		}
+		return s
+	}
+	revisions := func(t *testing.T, s *storage.Storage) []int {
+		releases, err := s.History("test")
+		if !notFoundErr(err) {
+			require.NoError(t, err)
+		}
+		var versions []int
+		for _, rel := range releases {
+			versions = append(versions, rel.Version)
+		}
+		return versions
+	}
+
+	tests := []struct {
+		name       string
+		maxHistory int
+		statuses   []rpb.Status
+		expect     []int
+	}{
+		{
+			name:       "only the deployed revision is kept by default",
+			maxHistory: 1,
+			statuses:   []rpb.Status{rpb.StatusSuperseded, rpb.StatusDeployed, rpb.StatusFailed},
+			expect:     []int{2},
+		},
+		{
+			name:       "the most recent revisions are kept",
+			maxHistory: 3,
+			statuses:   []rpb.Status{rpb.StatusSuperseded, rpb.StatusSuperseded, rpb.StatusDeployed, rpb.StatusFailed},
+			expect:     []int{2, 3, 4},
+		},
+		{
+			name:       "pending revisions are deleted",
+			maxHistory: 0,
+			statuses:   []rpb.Status{rpb.StatusSuperseded, rpb.StatusDeployed, rpb.StatusPendingUpgrade},
+			expect:     []int{1, 2},
+		},
+		{
+			name:       "all revisions are deleted if none is deployed",
+			maxHistory: 0,
+			statuses:   []rpb.Status{rpb.StatusSuperseded, rpb.StatusFailed},
+			expect:     nil,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := newStorage(tc.statuses...)
+			m := manager{storageBackend: s, releaseName: "test", maxHistory: tc.maxHistory}
+			releases, err := s.History("test")
+			require.NoError(t, err)
+			require.NoError(t, m.pruneHistory(releases))
+			assert.ElementsMatch(t, tc.expect, revisions(t, s))
+		})
+	}
+}
+
+func TestManagerDryRunRelease(t *testing.T) {
+	testChart := &cpb.Chart{
+		Metadata: &cpb.Metadata{APIVersion: cpb.APIVersionV2, Name: "test", Version: "0.1.0"},
+		Templates: []*cpb.File{{
+			Name: "templates/configmap.yaml",
+			Data: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: test\n" +
+				"data:\n  replicas: \"{{ required \"replicas is required\" .Values.replicas }}\"\n"),
+		}},
+		Schema: []byte(`{"type": "object", "properties": {"replicas": {"type": "integer"}}}`),
+	}
+	newManager := func(values map[string]any, installed bool) manager {
+		s := storage.Init(driver.NewMemory())
+		if installed {
+			require.NoError(t, s.Create(&rpb.Release{Name: "test", Namespace: "default", Version: 1,
+				Chart: testChart, Info: &rpb.Info{Status: rpb.StatusDeployed}}))
+		}
+		return manager{
+			actionConfig: &action.Configuration{
+				Releases:     s,
+				KubeClient:   &kubefake.PrintingKubeClient{Out: io.Discard},
+				Capabilities: chartutil.DefaultCapabilities,
+				Log:          func(string, ...any) {},
+			},
+			storageBackend: s,
+			releaseName:    "test",
+			namespace:      "default",
+			chart:          testChart,
+			values:         values,
+		}
+	}
+
+	rel, err := newManager(map[string]any{"replicas": 2}, false).DryRunRelease(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, 1, rel.Version)
+	assert.Contains(t, rel.Manifest, `replicas: "2"`)
+
+	rel, err = newManager(map[string]any{"replicas": 3}, true).DryRunRelease(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, 2, rel.Version)
+	assert.Contains(t, rel.Manifest, `replicas: "3"`)
+
+	_, err = newManager(map[string]any{}, false).DryRunRelease(context.TODO())
+	assert.ErrorContains(t, err, "replicas is required")
+	_, err = newManager(map[string]any{"replicas": "two"}, true).DryRunRelease(context.TODO())
+	assert.ErrorContains(t, err, "values don't meet the specifications of the schema")
+
+	// Dry-runs are post-rendered, so that their manifests are comparable with
+	// the manifests of installed and upgraded releases.
+	for _, installed := range []bool{false, true} {
+		m := newManager(map[string]any{"replicas": 4}, installed)
+		m.postRenderer = postRendererFunc(func(in *bytes.Buffer) (*bytes.Buffer, error) {
+			return bytes.NewBufferString(strings.ReplaceAll(in.String(), "name: test", "name: patched")), nil
+		})
+		rel, err = m.DryRunRelease(context.TODO())
+		require.NoError(t, err)
+		assert.Contains(t, rel.Manifest, "name: patched")
+	}
+}
+
+func TestManagerReleaseOwner(t *testing.T) {
+	testChart := &cpb.Chart{
+		Metadata: &cpb.Metadata{APIVersion: cpb.APIVersionV2, Name: "test", Version: "0.1.0"},
+		Templates: []*cpb.File{{
+			Name: "templates/configmap.yaml",
+			Data: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: test\n"),
+		}},
+	}
+	s := storage.Init(driver.NewMemory())
+	newManager := func(owner apitypes.UID) manager {
+		return manager{
+			actionConfig: &action.Configuration{
+				Releases:     s,
+				KubeClient:   &kubefake.PrintingKubeClient{Out: io.Discard},
+				Capabilities: chartutil.DefaultCapabilities,
+				Log:          func(string, ...any) {},
+			},
+			storageBackend: s,
+			ownerUID:       owner,
+			releaseName:    "test",
+			namespace:      "default",
+			chart:          testChart,
+			values:         map[string]any{},
+		}
+	}
+
+	owner, err := newManager("a").ReleaseOwner()
+	require.NoError(t, err)
+	assert.Empty(t, owner)
+
+	_, err = newManager("a").InstallRelease()
+	require.NoError(t, err)
+	owner, err = newManager("b").ReleaseOwner()
+	require.NoError(t, err)
+	assert.Equal(t, apitypes.UID("a"), owner)
+
+	// The owner recorded by the last upgrade takes precedence.
+	_, _, err = newManager("b").UpgradeRelease()
+	require.NoError(t, err)
+	owner, err = newManager("a").ReleaseOwner()
+	require.NoError(t, err)
+	assert.Equal(t, apitypes.UID("b"), owner)
+}
+
+func TestManagerAdoptReleaseOwner(t *testing.T) {
+	testChart := &cpb.Chart{
+		Metadata: &cpb.Metadata{APIVersion: cpb.APIVersionV2, Name: "test", Version: "0.1.0"},
+		Templates: []*cpb.File{{
+			Name: "templates/configmap.yaml",
+			Data: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: test\n"),
+		}},
+	}
+	s := storage.Init(driver.NewMemory())
+	newManager := func(owner apitypes.UID) manager {
+		return manager{
+			actionConfig: &action.Configuration{
+				Releases:     s,
+				KubeClient:   &kubefake.PrintingKubeClient{Out: io.Discard},
+				Capabilities: chartutil.DefaultCapabilities,
+				Log:          func(string, ...any) {},
+			},
+			storageBackend: s,
+			kubeClient:     &kubefake.PrintingKubeClient{Out: io.Discard},
+			ownerUID:       owner,
+			releaseName:    "test",
+			namespace:      "default",
+			chart:          testChart,
+			values:         map[string]any{},
+		}
+	}
+
+	// A release installed by another client has no owner.
+	_, err := newManager("").InstallRelease()
+	require.NoError(t, err)
+	_, _, err = newManager("").UpgradeRelease()
+	require.NoError(t, err)
+	owner, err := newManager("a").ReleaseOwner()
+	require.NoError(t, err)
+	assert.Empty(t, owner)
+
+	m := newManager("a")
+	m.deployedRelease, err = s.Deployed("test")
+	require.NoError(t, err)
+	_, err = m.AdoptRelease(context.TODO())
+	require.NoError(t, err)
+	owner, err = newManager("b").ReleaseOwner()
+	require.NoError(t, err)
+	assert.Equal(t, apitypes.UID("a"), owner)
+	history, err := s.History("test")
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	for _, rel := range history {
+		assert.Equal(t, "a", rel.Labels[OwnerUIDLabel])
+	}
+}
+
+type postRendererFunc func(*bytes.Buffer) (*bytes.Buffer, error)
+
+func (f postRendererFunc) Run(in *bytes.Buffer) (*bytes.Buffer, error) {
+	return f(in)
+}
+
+func TestCreateOwnershipPatch(t *testing.T) {
+	isController := true
+	owner := metav1.OwnerReference{APIVersion: "example.com/v1", Kind: "Nginx", Name: "test", UID: "uid", Controller: &isController}
+	other := metav1.OwnerReference{APIVersion: "example.com/v1", Kind: "Nginx", Name: "other", UID: "other-uid", Controller: &isController}
+	newDeployment := func(refs []metav1.OwnerReference, labels, annotations map[string]string) *appsv1.Deployment {
+		d := newTestDeployment(nil)
+		d.OwnerReferences = refs
+		d.Labels = labels
+		d.Annotations = annotations
+		return d
+	}
+	expected := newDeployment([]metav1.OwnerReference{owner},
+		map[string]string{ChartLabel: "nginx", "app": "nginx"}, nil)
+
+	// Ownership is added without changing other metadata.
+	patch, err := createOwnershipPatch(newDeployment(nil, map[string]string{"app": "web"}, nil), expected)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"metadata":{"labels":{"helm.sdk.operatorframework.io/chart":"nginx"},"ownerReferences":[`+
+		`{"apiVersion":"example.com/v1","kind":"Nginx","name":"test","uid":"uid","controller":true}]}}`, string(patch))
+
+	// Existing non-controller owner references are kept.
+	shared := metav1.OwnerReference{APIVersion: "v1", Kind: "ConfigMap", Name: "shared", UID: "cm-uid"}
+	patch, err = createOwnershipPatch(newDeployment([]metav1.OwnerReference{shared}, nil, nil), expected)
+	require.NoError(t, err)
+	assert.Contains(t, string(patch), `"uid":"cm-uid"`)
+	assert.Contains(t, string(patch), `"uid":"uid"`)
+
+	// Nothing is patched if the resource is already owned.
+	patch, err = createOwnershipPatch(newDeployment([]metav1.OwnerReference{owner},
+		map[string]string{ChartLabel: "nginx"}, nil), expected)
+	require.NoError(t, err)
+	assert.Nil(t, patch)
+
+	// Resources that are not owned get owner annotations instead.
+	annotated := newDeployment(nil, map[string]string{ChartLabel: "nginx"},
+		map[string]string{"operator-sdk/primary-resource": "default/test", "operator-sdk/primary-resource-type": "Nginx.example.com"})
+	patch, err = createOwnershipPatch(newDeployment(nil, nil, map[string]string{"helm.sh/resource-policy": "keep"}), annotated)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"metadata":{"labels":{"helm.sdk.operatorframework.io/chart":"nginx"},"annotations":{`+
+		`"operator-sdk/primary-resource":"default/test","operator-sdk/primary-resource-type":"Nginx.example.com"}}}`,
+		string(patch))
+
+	_, err = createOwnershipPatch(newDeployment([]metav1.OwnerReference{other}, nil, nil), expected)
+	assert.EqualError(t, err, "already controlled by Nginx other")
+}
@@ -0,0 +1,90 @@
+// Copyright 2026 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package release
+
+import (
+	"slices"
+
+	cpb "helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	rpb "helm.sh/helm/v3/pkg/release"
+
+	"github.com/operator-framework/operator-sdk/internal/helm/internal/types"
+)
+
+// chartDependencies returns a copy of the dependencies declared by c. Helm
+// removes the dependencies disabled by their condition or tags from the
+// metadata of a chart while processing the values of a release, so they are
+// copied before the chart is used.
+func chartDependencies(c *cpb.Chart) []cpb.Dependency {
+	if c.Metadata == nil {
+		return nil
+	}
+	deps := make([]cpb.Dependency, 0, len(c.Metadata.Dependencies))
+	for _, d := range c.Metadata.Dependencies {
+		deps = append(deps, *d)
+	}
+	return deps
+}
+
+// Subcharts returns the subcharts declared by the chart of the manager, and
+// whether they are enabled in rel, the deployed revision of the release. The
+// subcharts of the chart of a release are not kept in the release records, so
+// they are derived from its metadata, which only contains the enabled
+// dependencies, named after their alias, and from its lock, which records the
+// versions of the dependencies.
+func (m manager) Subcharts(rel *rpb.Release) []types.HelmAppSubchart {
+	if len(m.subcharts) == 0 {
+		return nil
+	}
+	var enabled, locked []*cpb.Dependency
+	if rel != nil && rel.Chart != nil {
+		if rel.Chart.Metadata != nil {
+			enabled = rel.Chart.Metadata.Dependencies
+		}
+		if rel.Chart.Lock != nil {
+			locked = rel.Chart.Lock.Dependencies
+		}
+	}
+	subcharts := make([]types.HelmAppSubchart, 0, len(m.subcharts))
+	for _, d := range m.subcharts {
+		sub := types.HelmAppSubchart{Name: d.Name, Chart: d.Name}
+		if d.Alias != "" {
+			sub.Name = d.Alias
+		}
+		sub.Enabled = slices.ContainsFunc(enabled, func(e *cpb.Dependency) bool {
+			return e != nil && e.Name == sub.Name && e.Enabled
+		})
+		if sub.Enabled {
+			sub.Version = lockedVersion(locked, d)
+		}
+		subcharts = append(subcharts, sub)
+	}
+	return subcharts
+}
+
+// lockedVersion returns the version of dependency d recorded in the lock of a
+// chart, or an empty version if the chart has no lock.
+func lockedVersion(locked []*cpb.Dependency, d cpb.Dependency) string {
+	for _, l := range locked {
+		if l == nil || l.Name != d.Name {
+			continue
+		}
+		if d.Version == "" || chartutil.IsCompatibleRange(d.Version, l.Version) {
+			return l.Version
+		}
+	}
+	return ""
+}
@@ -0,0 +1,83 @@
+// Copyright 2026 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package release
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	rpb "helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/operator-framework/operator-sdk/internal/helm/internal/types"
+)
+
+func TestManagerSubcharts(t *testing.T) {
+	parent := &chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: "v2",
+			Name:       "parent",
+			Version:    "0.1.0",
+			Dependencies: []*chart.Dependency{
+				{Name: "sub", Version: "~0.1.0", Condition: "sub.enabled"},
+				{Name: "sub", Version: "~0.1.0", Alias: "extra", Tags: []string{"extra"}},
+			},
+		},
+		Lock: &chart.Lock{Dependencies: []*chart.Dependency{
+			{Name: "sub", Version: "0.1.2", Repository: "https://charts.example.com"},
+			{Name: "sub", Version: "0.1.2", Repository: "https://charts.example.com"},
+		}},
+	}
+	parent.SetDependencies(&chart.Chart{Metadata: &chart.Metadata{APIVersion: "v2", Name: "sub", Version: "0.1.2"}})
+
+	c := copyChart(parent)
+	m := manager{chart: c, subcharts: chartDependencies(c)}
+	require.NoError(t, chartutil.ProcessDependencies(c, map[string]any{
+		"sub":  map[string]any{"enabled": false},
+		"tags": map[string]any{"extra": true},
+	}))
+
+	// The subcharts of the chart of a release are not stored in its records.
+	secrets := driver.NewSecrets(fake.NewClientset().CoreV1().Secrets("default"))
+	require.NoError(t, secrets.Create("sh.helm.release.v1.test.v1", &rpb.Release{
+		Name: "test", Namespace: "default", Version: 1, Chart: c, Info: &rpb.Info{Status: rpb.StatusDeployed},
+	}))
+	rel, err := secrets.Get("sh.helm.release.v1.test.v1")
+	require.NoError(t, err)
+	require.Empty(t, rel.Chart.Dependencies())
+	assert.Equal(t, []types.HelmAppSubchart{
+		{Name: "sub", Chart: "sub"},
+		{Name: "extra", Chart: "sub", Version: "0.1.2", Enabled: true},
+	}, m.Subcharts(rel))
+
+	// Without a lock, the versions of subcharts are unknown.
+	rel.Chart.Lock = nil
+	assert.Equal(t, []types.HelmAppSubchart{
+		{Name: "sub", Chart: "sub"},
+		{Name: "extra", Chart: "sub", Enabled: true},
+	}, m.Subcharts(rel))
+
+	// Subcharts are disabled until the release is deployed.
+	assert.Equal(t, []types.HelmAppSubchart{
+		{Name: "sub", Chart: "sub"},
+		{Name: "extra", Chart: "sub"},
+	}, m.Subcharts(nil))
+
+	assert.Nil(t, manager{chart: &chart.Chart{Metadata: &chart.Metadata{Name: "standalone"}}}.Subcharts(nil))
+}
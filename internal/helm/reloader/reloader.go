@@ -0,0 +1,343 @@
+// Copyright 2026 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reloader
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/operator-framework/operator-sdk/internal/helm/chartsource"
+	"github.com/operator-framework/operator-sdk/internal/helm/release"
+	"github.com/operator-framework/operator-sdk/internal/helm/watches"
+)
+
+var log = logf.Log.WithName("helm.reloader")
+
+// DefaultDebounce is how long the Reloader waits for further changes to a
+// chart before reloading it, so that a chart being updated file by file is
+// only reloaded once.
+const DefaultDebounce = time.Second
+
+// Reloader watches the chart directories of the watches in a watches file,
+// and the watches file itself, for changes. When the chart of a watch
+// changes, the Reloader reloads the chart of the watch's ManagerFactory and
+// sends an event on the watch's channel so that every custom resource of the
+// watch's GVK is reconciled with the new chart.
+//
+// Changes to the watches file only take effect for the chart source of
+// existing watches; other changes require a restart of the operator.
+type Reloader struct {
+	// Debounce is how long to wait for further changes before reloading.
+	Debounce time.Duration
+
+	watchesFile string
+	fetcher     *chartsource.Fetcher
+	watchesData []byte
+	watches     map[schema.GroupVersionKind]watches.Watch
+	targets     map[schema.GroupVersionKind]*target
+}
+
+type target struct {
+	chartDir string
+	factory  release.ManagerFactory
+	events   chan event.GenericEvent
+}
+
+// New returns a Reloader for the watches in watchesFile. Remote charts
+// referenced by changed watches are fetched with fetcher.
+func New(watchesFile string, fetcher *chartsource.Fetcher) (*Reloader, error) {
+	watchesFile, err := filepath.Abs(watchesFile)
+	if err != nil {
+		return nil, err
+	}
+	data, ws, err := loadWatches(watchesFile)
+	if err != nil {
+		return nil, err
+	}
+	return &Reloader{
+		Debounce:    DefaultDebounce,
+		watchesFile: watchesFile,
+		fetcher:     fetcher,
+		watchesData: data,
+		watches:     ws,
+		targets:     map[schema.GroupVersionKind]*target{},
+	}, nil
+}
+
+// Add registers the ManagerFactory of the watch for gvk, whose chart is
+// currently loaded from chartDir. It returns a channel on which an event is
+// sent each time the chart is reloaded.
+func (r *Reloader) Add(gvk schema.GroupVersionKind, chartDir string, factory release.ManagerFactory) (<-chan event.GenericEvent, error) {
+	chartDir, err := filepath.Abs(chartDir)
+	if err != nil {
+		return nil, err
+	}
+	t := &target{
+		chartDir: chartDir,
+		factory:  factory,
+		events:   make(chan event.GenericEvent, 1),
+	}
+	r.targets[gvk] = t
+	return t.events, nil
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable. Charts are
+// reloaded by every replica, so that a replica becoming the leader uses the
+// current charts.
+func (r *Reloader) NeedLeaderElection() bool {
+	return false
+}
+
+// Start implements manager.Runnable. It watches for changes until ctx is
+// done.
+func (r *Reloader) Start(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create file watcher: %w", err)
+	}
+	defer func() {
+		if err := watcher.Close(); err != nil {
+			log.Error(err, "Failed to close file watcher")
+		}
+	}()
+
+	// Watch the directory of the watches file rather than the file itself,
+	// so that the file can be replaced, e.g. when it is mounted from a
+	// ConfigMap.
+	if err := watcher.Add(filepath.Dir(r.watchesFile)); err != nil {
+		return fmt.Errorf("failed to watch %s: %w", r.watchesFile, err)
+	}
+	for gvk, t := range r.targets {
+		if r.watches[gvk].IsRemoteChart() {
+			continue
+		}
+		if err := watchChartDir(watcher, t.chartDir); err != nil {
+			return fmt.Errorf("failed to watch chart directory %s: %w", t.chartDir, err)
+		}
+	}
+
+	var (
+		pending        = map[schema.GroupVersionKind]struct{}{}
+		watchesChanged bool
+		timer          *time.Timer
+		timerC         <-chan time.Time
+	)
+	for {
+		select {
+		case <-ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			return nil
+		case ev, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if ev.Op == fsnotify.Chmod {
+				continue
+			}
+			changed := false
+			if filepath.Dir(ev.Name) == filepath.Dir(r.watchesFile) {
+				watchesChanged, changed = true, true
+			}
+			for _, gvk := range r.targetsFor(ev.Name) {
+				pending[gvk], changed = struct{}{}, true
+			}
+			if !changed {
+				continue
+			}
+			if timer == nil {
+				timer = time.NewTimer(r.Debounce)
+				timerC = timer.C
+			} else {
+				timer.Reset(r.Debounce)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			log.Error(err, "File watcher error")
+		case <-timerC:
+			timer, timerC = nil, nil
+			if watchesChanged {
+				for _, gvk := range r.reloadWatches() {
+					pending[gvk] = struct{}{}
+				}
+				watchesChanged = false
+			}
+			for gvk := range pending {
+				r.reloadChart(watcher, gvk)
+				delete(pending, gvk)
+			}
+		}
+	}
+}
+
+// targetsFor returns the GVKs of the local charts containing path.
+func (r *Reloader) targetsFor(path string) []schema.GroupVersionKind {
+	var gvks []schema.GroupVersionKind
+	for gvk, t := range r.targets {
+		if r.watches[gvk].IsRemoteChart() {
+			continue
+		}
+		if path == t.chartDir || strings.HasPrefix(path, t.chartDir+string(filepath.Separator)) {
+			gvks = append(gvks, gvk)
+		}
+	}
+	return gvks
+}
+
+// reloadWatches reloads the watches file and returns the GVKs of the watches
+// whose chart source changed.
+func (r *Reloader) reloadWatches() []schema.GroupVersionKind {
+	data, ws, err := loadWatches(r.watchesFile)
+	if err != nil {
+		log.Error(err, "Failed to reload watches file", "path", r.watchesFile)
+		return nil
+	}
+	if bytes.Equal(data, r.watchesData) {
+		return nil
+	}
+	log.Info("Watches file changed", "path", r.watchesFile)
+
+	var changed []schema.GroupVersionKind
+	for gvk, t := range r.targets {
+		oldWatch := r.watches[gvk]
+		newWatch, ok := ws[gvk]
+		if !ok {
+			log.Info("Watch was removed from the watches file, restart the operator to stop watching it",
+				"apiVersion", gvk.GroupVersion(), "kind", gvk.Kind)
+			continue
+		}
+		if oldWatch.ChartSource() != newWatch.ChartSource() {
+			chartDir, err := r.resolveChartDir(newWatch)
+			if err != nil {
+				log.Error(err, "Failed to resolve chart", "apiVersion", gvk.GroupVersion(), "kind", gvk.Kind)
+				continue
+			}
+			t.chartDir = chartDir
+			changed = append(changed, gvk)
+		}
+		if !reflect.DeepEqual(withoutChartSource(oldWatch), withoutChartSource(newWatch)) {
+			log.Info("Watch options changed, restart the operator to apply changes other than to the chart",
+				"apiVersion", gvk.GroupVersion(), "kind", gvk.Kind)
+		}
+		r.watches[gvk] = newWatch
+	}
+	for gvk := range ws {
+		if _, ok := r.targets[gvk]; !ok {
+			log.Info("Watch was added to the watches file, restart the operator to start watching it",
+				"apiVersion", gvk.GroupVersion(), "kind", gvk.Kind)
+		}
+	}
+	r.watchesData = data
+	return changed
+}
+
+// withoutChartSource returns w with the fields identifying its chart cleared.
+func withoutChartSource(w watches.Watch) watches.Watch {
+	w.ChartDir, w.ChartRepo, w.ChartVersion, w.ChartDigest, w.ChartPullSecret = "", "", "", "", ""
+	w.ChartPlainHTTP = false
+	return w
+}
+
+// resolveChartDir returns the local chart directory of w, fetching remote
+// charts into the chart cache.
+func (r *Reloader) resolveChartDir(w watches.Watch) (string, error) {
+	if w.IsRemoteChart() {
+		return r.fetcher.Fetch(w.ChartSource())
+	}
+	return filepath.Abs(w.ChartDir)
+}
+
+// reloadChart reloads the chart of the watch for gvk and notifies its
+// controller.
+func (r *Reloader) reloadChart(watcher *fsnotify.Watcher, gvk schema.GroupVersionKind) {
+	t := r.targets[gvk]
+	if !r.watches[gvk].IsRemoteChart() {
+		// Directories may have been added to or replaced in the chart.
+		if err := watchChartDir(watcher, t.chartDir); err != nil {
+			log.Error(err, "Failed to watch chart directory", "path", t.chartDir)
+		}
+	}
+	chartDir, err := r.fetcher.ResolveDependencies(t.chartDir, r.watches[gvk].ChartSource())
+	if err != nil {
+		log.Error(err, "Failed to resolve chart dependencies, continuing with the previous chart",
+			"apiVersion", gvk.GroupVersion(), "kind", gvk.Kind, "path", t.chartDir)
+		return
+	}
+	if err := t.factory.ReloadChart(chartDir); err != nil {
+		log.Error(err, "Failed to reload chart, continuing with the previous chart",
+			"apiVersion", gvk.GroupVersion(), "kind", gvk.Kind, "path", chartDir)
+		return
+	}
+	log.Info("Reloaded chart", "apiVersion", gvk.GroupVersion(), "kind", gvk.Kind, "path", t.chartDir)
+
+	o := &unstructured.Unstructured{}
+	o.SetGroupVersionKind(gvk)
+	select {
+	case t.events <- event.GenericEvent{Object: o}:
+	default:
+		// A reload is already pending for the controller.
+	}
+}
+
+// watchChartDir adds every directory of the chart in dir, and the directory
+// containing it, to watcher. fsnotify does not watch directories recursively.
+func watchChartDir(watcher *fsnotify.Watcher, dir string) error {
+	if err := watcher.Add(filepath.Dir(dir)); err != nil {
+		return err
+	}
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		return watcher.Add(path)
+	})
+}
+
+// loadWatches loads the watches file, returning its contents and the
+// watches it contains by GVK.
+func loadWatches(path string) ([]byte, map[schema.GroupVersionKind]watches.Watch, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read watches file: %w", err)
+	}
+	ws, err := watches.LoadReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, err
+	}
+	byGVK := make(map[schema.GroupVersionKind]watches.Watch, len(ws))
+	for _, w := range ws {
+		byGVK[w.GroupVersionKind] = w
+	}
+	return data, byGVK, nil
+}
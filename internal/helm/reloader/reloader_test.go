@@ -0,0 +1,127 @@
+// Copyright 2026 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reloader
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/operator-framework/operator-sdk/internal/helm/chartsource"
+	"github.com/operator-framework/operator-sdk/internal/helm/release"
+)
+
+const testChartArchive = "../../plugins/helm/v1/chartutil/testdata/test-chart-1.2.3.tgz"
+
+type fakeFactory struct {
+	release.ManagerFactory
+	reloads chan string
+}
+
+func (f *fakeFactory) ReloadChart(chartDir string) error {
+	f.reloads <- chartDir
+	return nil
+}
+
+func expandTestChart(t *testing.T, dir string) string {
+	require.NoError(t, chartutil.ExpandFile(dir, testChartArchive))
+	return filepath.Join(dir, "test-chart")
+}
+
+func writeWatches(t *testing.T, path, chartDir string) {
+	data := fmt.Sprintf("- group: mygroup\n  version: v1alpha1\n  kind: MyKind\n  chart: %s\n", chartDir)
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o600))
+}
+
+func receive[T any](t *testing.T, ch <-chan T) T {
+	select {
+	case v := <-ch:
+		return v
+	case <-time.After(10 * time.Second):
+		require.FailNow(t, "timed out waiting for chart reload")
+	}
+	var zero T
+	return zero
+}
+
+func TestReloader(t *testing.T) {
+	gvk := schema.GroupVersionKind{Group: "mygroup", Version: "v1alpha1", Kind: "MyKind"}
+	chartDir := expandTestChart(t, t.TempDir())
+	otherChartDir := expandTestChart(t, t.TempDir())
+	watchesFile := filepath.Join(t.TempDir(), "watches.yaml")
+	writeWatches(t, watchesFile, chartDir)
+
+	r, err := New(watchesFile, chartsource.NewFetcher(t.TempDir()))
+	require.NoError(t, err)
+	r.Debounce = 50 * time.Millisecond
+	factory := &fakeFactory{reloads: make(chan string, 10)}
+	events, err := r.Add(gvk, chartDir, factory)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- r.Start(ctx) }()
+	defer func() {
+		cancel()
+		assert.NoError(t, <-done)
+	}()
+	// Give the file watcher time to start.
+	time.Sleep(100 * time.Millisecond)
+
+	// Changing a chart file reloads the chart and notifies the controller.
+	valuesFile := filepath.Join(chartDir, chartutil.ValuesfileName)
+	require.NoError(t, os.WriteFile(valuesFile, []byte("replicaCount: 2\n"), 0o600))
+	assert.Equal(t, chartDir, receive(t, factory.reloads))
+	ev := receive(t, events)
+	assert.Equal(t, gvk, ev.Object.(*unstructured.Unstructured).GroupVersionKind())
+
+	// Changing the chart of the watch reloads the chart from the new directory.
+	writeWatches(t, watchesFile, otherChartDir)
+	assert.Equal(t, otherChartDir, receive(t, factory.reloads))
+	receive(t, events)
+
+	// The new chart directory is watched in place of the old one.
+	require.NoError(t, os.WriteFile(valuesFile, []byte("replicaCount: 3\n"), 0o600))
+	otherValuesFile := filepath.Join(otherChartDir, chartutil.ValuesfileName)
+	require.NoError(t, os.WriteFile(otherValuesFile, []byte("replicaCount: 3\n"), 0o600))
+	assert.Equal(t, otherChartDir, receive(t, factory.reloads))
+	receive(t, events)
+}
+
+func TestReloaderTargetsFor(t *testing.T) {
+	gvk := schema.GroupVersionKind{Group: "mygroup", Version: "v1alpha1", Kind: "MyKind"}
+	chartDir := expandTestChart(t, t.TempDir())
+	watchesFile := filepath.Join(t.TempDir(), "watches.yaml")
+	writeWatches(t, watchesFile, chartDir)
+
+	r, err := New(watchesFile, chartsource.NewFetcher(t.TempDir()))
+	require.NoError(t, err)
+	_, err = r.Add(gvk, chartDir, &fakeFactory{})
+	require.NoError(t, err)
+
+	assert.Equal(t, []schema.GroupVersionKind{gvk}, r.targetsFor(chartDir))
+	assert.Equal(t, []schema.GroupVersionKind{gvk}, r.targetsFor(filepath.Join(chartDir, "templates", "deployment.yaml")))
+	assert.Empty(t, r.targetsFor(chartDir+"-other"))
+	assert.Empty(t, r.targetsFor(filepath.Dir(chartDir)))
+}
@@ -0,0 +1,103 @@
+// Copyright 2026 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"sync"
+
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+// SQL is a database containing release records, shared by the storage
+// drivers of all namespaces. The release records of the sql backend are
+// stored in PostgreSQL by the SQL storage driver of Helm, so that the Helm CLI
+// can read them. The release records of the sqlite backend are stored in a
+// SQLite database file.
+type SQL struct {
+	backend          Backend
+	connectionString string
+	log              func(string, ...any)
+
+	// sqlite is the database of the sqlite backend.
+	sqlite *sql.DB
+
+	// Helm connects each of its storage drivers to the database, so that the
+	// driver of each namespace is opened once and reused.
+	mu      sync.Mutex
+	drivers map[string]driver.Driver
+}
+
+// OpenSQL opens the database of the SQL backend b with connectionString, and
+// creates the tables of release records if they do not exist yet.
+// connectionString is a PostgreSQL connection string or URL for BackendSQL,
+// and a SQLite file name or URI for BackendSQLite. log is used by the storage
+// drivers to log debug messages.
+func OpenSQL(b Backend, connectionString string, log func(string, ...any)) (*SQL, error) {
+	if !b.IsSQL() {
+		return nil, fmt.Errorf("release storage backend %q is not a SQL backend", b)
+	}
+	if connectionString == "" {
+		return nil, fmt.Errorf("a connection string is required for the %q release storage backend", b)
+	}
+	if log == nil {
+		log = func(string, ...any) {}
+	}
+	s := &SQL{backend: b, connectionString: connectionString, log: log, drivers: map[string]driver.Driver{}}
+	if b == BackendSQLite {
+		db, err := openSQLite(connectionString)
+		if err != nil {
+			return nil, err
+		}
+		s.sqlite = db
+		return s, nil
+	}
+	// Open the driver of all namespaces to check the connection and create
+	// the tables.
+	if _, err := s.Driver(""); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Close closes the database of the sqlite backend. The databases of the
+// drivers of the sql backend are kept open by Helm.
+func (s *SQL) Close() error {
+	if s.sqlite == nil {
+		return nil
+	}
+	return s.sqlite.Close()
+}
+
+// Driver returns the storage driver of the release records of namespace, or
+// of all namespaces if namespace is empty. The driver of all namespaces can
+// only list and query release records.
+func (s *SQL) Driver(namespace string) (driver.Driver, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sqlite != nil {
+		return &sqliteDriver{db: s.sqlite, namespace: namespace, log: s.log}, nil
+	}
+	if d, ok := s.drivers[namespace]; ok {
+		return d, nil
+	}
+	d, err := driver.NewSQL(s.connectionString, s.log, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open release storage database: %w", err)
+	}
+	s.drivers[namespace] = d
+	return d, nil
+}
@@ -0,0 +1,300 @@
+// Copyright 2026 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"bytes"
+	"compress/gzip"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	// Register the SQLite database/sql driver.
+	_ "github.com/mattn/go-sqlite3"
+	rspb "helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+// SQLiteDriverName is the name of the storage driver of the sqlite backend.
+const SQLiteDriverName = "SQLite"
+
+const (
+	// sqliteTable is the table containing release records.
+	sqliteTable = "releases"
+
+	// sqliteReleaseOwner is the owner label of release records, as set by the
+	// Helm storage drivers.
+	sqliteReleaseOwner = "helm"
+)
+
+var sqliteSchema = `CREATE TABLE IF NOT EXISTS ` + sqliteTable + ` (
+	namespace TEXT NOT NULL,
+	release_key TEXT NOT NULL,
+	name TEXT NOT NULL,
+	status TEXT NOT NULL,
+	labels TEXT NOT NULL,
+	body TEXT NOT NULL,
+	PRIMARY KEY (namespace, release_key)
+)`
+
+// openSQLite opens the SQLite database file or URI connectionString, and
+// creates the table of release records if it does not exist yet.
+func openSQLite(connectionString string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite3", connectionString)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open release storage database: %w", err)
+	}
+	// SQLite does not support concurrent writes.
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to create release storage table: %w", err)
+	}
+	return db, nil
+}
+
+// sqliteDriver stores the release records of a namespace, or lists and
+// queries the release records of all namespaces if namespace is empty, in a
+// SQLite database. Release records are encoded like the records of the Helm
+// storage drivers.
+type sqliteDriver struct {
+	db        *sql.DB
+	namespace string
+	log       func(string, ...any)
+}
+
+var _ driver.Driver = &sqliteDriver{}
+
+func (d *sqliteDriver) Name() string {
+	return SQLiteDriverName
+}
+
+func (d *sqliteDriver) Get(key string) (*rspb.Release, error) {
+	releases, err := d.query("release_key = ?", key)
+	if err != nil {
+		return nil, err
+	}
+	if len(releases) == 0 {
+		return nil, driver.ErrReleaseNotFound
+	}
+	return releases[0], nil
+}
+
+func (d *sqliteDriver) List(filter func(*rspb.Release) bool) ([]*rspb.Release, error) {
+	releases, err := d.query("")
+	if err != nil {
+		return nil, err
+	}
+	var out []*rspb.Release
+	for _, rls := range releases {
+		if filter(rls) {
+			out = append(out, rls)
+		}
+	}
+	return out, nil
+}
+
+// Query returns the release records with all of labels. Like the labels of
+// the Secrets and ConfigMaps storing release records, the labels of a record
+// are its custom labels along with its name, owner, status and version.
+func (d *sqliteDriver) Query(labels map[string]string) ([]*rspb.Release, error) {
+	condition, args := "", []any{}
+	if name, ok := labels["name"]; ok {
+		condition, args = "name = ?", append(args, name)
+	}
+	releases, err := d.query(condition, args...)
+	if err != nil {
+		return nil, err
+	}
+	var out []*rspb.Release
+	for _, rls := range releases {
+		if matchLabels(recordLabels(rls), labels) {
+			out = append(out, rls)
+		}
+	}
+	if len(out) == 0 {
+		return nil, driver.ErrReleaseNotFound
+	}
+	return out, nil
+}
+
+func (d *sqliteDriver) Create(key string, rls *rspb.Release) error {
+	labels, body, err := encodeRecord(rls)
+	if err != nil {
+		return err
+	}
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+	var n int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM "+sqliteTable+" WHERE namespace = ? AND release_key = ?",
+		d.namespace, key).Scan(&n); err != nil {
+		return err
+	}
+	if n > 0 {
+		return driver.ErrReleaseExists
+	}
+	if _, err := tx.Exec("INSERT INTO "+sqliteTable+" (namespace, release_key, name, status, labels, body) "+
+		"VALUES (?, ?, ?, ?, ?, ?)", d.namespace, key, rls.Name, releaseStatus(rls), labels, body); err != nil {
+		d.log("create: failed to insert release record %s/%s: %v", d.namespace, key, err)
+		return err
+	}
+	return tx.Commit()
+}
+
+func (d *sqliteDriver) Update(key string, rls *rspb.Release) error {
+	labels, body, err := encodeRecord(rls)
+	if err != nil {
+		return err
+	}
+	result, err := d.db.Exec("UPDATE "+sqliteTable+" SET name = ?, status = ?, labels = ?, body = ? "+
+		"WHERE namespace = ? AND release_key = ?", rls.Name, releaseStatus(rls), labels, body, d.namespace, key)
+	if err != nil {
+		d.log("update: failed to update release record %s/%s: %v", d.namespace, key, err)
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return driver.ErrReleaseNotFound
+	}
+	return nil
+}
+
+func (d *sqliteDriver) Delete(key string) (*rspb.Release, error) {
+	rls, err := d.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := d.db.Exec("DELETE FROM "+sqliteTable+" WHERE namespace = ? AND release_key = ?", d.namespace, key); err != nil {
+		d.log("delete: failed to delete release record %s/%s: %v", d.namespace, key, err)
+		return nil, err
+	}
+	return rls, nil
+}
+
+// query returns the decoded release records of the namespace of d matching
+// condition.
+func (d *sqliteDriver) query(condition string, args ...any) ([]*rspb.Release, error) {
+	query := "SELECT labels, body FROM " + sqliteTable + " WHERE 1 = 1"
+	if d.namespace != "" {
+		query += " AND namespace = ?"
+		args = append([]any{d.namespace}, args...)
+	}
+	if condition != "" {
+		query += " AND " + condition
+	}
+	rows, err := d.db.Query(query, args...)
+	if err != nil {
+		d.log("query: failed to query release records: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var releases []*rspb.Release
+	for rows.Next() {
+		var labels, body string
+		if err := rows.Scan(&labels, &body); err != nil {
+			return nil, err
+		}
+		rls, err := decodeRecord(labels, body)
+		if err != nil {
+			d.log("query: failed to decode release record: %v", err)
+			continue
+		}
+		releases = append(releases, rls)
+	}
+	return releases, rows.Err()
+}
+
+func releaseStatus(rls *rspb.Release) string {
+	if rls.Info == nil {
+		return ""
+	}
+	return rls.Info.Status.String()
+}
+
+// recordLabels returns the labels of the record of rls.
+func recordLabels(rls *rspb.Release) map[string]string {
+	labels := make(map[string]string, len(rls.Labels)+4)
+	for k, v := range rls.Labels {
+		labels[k] = v
+	}
+	labels["name"] = rls.Name
+	labels["owner"] = sqliteReleaseOwner
+	labels["status"] = releaseStatus(rls)
+	labels["version"] = strconv.Itoa(rls.Version)
+	return labels
+}
+
+func matchLabels(labels, selector map[string]string) bool {
+	for k, v := range selector {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// encodeRecord returns the custom labels of rls as JSON, and rls as
+// base64-encoded, gzip-compressed JSON, as stored by the Helm storage
+// drivers.
+func encodeRecord(rls *rspb.Release) (string, string, error) {
+	labels, err := json.Marshal(rls.Labels)
+	if err != nil {
+		return "", "", err
+	}
+	b, err := json.Marshal(rls)
+	if err != nil {
+		return "", "", err
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(b); err != nil {
+		return "", "", err
+	}
+	if err := zw.Close(); err != nil {
+		return "", "", err
+	}
+	return string(labels), base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+func decodeRecord(labels, body string) (*rspb.Release, error) {
+	b, err := base64.StdEncoding.DecodeString(body)
+	if err != nil {
+		return nil, err
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	b, err = io.ReadAll(zr)
+	if err != nil {
+		return nil, err
+	}
+	var rls rspb.Release
+	if err := json.Unmarshal(b, &rls); err != nil {
+		return nil, err
+	}
+	// Like the Helm storage drivers, custom labels are not part of the
+	// encoded release.
+	if err := json.Unmarshal([]byte(labels), &rls.Labels); err != nil {
+		return nil, err
+	}
+	return &rls, nil
+}
@@ -0,0 +1,116 @@
+// Copyright 2026 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build cgo
+
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	rspb "helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+func openTestSQLite(t *testing.T) *SQL {
+	db, err := OpenSQL(BackendSQLite, filepath.Join(t.TempDir(), "releases.db"), nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
+
+func TestSQLiteDriver(t *testing.T) {
+	db := openTestSQLite(t)
+	d, err := db.Driver("default")
+	require.NoError(t, err)
+	assert.Equal(t, SQLiteDriverName, d.Name())
+
+	rls := newRelease("default", "nginx", 1, rspb.StatusDeployed)
+	rls.Labels = map[string]string{"helm.sdk.operatorframework.io/owner-uid": "uid"}
+	key := releaseKey("nginx", 1)
+	require.NoError(t, d.Create(key, rls))
+	assert.ErrorIs(t, d.Create(key, rls), driver.ErrReleaseExists)
+
+	got, err := d.Get(key)
+	require.NoError(t, err)
+	assert.Equal(t, rls, got)
+	_, err = d.Get(releaseKey("nginx", 2))
+	assert.ErrorIs(t, err, driver.ErrReleaseNotFound)
+
+	// Records are queried by their custom and system labels.
+	found, err := d.Query(map[string]string{"name": "nginx", "owner": "helm", "status": "deployed"})
+	require.NoError(t, err)
+	assert.Equal(t, []*rspb.Release{rls}, found)
+	found, err = d.Query(map[string]string{"helm.sdk.operatorframework.io/owner-uid": "uid"})
+	require.NoError(t, err)
+	assert.Len(t, found, 1)
+	_, err = d.Query(map[string]string{"name": "nginx", "status": "superseded"})
+	assert.ErrorIs(t, err, driver.ErrReleaseNotFound)
+
+	rls.Info.Status = rspb.StatusSuperseded
+	require.NoError(t, d.Update(key, rls))
+	got, err = d.Get(key)
+	require.NoError(t, err)
+	assert.Equal(t, rspb.StatusSuperseded, got.Info.Status)
+	assert.ErrorIs(t, d.Update(releaseKey("nginx", 2), rls), driver.ErrReleaseNotFound)
+
+	// Records are isolated by namespace, and the driver of all namespaces
+	// lists the records of every namespace.
+	other, err := db.Driver("other")
+	require.NoError(t, err)
+	_, err = other.Get(key)
+	assert.ErrorIs(t, err, driver.ErrReleaseNotFound)
+	require.NoError(t, other.Create(releaseKey("redis", 1), newRelease("other", "redis", 1, rspb.StatusDeployed)))
+	all, err := db.Driver("")
+	require.NoError(t, err)
+	listed, err := all.List(func(*rspb.Release) bool { return true })
+	require.NoError(t, err)
+	assert.Len(t, listed, 2)
+
+	deleted, err := d.Delete(key)
+	require.NoError(t, err)
+	assert.Equal(t, "nginx", deleted.Name)
+	_, err = d.Delete(key)
+	assert.ErrorIs(t, err, driver.ErrReleaseNotFound)
+}
+
+func TestMigrateToSQLite(t *testing.T) {
+	source := driver.NewMemory()
+	for _, rls := range []*rspb.Release{
+		newRelease("default", "nginx", 1, rspb.StatusSuperseded),
+		newRelease("default", "nginx", 2, rspb.StatusDeployed),
+		newRelease("other", "redis", 1, rspb.StatusDeployed),
+	} {
+		source.SetNamespace(rls.Namespace)
+		require.NoError(t, source.Create(releaseKey(rls.Name, rls.Version), rls))
+	}
+	db := openTestSQLite(t)
+
+	n, err := Migrate(memoryDrivers(source), db.Driver, "", func(*rspb.Release) bool { return true }, false)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	d, err := db.Driver("other")
+	require.NoError(t, err)
+	rls, err := d.Get(releaseKey("redis", 1))
+	require.NoError(t, err)
+	assert.Equal(t, "other", rls.Namespace)
+
+	// Migrating again overwrites the records.
+	n, err = Migrate(memoryDrivers(source), db.Driver, "default", func(*rspb.Release) bool { return true }, false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+}
@@ -0,0 +1,115 @@
+// Copyright 2026 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package storage implements the backends in which the helm-operator stores
+// Helm release records, and the migration of release records between them.
+package storage
+
+import (
+	"errors"
+	"fmt"
+
+	rspb "helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+// Backend is a storage backend for Helm release records.
+type Backend string
+
+const (
+	// BackendSecret stores release records in Secrets in the namespace of
+	// each release. It is the default backend.
+	BackendSecret Backend = "secret"
+	// BackendConfigMap stores release records in ConfigMaps in the namespace
+	// of each release.
+	BackendConfigMap Backend = "configmap"
+	// BackendSQL stores release records in a PostgreSQL database.
+	BackendSQL Backend = "sql"
+	// BackendSQLite stores release records in a SQLite database file. It is
+	// meant for local testing, and requires a binary built with cgo.
+	BackendSQLite Backend = "sqlite"
+)
+
+// ConnectionStringEnvVar is the environment variable from which the
+// connection string of the SQL backend is read if it is not set with a
+// flag. It is the variable used by the Helm CLI for its SQL backend.
+const ConnectionStringEnvVar = "HELM_DRIVER_SQL_CONNECTION_STRING"
+
+// Validate returns an error if b is not a supported backend. The empty
+// backend is valid and stands for the default backend.
+func (b Backend) Validate() error {
+	switch b {
+	case "", BackendSecret, BackendConfigMap, BackendSQL, BackendSQLite:
+		return nil
+	}
+	return fmt.Errorf("unsupported release storage backend %q: must be one of %q, %q, %q or %q",
+		b, BackendSecret, BackendConfigMap, BackendSQL, BackendSQLite)
+}
+
+// IsSQL returns true if b stores release records in a database.
+func (b Backend) IsSQL() bool {
+	return b == BackendSQL || b == BackendSQLite
+}
+
+// Drivers returns the storage driver of the release records of namespace,
+// or of all namespaces if namespace is empty.
+type Drivers func(namespace string) (driver.Driver, error)
+
+// Migrate copies the release records of namespace, or of all namespaces if
+// namespace is empty, that match filter from one backend to another, and
+// returns the number of records copied. Records that already exist in the
+// target backend are overwritten. If deleteSource is true, records are
+// deleted from the source backend once copied.
+func Migrate(from, to Drivers, namespace string, filter func(*rspb.Release) bool, deleteSource bool) (int, error) {
+	source, err := from(namespace)
+	if err != nil {
+		return 0, err
+	}
+	releases, err := source.List(filter)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list release records: %w", err)
+	}
+
+	migrated := 0
+	for _, rls := range releases {
+		key := releaseKey(rls.Name, rls.Version)
+		target, err := to(rls.Namespace)
+		if err != nil {
+			return migrated, err
+		}
+		err = target.Create(key, rls)
+		if errors.Is(err, driver.ErrReleaseExists) {
+			err = target.Update(key, rls)
+		}
+		if err != nil {
+			return migrated, fmt.Errorf("failed to copy release record %s/%s: %w", rls.Namespace, key, err)
+		}
+		if deleteSource {
+			namespaced, err := from(rls.Namespace)
+			if err != nil {
+				return migrated, err
+			}
+			if _, err := namespaced.Delete(key); err != nil && !errors.Is(err, driver.ErrReleaseNotFound) {
+				return migrated, fmt.Errorf("failed to delete release record %s/%s: %w", rls.Namespace, key, err)
+			}
+		}
+		migrated++
+	}
+	return migrated, nil
+}
+
+// releaseKey returns the key of a release record, as set by the Helm storage.
+func releaseKey(name string, version int) string {
+	return fmt.Sprintf("sh.helm.release.v1.%s.v%d", name, version)
+}
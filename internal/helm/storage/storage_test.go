@@ -0,0 +1,105 @@
+// Copyright 2026 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	rspb "helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+func newRelease(namespace, name string, version int, status rspb.Status) *rspb.Release {
+	return &rspb.Release{
+		Name:      name,
+		Namespace: namespace,
+		Version:   version,
+		Info:      &rspb.Info{Status: status},
+		Manifest:  "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: " + name + "\n",
+	}
+}
+
+// memoryDrivers returns the drivers of an in-memory backend.
+func memoryDrivers(memory *driver.Memory) Drivers {
+	return func(namespace string) (driver.Driver, error) {
+		memory.SetNamespace(namespace)
+		return memory, nil
+	}
+}
+
+func TestBackendValidate(t *testing.T) {
+	for _, b := range []Backend{"", BackendSecret, BackendConfigMap, BackendSQL, BackendSQLite} {
+		assert.NoError(t, b.Validate(), b)
+	}
+	assert.ErrorContains(t, Backend("etcd").Validate(), `unsupported release storage backend "etcd"`)
+	assert.True(t, BackendSQL.IsSQL())
+	assert.True(t, BackendSQLite.IsSQL())
+	assert.False(t, BackendConfigMap.IsSQL())
+}
+
+func TestOpenSQLErrors(t *testing.T) {
+	_, err := OpenSQL(BackendSQL, "", nil)
+	assert.ErrorContains(t, err, `a connection string is required for the "sql" release storage backend`)
+	_, err = OpenSQL(BackendSQLite, "", nil)
+	assert.ErrorContains(t, err, `a connection string is required for the "sqlite" release storage backend`)
+	_, err = OpenSQL(BackendSecret, "releases.db", nil)
+	assert.ErrorContains(t, err, `release storage backend "secret" is not a SQL backend`)
+}
+
+func TestMigrate(t *testing.T) {
+	source, target := driver.NewMemory(), driver.NewMemory()
+	for _, rls := range []*rspb.Release{
+		newRelease("default", "nginx", 1, rspb.StatusSuperseded),
+		newRelease("default", "nginx", 2, rspb.StatusDeployed),
+		newRelease("default", "unowned", 1, rspb.StatusDeployed),
+		newRelease("other", "redis", 1, rspb.StatusDeployed),
+	} {
+		source.SetNamespace(rls.Namespace)
+		require.NoError(t, source.Create(releaseKey(rls.Name, rls.Version), rls))
+	}
+	owned := func(rls *rspb.Release) bool { return rls.Name != "unowned" }
+
+	// An existing record is overwritten.
+	target.SetNamespace("default")
+	require.NoError(t, target.Create(releaseKey("nginx", 2), newRelease("default", "nginx", 2, rspb.StatusFailed)))
+
+	n, err := Migrate(memoryDrivers(source), memoryDrivers(target), "default", owned, false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	target.SetNamespace("default")
+	rls, err := target.Get(releaseKey("nginx", 2))
+	require.NoError(t, err)
+	assert.Equal(t, rspb.StatusDeployed, rls.Info.Status)
+	_, err = target.Get(releaseKey("unowned", 1))
+	assert.ErrorIs(t, err, driver.ErrReleaseNotFound)
+	target.SetNamespace("other")
+	_, err = target.Get(releaseKey("redis", 1))
+	assert.ErrorIs(t, err, driver.ErrReleaseNotFound)
+
+	n, err = Migrate(memoryDrivers(source), memoryDrivers(target), "", owned, true)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	target.SetNamespace("other")
+	_, err = target.Get(releaseKey("redis", 1))
+	assert.NoError(t, err)
+	// Records that do not match the filter are left in the source backend.
+	source.SetNamespace("")
+	remaining, err := source.List(func(*rspb.Release) bool { return true })
+	require.NoError(t, err)
+	require.Len(t, remaining, 1)
+	assert.Equal(t, "unowned", remaining[0].Name)
+}
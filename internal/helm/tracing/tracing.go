@@ -0,0 +1,187 @@
+// Copyright 2026 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tracing exports traces of the helm-operator reconcile path to an
+// OpenTelemetry collector with OTLP.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.40.0"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	sdkVersion "github.com/operator-framework/operator-sdk/internal/version"
+)
+
+const (
+	// ProtocolGRPC and ProtocolHTTP are the supported OTLP protocols.
+	ProtocolGRPC = "grpc"
+	ProtocolHTTP = "http/protobuf"
+
+	tracerName  = "github.com/operator-framework/operator-sdk/internal/helm"
+	serviceName = "helm-operator"
+)
+
+// Attribute keys identifying the custom resource and release of a span.
+const (
+	AttributeGroup     = attribute.Key("helm.sdk.operatorframework.io/group")
+	AttributeVersion   = attribute.Key("helm.sdk.operatorframework.io/version")
+	AttributeKind      = attribute.Key("helm.sdk.operatorframework.io/kind")
+	AttributeNamespace = attribute.Key("helm.sdk.operatorframework.io/namespace")
+	AttributeName      = attribute.Key("helm.sdk.operatorframework.io/name")
+	AttributeUID       = attribute.Key("helm.sdk.operatorframework.io/uid")
+	AttributeRelease   = attribute.Key("helm.sdk.operatorframework.io/release")
+)
+
+// Options configures the export of traces. Options not set are read from the
+// standard OTEL_EXPORTER_OTLP_* environment variables.
+type Options struct {
+	// Endpoint is the host and port, or the URL, of the collector.
+	Endpoint string
+	// Protocol is the OTLP protocol, ProtocolGRPC or ProtocolHTTP.
+	Protocol string
+	// Insecure disables TLS.
+	Insecure bool
+	// SampleRatio is the ratio of reconciliations traced, between 0 and 1.
+	SampleRatio float64
+}
+
+// Enabled returns true if an endpoint is configured by o or the environment.
+func (o Options) Enabled() bool {
+	return o.Endpoint != "" ||
+		os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" ||
+		os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != ""
+}
+
+func (o Options) protocol() string {
+	if o.Protocol != "" {
+		return o.Protocol
+	}
+	for _, env := range []string{"OTEL_EXPORTER_OTLP_TRACES_PROTOCOL", "OTEL_EXPORTER_OTLP_PROTOCOL"} {
+		if p := os.Getenv(env); p != "" {
+			return p
+		}
+	}
+	return ProtocolGRPC
+}
+
+// Setup configures the global tracer provider to export traces as configured
+// by o. The returned function flushes pending spans and stops the export.
+func Setup(ctx context.Context, o Options) (func(context.Context) error, error) {
+	if o.SampleRatio < 0 || o.SampleRatio > 1 {
+		return nil, fmt.Errorf("invalid sample ratio %v: must be between 0 and 1", o.SampleRatio)
+	}
+	exporter, err := newExporter(ctx, o)
+	if err != nil {
+		return nil, err
+	}
+	res, err := resource.New(ctx,
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(semconv.ServiceName(serviceName), semconv.ServiceVersion(serviceVersion())),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tracing resource: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(o.SampleRatio))),
+	)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return tp.Shutdown, nil
+}
+
+func serviceVersion() string {
+	if sdkVersion.GitVersion != "unknown" {
+		return sdkVersion.GitVersion
+	}
+	return sdkVersion.Version
+}
+
+func newExporter(ctx context.Context, o Options) (sdktrace.SpanExporter, error) {
+	isURL := strings.Contains(o.Endpoint, "://")
+	switch p := o.protocol(); p {
+	case ProtocolGRPC:
+		var opts []otlptracegrpc.Option
+		if isURL {
+			opts = append(opts, otlptracegrpc.WithEndpointURL(o.Endpoint))
+		} else if o.Endpoint != "" {
+			opts = append(opts, otlptracegrpc.WithEndpoint(o.Endpoint))
+		}
+		if o.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		return otlptracegrpc.New(ctx, opts...)
+	case ProtocolHTTP:
+		var opts []otlptracehttp.Option
+		if isURL {
+			opts = append(opts, otlptracehttp.WithEndpointURL(o.Endpoint))
+		} else if o.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(o.Endpoint))
+		}
+		if o.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unsupported OTLP protocol %q: must be %q or %q", p, ProtocolGRPC, ProtocolHTTP)
+	}
+}
+
+// Start starts a span named name. Spans are not exported unless Setup was
+// called.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End ends span, recording err if it is not nil.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// ObjectAttributes returns the attributes identifying the custom resource o.
+func ObjectAttributes(o *unstructured.Unstructured) []attribute.KeyValue {
+	gvk := o.GroupVersionKind()
+	attrs := []attribute.KeyValue{
+		AttributeGroup.String(gvk.Group),
+		AttributeVersion.String(gvk.Version),
+		AttributeKind.String(gvk.Kind),
+		AttributeNamespace.String(o.GetNamespace()),
+		AttributeName.String(o.GetName()),
+	}
+	if uid := o.GetUID(); uid != "" {
+		attrs = append(attrs, AttributeUID.String(string(uid)))
+	}
+	return attrs
+}
@@ -0,0 +1,150 @@
+// Copyright 2026 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// collector is a stand-in for an OpenTelemetry collector receiving traces
+// with OTLP over HTTP.
+type collector struct {
+	mu    sync.Mutex
+	spans []*tracepb.Span
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/v1/traces" {
+		http.NotFound(w, r)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req := &coltracepb.ExportTraceServiceRequest{}
+	if err := proto.Unmarshal(body, req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	c.mu.Lock()
+	for _, rs := range req.ResourceSpans {
+		for _, ss := range rs.ScopeSpans {
+			c.spans = append(c.spans, ss.Spans...)
+		}
+	}
+	c.mu.Unlock()
+
+	resp, _ := proto.Marshal(&coltracepb.ExportTraceServiceResponse{})
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	_, _ = w.Write(resp)
+}
+
+func (c *collector) span(name string) *tracepb.Span {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, s := range c.spans {
+		if s.Name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+func attributes(s *tracepb.Span) map[string]string {
+	attrs := map[string]string{}
+	for _, kv := range s.Attributes {
+		attrs[kv.Key] = kv.Value.GetStringValue()
+	}
+	return attrs
+}
+
+func TestSetup(t *testing.T) {
+	c := &collector{}
+	server := httptest.NewServer(c)
+	defer server.Close()
+	defer otel.SetTracerProvider(otel.GetTracerProvider())
+
+	shutdown, err := Setup(context.Background(), Options{
+		Endpoint:    server.URL,
+		Protocol:    ProtocolHTTP,
+		SampleRatio: 1,
+	})
+	require.NoError(t, err)
+
+	cr := &unstructured.Unstructured{}
+	cr.SetAPIVersion("example.com/v1alpha1")
+	cr.SetKind("Nginx")
+	cr.SetNamespace("default")
+	cr.SetName("nginx-sample")
+	cr.SetUID("6f1c2e4a")
+
+	ctx, parent := Start(context.Background(), "Reconcile", ObjectAttributes(cr)...)
+	_, child := Start(ctx, "UpgradeRelease")
+	End(child, errors.New("upgrade failed"))
+	End(parent, nil)
+	require.NoError(t, shutdown(context.Background()))
+
+	reconcileSpan := c.span("Reconcile")
+	require.NotNil(t, reconcileSpan)
+	assert.Equal(t, map[string]string{
+		"helm.sdk.operatorframework.io/group":     "example.com",
+		"helm.sdk.operatorframework.io/version":   "v1alpha1",
+		"helm.sdk.operatorframework.io/kind":      "Nginx",
+		"helm.sdk.operatorframework.io/namespace": "default",
+		"helm.sdk.operatorframework.io/name":      "nginx-sample",
+		"helm.sdk.operatorframework.io/uid":       "6f1c2e4a",
+	}, attributes(reconcileSpan))
+	assert.Equal(t, tracepb.Status_STATUS_CODE_UNSET, reconcileSpan.GetStatus().GetCode())
+
+	upgradeSpan := c.span("UpgradeRelease")
+	require.NotNil(t, upgradeSpan)
+	assert.Equal(t, reconcileSpan.SpanId, upgradeSpan.ParentSpanId)
+	assert.Equal(t, tracepb.Status_STATUS_CODE_ERROR, upgradeSpan.GetStatus().GetCode())
+	assert.Equal(t, "upgrade failed", upgradeSpan.GetStatus().GetMessage())
+}
+
+func TestSetupErrors(t *testing.T) {
+	_, err := Setup(context.Background(), Options{Endpoint: "localhost:4317", SampleRatio: 2})
+	assert.ErrorContains(t, err, "invalid sample ratio")
+
+	_, err = Setup(context.Background(), Options{Endpoint: "localhost:4317", Protocol: "http/json", SampleRatio: 1})
+	assert.ErrorContains(t, err, "unsupported OTLP protocol")
+}
+
+func TestOptionsEnabled(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+	assert.False(t, Options{}.Enabled())
+	assert.True(t, Options{Endpoint: "localhost:4317"}.Enabled())
+
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "http://localhost:4318/v1/traces")
+	assert.True(t, Options{}.Enabled())
+}
@@ -0,0 +1,43 @@
+// Copyright 2026 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package values
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+)
+
+// NewCache returns a cache of the ConfigMaps and Secrets labeled with
+// SourceLabel=true in namespaces. The operator's main cache only contains
+// objects created by its charts, so referenced objects are read from and
+// watched with this cache instead.
+func NewCache(cfg *rest.Config, mapper meta.RESTMapper, namespaces []string) (cache.Cache, error) {
+	selector := labels.SelectorFromSet(labels.Set{SourceLabel: "true"})
+	opts := cache.Options{
+		Scheme:               clientgoscheme.Scheme,
+		Mapper:               mapper,
+		DefaultLabelSelector: selector,
+	}
+	if len(namespaces) > 0 {
+		opts.DefaultNamespaces = make(map[string]cache.Config, len(namespaces))
+		for _, ns := range namespaces {
+			opts.DefaultNamespaces[ns] = cache.Config{LabelSelector: selector}
+		}
+	}
+	return cache.New(cfg, opts)
+}
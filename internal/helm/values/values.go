@@ -0,0 +1,319 @@
+// Copyright 2026 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package values
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"text/template"
+
+	sprig "github.com/go-task/slim-sprig"
+	"github.com/google/cel-go/cel"
+	"google.golang.org/protobuf/types/known/structpb"
+	"helm.sh/helm/v3/pkg/chartutil"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// KindConfigMap and KindSecret are the kinds of objects values can be
+	// read from.
+	KindConfigMap = "ConfigMap"
+	KindSecret    = "Secret"
+
+	// DefaultKey is the key of the values in a referenced object if none is
+	// set.
+	DefaultKey = "values.yaml"
+
+	// SourceLabel must be set to "true" on ConfigMaps and Secrets referenced
+	// by a values pipeline, so that the operator caches and watches them.
+	SourceLabel = "helm.sdk.operatorframework.io/values-source"
+)
+
+// Options configures the values pipeline of a watch. Values are merged in
+// the following order, later values taking precedence: the defaults file,
+// the referenced objects in order, the custom resource spec, and the
+// expressions in order.
+type Options struct {
+	// DefaultsFile is the path of a values file containing defaults for
+	// values not set anywhere else.
+	DefaultsFile string `json:"defaultsFile,omitempty"`
+	// From references ConfigMaps and Secrets in the namespace of the custom
+	// resource containing values.
+	From []Reference `json:"from,omitempty"`
+	// Expressions compute values from the custom resource.
+	Expressions []Expression `json:"expressions,omitempty"`
+}
+
+// Reference references a key of a ConfigMap or Secret containing values in
+// YAML format.
+type Reference struct {
+	Kind     string `json:"kind"`
+	Name     string `json:"name"`
+	Key      string `json:"key,omitempty"`
+	Optional bool   `json:"optional,omitempty"`
+}
+
+// Expression sets the value at Path, a dot-separated path in the values, to
+// the result of a CEL expression or Go template. CEL expressions can access
+// the custom resource as the "object" variable, and templates are executed
+// with the custom resource as data. Templates always produce a string.
+type Expression struct {
+	Path     string `json:"path"`
+	CEL      string `json:"cel,omitempty"`
+	Template string `json:"template,omitempty"`
+}
+
+// IsZero returns true if o does not configure any step of the pipeline.
+func (o Options) IsZero() bool {
+	return o.DefaultsFile == "" && len(o.From) == 0 && len(o.Expressions) == 0
+}
+
+// Validate returns an error if the defaults file cannot be read, a reference
+// is invalid, or an expression does not compile.
+func (o Options) Validate() error {
+	_, err := NewPipeline(o, nil)
+	return err
+}
+
+// Pipeline derives the values used to render a chart from a custom resource.
+type Pipeline struct {
+	defaults    map[string]any
+	from        []Reference
+	expressions []compiledExpression
+	reader      client.Reader
+}
+
+type compiledExpression struct {
+	path     []string
+	program  cel.Program
+	template *template.Template
+}
+
+// NewPipeline returns the Pipeline configured by o. Referenced ConfigMaps and
+// Secrets are read with reader.
+func NewPipeline(o Options, reader client.Reader) (*Pipeline, error) {
+	p := &Pipeline{reader: reader}
+	if o.DefaultsFile != "" {
+		defaults, err := chartutil.ReadValuesFile(o.DefaultsFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read defaults file: %w", err)
+		}
+		p.defaults = defaults
+	}
+
+	for _, ref := range o.From {
+		if ref.Kind != KindConfigMap && ref.Kind != KindSecret {
+			return nil, fmt.Errorf("invalid values reference %q: kind must be %s or %s", ref.Name, KindConfigMap, KindSecret)
+		}
+		if ref.Name == "" {
+			return nil, fmt.Errorf("invalid %s values reference: name must be set", ref.Kind)
+		}
+		if ref.Key == "" {
+			ref.Key = DefaultKey
+		}
+		p.from = append(p.from, ref)
+	}
+
+	if len(o.Expressions) == 0 {
+		return p, nil
+	}
+	env, err := cel.NewEnv(cel.Variable("object", cel.DynType))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CEL environment: %w", err)
+	}
+	for _, e := range o.Expressions {
+		ce, err := compileExpression(e, env)
+		if err != nil {
+			return nil, fmt.Errorf("invalid values expression for %q: %w", e.Path, err)
+		}
+		p.expressions = append(p.expressions, ce)
+	}
+	return p, nil
+}
+
+func compileExpression(e Expression, env *cel.Env) (compiledExpression, error) {
+	ce := compiledExpression{path: strings.Split(e.Path, ".")}
+	if e.Path == "" {
+		return ce, errors.New("path must be set")
+	}
+	if (e.CEL == "") == (e.Template == "") {
+		return ce, errors.New("exactly one of cel or template must be set")
+	}
+
+	if e.Template != "" {
+		tmpl, err := template.New(e.Path).Funcs(sprig.TxtFuncMap()).Option("missingkey=error").Parse(e.Template)
+		if err != nil {
+			return ce, err
+		}
+		ce.template = tmpl
+		return ce, nil
+	}
+
+	ast, issues := env.Compile(e.CEL)
+	if issues.Err() != nil {
+		return ce, issues.Err()
+	}
+	program, err := env.Program(ast)
+	if err != nil {
+		return ce, err
+	}
+	ce.program = program
+	return ce, nil
+}
+
+// References returns the ConfigMaps and Secrets read by the pipeline.
+func (p *Pipeline) References() []Reference {
+	return p.from
+}
+
+// TransformValues returns the values used to render the chart for cr, given
+// the values in its spec. Referenced ConfigMaps and Secrets are read from
+// namespace, the namespace of the release of cr.
+func (p *Pipeline) TransformValues(ctx context.Context, cr *unstructured.Unstructured, namespace string,
+	spec map[string]any) (map[string]any, error) {
+	values := runtime.DeepCopyJSON(p.defaults)
+	if values == nil {
+		values = map[string]any{}
+	}
+
+	for _, ref := range p.from {
+		refValues, err := p.readReference(ctx, namespace, ref)
+		if err != nil {
+			return nil, err
+		}
+		values = merge(values, refValues)
+	}
+	// The spec is copied since expressions may set values nested in it.
+	values = merge(values, runtime.DeepCopyJSON(spec))
+
+	for _, e := range p.expressions {
+		v, err := e.evaluate(cr.Object)
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate values expression for %q: %w", strings.Join(e.path, "."), err)
+		}
+		setPath(values, e.path, v)
+	}
+	return values, nil
+}
+
+func (p *Pipeline) readReference(ctx context.Context, namespace string, ref Reference) (map[string]any, error) {
+	key := client.ObjectKey{Namespace: namespace, Name: ref.Name}
+	var (
+		data  []byte
+		found bool
+		err   error
+	)
+	switch ref.Kind {
+	case KindConfigMap:
+		cm := &corev1.ConfigMap{}
+		if err = p.reader.Get(ctx, key, cm); err == nil {
+			var s string
+			if s, found = cm.Data[ref.Key]; found {
+				data = []byte(s)
+			} else {
+				data, found = cm.BinaryData[ref.Key]
+			}
+		}
+	case KindSecret:
+		secret := &corev1.Secret{}
+		if err = p.reader.Get(ctx, key, secret); err == nil {
+			data, found = secret.Data[ref.Key]
+		}
+	}
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			if ref.Optional {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("values %s %s not found; it must exist and have the label %s=true",
+				ref.Kind, key, SourceLabel)
+		}
+		return nil, fmt.Errorf("failed to get values %s %s: %w", ref.Kind, key, err)
+	}
+	if !found {
+		if ref.Optional {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("values %s %s has no key %q", ref.Kind, key, ref.Key)
+	}
+
+	values, err := chartutil.ReadValues(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse values in %s %s key %q: %w", ref.Kind, key, ref.Key, err)
+	}
+	return values, nil
+}
+
+func (e compiledExpression) evaluate(obj map[string]any) (any, error) {
+	if e.template != nil {
+		var buf bytes.Buffer
+		if err := e.template.Execute(&buf, obj); err != nil {
+			return nil, err
+		}
+		return buf.String(), nil
+	}
+
+	out, _, err := e.program.Eval(map[string]any{"object": obj})
+	if err != nil {
+		return nil, err
+	}
+	v, err := out.ConvertToNative(reflect.TypeOf(&structpb.Value{}))
+	if err != nil {
+		return nil, fmt.Errorf("result cannot be used as a value: %w", err)
+	}
+	return v.(*structpb.Value).AsInterface(), nil
+}
+
+// merge returns a copy of base with the values in override merged into it.
+// Nested maps are merged recursively; any other value in override replaces
+// the value in base.
+func merge(base, override map[string]any) map[string]any {
+	out := make(map[string]any, len(base))
+	for k, v := range base {
+		out[k] = v
+	}
+	for k, v := range override {
+		if v, ok := v.(map[string]any); ok {
+			if bv, ok := out[k].(map[string]any); ok {
+				out[k] = merge(bv, v)
+				continue
+			}
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// setPath sets the value at path in values, creating or replacing
+// intermediate maps as needed.
+func setPath(values map[string]any, path []string, v any) {
+	for _, key := range path[:len(path)-1] {
+		next, ok := values[key].(map[string]any)
+		if !ok {
+			next = map[string]any{}
+			values[key] = next
+		}
+		values = next
+	}
+	values[path[len(path)-1]] = v
+}
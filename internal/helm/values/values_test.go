@@ -0,0 +1,162 @@
+// Copyright 2026 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package values
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newTestCR() *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "example.com/v1alpha1",
+		"kind":       "Nginx",
+		"metadata": map[string]any{
+			"name":      "nginx-sample",
+			"namespace": "default",
+			"labels":    map[string]any{"tier": "frontend"},
+		},
+		"spec": map[string]any{
+			"replicaCount": int64(2),
+			"image":        map[string]any{"repository": "nginx"},
+		},
+		"status": map[string]any{"observedGeneration": int64(3)},
+	}}
+}
+
+func TestTransformValues(t *testing.T) {
+	defaultsFile := filepath.Join(t.TempDir(), "defaults.yaml")
+	require.NoError(t, os.WriteFile(defaultsFile, []byte(`
+replicaCount: 1
+image:
+  repository: busybox
+  tag: latest
+service:
+  port: 80
+`), 0o600))
+
+	reader := fake.NewClientBuilder().WithObjects(
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "nginx-values", Namespace: "default"},
+			Data:       map[string]string{DefaultKey: "service:\n  port: 8080\n  type: NodePort\n"},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "nginx-secret-values", Namespace: "default"},
+			Data:       map[string][]byte{"secret.yaml": []byte("service:\n  type: LoadBalancer\npassword: s3cr3t\n")},
+		},
+	).Build()
+
+	p, err := NewPipeline(Options{
+		DefaultsFile: defaultsFile,
+		From: []Reference{
+			{Kind: KindConfigMap, Name: "nginx-values"},
+			{Kind: KindSecret, Name: "nginx-secret-values", Key: "secret.yaml"},
+			{Kind: KindConfigMap, Name: "missing", Optional: true},
+		},
+		Expressions: []Expression{
+			{Path: "image.tag", CEL: `object.metadata.labels["tier"] == "frontend" ? "stable" : "latest"`},
+			{Path: "podLabels", CEL: `{"tier": object.metadata.labels["tier"]}`},
+			{Path: "fullnameOverride", Template: `{{ .metadata.name }}-{{ .status.observedGeneration }}`},
+		},
+	}, reader)
+	require.NoError(t, err)
+
+	cr := newTestCR()
+	spec := cr.Object["spec"].(map[string]any)
+	values, err := p.TransformValues(context.TODO(), cr, cr.GetNamespace(), spec)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"replicaCount":     int64(2),
+		"image":            map[string]any{"repository": "nginx", "tag": "stable"},
+		"service":          map[string]any{"port": float64(8080), "type": "LoadBalancer"},
+		"password":         "s3cr3t",
+		"podLabels":        map[string]any{"tier": "frontend"},
+		"fullnameOverride": "nginx-sample-3",
+	}, values)
+
+	// The custom resource is not modified.
+	assert.Equal(t, newTestCR(), cr)
+}
+
+func TestTransformValuesErrors(t *testing.T) {
+	reader := fake.NewClientBuilder().WithObjects(
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "nginx-values", Namespace: "default"},
+			Data:       map[string]string{"other.yaml": "a: b\n"},
+		},
+	).Build()
+	cr := newTestCR()
+
+	testCases := []struct {
+		name      string
+		opts      Options
+		expectErr string
+	}{
+		{
+			name:      "missing reference",
+			opts:      Options{From: []Reference{{Kind: KindConfigMap, Name: "missing"}}},
+			expectErr: SourceLabel,
+		},
+		{
+			name:      "missing key",
+			opts:      Options{From: []Reference{{Kind: KindConfigMap, Name: "nginx-values"}}},
+			expectErr: `has no key "values.yaml"`,
+		},
+		{
+			name:      "missing field in CEL expression",
+			opts:      Options{Expressions: []Expression{{Path: "a", CEL: "object.spec.missing"}}},
+			expectErr: `failed to evaluate values expression for "a"`,
+		},
+		{
+			name:      "missing field in template",
+			opts:      Options{Expressions: []Expression{{Path: "a", Template: "{{ .spec.missing }}"}}},
+			expectErr: `failed to evaluate values expression for "a"`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := NewPipeline(tc.opts, reader)
+			require.NoError(t, err)
+			_, err = p.TransformValues(context.TODO(), cr, cr.GetNamespace(), cr.Object["spec"].(map[string]any))
+			assert.ErrorContains(t, err, tc.expectErr)
+		})
+	}
+}
+
+func TestOptionsValidate(t *testing.T) {
+	assert.NoError(t, Options{}.Validate())
+	assert.NoError(t, Options{
+		From:        []Reference{{Kind: KindSecret, Name: "values"}},
+		Expressions: []Expression{{Path: "a.b", CEL: "object.metadata.name"}},
+	}.Validate())
+
+	assert.Error(t, Options{DefaultsFile: "missing.yaml"}.Validate())
+	assert.Error(t, Options{From: []Reference{{Kind: "Pod", Name: "values"}}}.Validate())
+	assert.Error(t, Options{From: []Reference{{Kind: KindConfigMap}}}.Validate())
+	assert.Error(t, Options{Expressions: []Expression{{CEL: "object.metadata.name"}}}.Validate())
+	assert.Error(t, Options{Expressions: []Expression{{Path: "a"}}}.Validate())
+	assert.Error(t, Options{Expressions: []Expression{{Path: "a", CEL: "1", Template: "1"}}}.Validate())
+	assert.Error(t, Options{Expressions: []Expression{{Path: "a", CEL: "object.metadata.name +"}}}.Validate())
+	assert.Error(t, Options{Expressions: []Expression{{Path: "a", Template: "{{ .metadata.name"}}}.Validate())
+}
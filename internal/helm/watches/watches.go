@@ -20,13 +20,24 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"slices"
+	"strings"
 	"text/template"
 
 	sprig "github.com/go-task/slim-sprig"
 	"helm.sh/helm/v3/pkg/chartutil"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"sigs.k8s.io/yaml"
+
+	"github.com/operator-framework/operator-sdk/internal/helm/chartsource"
+	"github.com/operator-framework/operator-sdk/internal/helm/internal/types"
+	"github.com/operator-framework/operator-sdk/internal/helm/maintenance"
+	"github.com/operator-framework/operator-sdk/internal/helm/postrenderer"
+	"github.com/operator-framework/operator-sdk/internal/helm/queue"
+	"github.com/operator-framework/operator-sdk/internal/helm/storage"
+	"github.com/operator-framework/operator-sdk/internal/helm/values"
 )
 
 const WatchesFile = "watches.yaml"
@@ -35,12 +46,65 @@ const WatchesFile = "watches.yaml"
 // custom resource.
 type Watch struct {
 	schema.GroupVersionKind `json:",inline"`
-	ChartDir                string               `json:"chart"`
-	WatchDependentResources *bool                `json:"watchDependentResources,omitempty"`
-	OverrideValues          map[string]string    `json:"overrideValues,omitempty"`
-	Selector                metav1.LabelSelector `json:"selector"`
-	ReconcilePeriod         metav1.Duration      `json:"reconcilePeriod,omitempty"`
-	DryRunOption            string               `json:"dryRunOption,omitempty"`
+	ChartDir                string                `json:"chart"`
+	ChartRepo               string                `json:"chartRepo,omitempty"`
+	ChartVersion            string                `json:"chartVersion,omitempty"`
+	ChartDigest             string                `json:"chartDigest,omitempty"`
+	ChartPullSecret         string                `json:"chartPullSecret,omitempty"`
+	ChartPlainHTTP          bool                  `json:"chartPlainHTTP,omitempty"`
+	WatchDependentResources *bool                 `json:"watchDependentResources,omitempty"`
+	OverrideValues          map[string]string     `json:"overrideValues,omitempty"`
+	Selector                metav1.LabelSelector  `json:"selector"`
+	ReconcilePeriod         metav1.Duration       `json:"reconcilePeriod,omitempty"`
+	DryRunOption            string                `json:"dryRunOption,omitempty"`
+	ServerSideApply         *bool                 `json:"serverSideApply,omitempty"`
+	ForceConflicts          *bool                 `json:"forceConflicts,omitempty"`
+	Test                    TestOptions           `json:"test,omitempty"`
+	Wait                    WaitOptions           `json:"wait,omitempty"`
+	Values                  values.Options        `json:"values,omitempty"`
+	MaxHistory              *int                  `json:"maxHistory,omitempty"`
+	ManifestStorage         types.ManifestStorage `json:"manifestStorage,omitempty"`
+	MaintenanceWindow       *maintenance.Window   `json:"maintenanceWindow,omitempty"`
+	ReleaseName             string                `json:"releaseName,omitempty"`
+	ReleaseNamespace        string                `json:"releaseNamespace,omitempty"`
+	ReleaseNamespaceFrom    string                `json:"releaseNamespaceFrom,omitempty"`
+	ReleaseStorage          storage.Backend       `json:"releaseStorage,omitempty"`
+	AdoptReleases           bool                  `json:"adoptReleases,omitempty"`
+	MaxConcurrentReconciles *int                  `json:"maxConcurrentReconciles,omitempty"`
+	RateLimiter             *queue.RateLimiter    `json:"rateLimiter,omitempty"`
+	Priorities              []queue.PriorityRule  `json:"priorities,omitempty"`
+	PostRenderer            *postrenderer.Options `json:"postRenderer,omitempty"`
+}
+
+// ReleaseNaming returns the configuration of the names and namespaces of the
+// releases of the custom resources of w.
+func (w Watch) ReleaseNaming() (types.ReleaseNaming, error) {
+	return types.NewReleaseNaming(w.ReleaseName, w.ReleaseNamespace, w.ReleaseNamespaceFrom)
+}
+
+// Prioritizer returns the prioritizer of the reconciliations of the custom
+// resources of w, or nil if w has no priority rules.
+func (w Watch) Prioritizer() (*queue.Prioritizer, error) {
+	if len(w.Priorities) == 0 {
+		return nil, nil
+	}
+	return queue.NewPrioritizer(w.Priorities)
+}
+
+// WaitOptions configures waiting for the resources of a release to become
+// ready after it is installed or upgraded.
+type WaitOptions struct {
+	Enabled bool            `json:"enabled,omitempty"`
+	Jobs    bool            `json:"jobs,omitempty"`
+	Timeout metav1.Duration `json:"timeout,omitempty"`
+}
+
+// TestOptions configures running the chart's test hooks after each install
+// and upgrade of a release.
+type TestOptions struct {
+	Enabled           bool            `json:"enabled,omitempty"`
+	Timeout           metav1.Duration `json:"timeout,omitempty"`
+	RollbackOnFailure bool            `json:"rollbackOnFailure,omitempty"`
 }
 
 // UnmarshalYAML unmarshals an individual watch from the Helm watches.yaml file
@@ -104,10 +168,82 @@ func LoadReader(reader io.Reader) ([]Watch, error) {
 			return nil, fmt.Errorf("invalid GVK: %s: %w", gvk, err)
 		}
 
-		if _, err := chartutil.IsChartDir(w.ChartDir); err != nil {
+		if w.IsRemoteChart() {
+			if w.ChartDigest != "" {
+				if err := chartsource.ValidateDigest(w.ChartDigest); err != nil {
+					return nil, fmt.Errorf("invalid chart digest for %s: %w", gvk, err)
+				}
+			}
+		} else if _, err := chartutil.IsChartDir(w.ChartDir); err != nil {
 			return nil, fmt.Errorf("invalid chart directory %s: %w", w.ChartDir, err)
 		}
 
+		if w.MaxHistory != nil && *w.MaxHistory < 0 {
+			return nil, fmt.Errorf("invalid maxHistory for %s: must not be negative", gvk)
+		}
+
+		if w.Wait.Timeout.Duration < 0 {
+			return nil, fmt.Errorf("invalid wait timeout for %s: must not be negative", gvk)
+		}
+
+		switch w.ManifestStorage {
+		case "", types.ManifestStorageStatus, types.ManifestStorageRelease, types.ManifestStorageConfigMap:
+		default:
+			return nil, fmt.Errorf("invalid manifestStorage %q for %s: must be one of %q, %q or %q", w.ManifestStorage,
+				gvk, types.ManifestStorageStatus, types.ManifestStorageRelease, types.ManifestStorageConfigMap)
+		}
+
+		if w.MaintenanceWindow != nil {
+			if err := w.MaintenanceWindow.Validate(); err != nil {
+				return nil, fmt.Errorf("invalid maintenanceWindow for %s: %w", gvk, err)
+			}
+		}
+
+		if _, err := w.ReleaseNaming(); err != nil {
+			return nil, fmt.Errorf("invalid release naming for %s: %w", gvk, err)
+		}
+		if w.ReleaseName != "" && !strings.Contains(w.ReleaseName, "{{") {
+			if err := chartutil.ValidateReleaseName(w.ReleaseName); err != nil {
+				return nil, fmt.Errorf("invalid releaseName %q for %s: %w", w.ReleaseName, gvk, err)
+			}
+		}
+		if w.ReleaseNamespace != "" && !strings.Contains(w.ReleaseNamespace, "{{") {
+			if errs := validation.IsDNS1123Label(w.ReleaseNamespace); len(errs) > 0 {
+				return nil, fmt.Errorf("invalid releaseNamespace %q for %s: %s", w.ReleaseNamespace, gvk,
+					strings.Join(errs, ", "))
+			}
+		}
+		if w.ReleaseNamespaceFrom != "" && slices.Contains(strings.Split(w.ReleaseNamespaceFrom, "."), "") {
+			return nil, fmt.Errorf("invalid releaseNamespaceFrom %q for %s: must be a dot-separated field path",
+				w.ReleaseNamespaceFrom, gvk)
+		}
+
+		if err := w.ReleaseStorage.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid releaseStorage for %s: %w", gvk, err)
+		}
+
+		if w.MaxConcurrentReconciles != nil && *w.MaxConcurrentReconciles <= 0 {
+			return nil, fmt.Errorf("invalid maxConcurrentReconciles for %s: must be positive", gvk)
+		}
+		if w.RateLimiter != nil {
+			if err := w.RateLimiter.Validate(); err != nil {
+				return nil, fmt.Errorf("invalid rateLimiter for %s: %w", gvk, err)
+			}
+		}
+		if _, err := w.Prioritizer(); err != nil {
+			return nil, fmt.Errorf("invalid priorities for %s: %w", gvk, err)
+		}
+
+		if w.PostRenderer != nil {
+			if err := w.PostRenderer.Validate(); err != nil {
+				return nil, fmt.Errorf("invalid postRenderer for %s: %w", gvk, err)
+			}
+		}
+
+		if err := w.Values.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid values options for %s: %w", gvk, err)
+		}
+
 		if _, ok := watchesMap[gvk]; ok {
 			return nil, fmt.Errorf("duplicate GVK: %s", gvk)
 		}
@@ -125,6 +261,24 @@ func LoadReader(reader io.Reader) ([]Watch, error) {
 	return watches, nil
 }
 
+// IsRemoteChart returns true if the watch references a chart in an OCI
+// registry or a chart repository, which must be fetched before use.
+func (w Watch) IsRemoteChart() bool {
+	return chartsource.IsRemote(w.ChartDir, w.ChartRepo)
+}
+
+// ChartSource returns the source of the watch's remote chart.
+func (w Watch) ChartSource() chartsource.Source {
+	return chartsource.Source{
+		Chart:      w.ChartDir,
+		Repo:       w.ChartRepo,
+		Version:    w.ChartVersion,
+		Digest:     w.ChartDigest,
+		PullSecret: w.ChartPullSecret,
+		PlainHTTP:  w.ChartPlainHTTP,
+	}
+}
+
 func expandOverrideValues(in map[string]string) (map[string]string, error) {
 	if in == nil {
 		return nil, nil
@@ -18,13 +18,23 @@ import (
 	"bytes"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/operator-framework/operator-sdk/internal/helm/internal/types"
+	"github.com/operator-framework/operator-sdk/internal/helm/maintenance"
+	"github.com/operator-framework/operator-sdk/internal/helm/postrenderer"
+	"github.com/operator-framework/operator-sdk/internal/helm/queue"
+	"github.com/operator-framework/operator-sdk/internal/helm/storage"
+	"github.com/operator-framework/operator-sdk/internal/helm/values"
 )
 
 func TestLoadReader(t *testing.T) {
 	trueVal, falseVal := true, false
+	maxHistory, four := 5, 4
 	testCases := []struct {
 		name          string
 		data          string
@@ -125,6 +135,537 @@ func TestLoadReader(t *testing.T) {
 			},
 			expectErr: false,
 		},
+		{
+			name: "valid with server-side apply",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  serverSideApply: true
+  forceConflicts: false
+`,
+			expectWatches: []Watch{
+				{
+					GroupVersionKind:        schema.GroupVersionKind{Group: "mygroup", Version: "v1alpha1", Kind: "MyKind"},
+					ChartDir:                "../../../internal/plugins/helm/v1/chartutil/testdata/test-chart",
+					WatchDependentResources: &trueVal,
+					ServerSideApply:         &trueVal,
+					ForceConflicts:          &falseVal,
+				},
+			},
+			expectErr: false,
+		},
+		{
+			name: "valid with release tests",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  test:
+    enabled: true
+    timeout: 2m
+    rollbackOnFailure: true
+`,
+			expectWatches: []Watch{
+				{
+					GroupVersionKind:        schema.GroupVersionKind{Group: "mygroup", Version: "v1alpha1", Kind: "MyKind"},
+					ChartDir:                "../../../internal/plugins/helm/v1/chartutil/testdata/test-chart",
+					WatchDependentResources: &trueVal,
+					Test: TestOptions{
+						Enabled:           true,
+						Timeout:           metav1.Duration{Duration: 2 * time.Minute},
+						RollbackOnFailure: true,
+					},
+				},
+			},
+			expectErr: false,
+		},
+		{
+			name: "valid with wait",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  wait:
+    enabled: true
+    jobs: true
+    timeout: 10m
+`,
+			expectWatches: []Watch{
+				{
+					GroupVersionKind:        schema.GroupVersionKind{Group: "mygroup", Version: "v1alpha1", Kind: "MyKind"},
+					ChartDir:                "../../../internal/plugins/helm/v1/chartutil/testdata/test-chart",
+					WatchDependentResources: &trueVal,
+					Wait: WaitOptions{
+						Enabled: true,
+						Jobs:    true,
+						Timeout: metav1.Duration{Duration: 10 * time.Minute},
+					},
+				},
+			},
+			expectErr: false,
+		},
+		{
+			// Jobs are not waited for by default, and the run command applies
+			// the default timeout when it is unset.
+			name: "valid with wait defaults",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  wait:
+    enabled: true
+`,
+			expectWatches: []Watch{
+				{
+					GroupVersionKind:        schema.GroupVersionKind{Group: "mygroup", Version: "v1alpha1", Kind: "MyKind"},
+					ChartDir:                "../../../internal/plugins/helm/v1/chartutil/testdata/test-chart",
+					WatchDependentResources: &trueVal,
+					Wait:                    WaitOptions{Enabled: true},
+				},
+			},
+			expectErr: false,
+		},
+		{
+			name: "invalid wait timeout",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  wait:
+    enabled: true
+    timeout: forever
+`,
+			expectErr: true,
+		},
+		{
+			name: "negative wait timeout",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  wait:
+    enabled: true
+    timeout: -1m
+`,
+			expectErr: true,
+		},
+		{
+			name: "valid with oci chart",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: oci://registry.example.com/charts/test-chart
+  chartVersion: 1.2.3
+  chartDigest: sha256:0000000000000000000000000000000000000000000000000000000000000000
+  chartPullSecret: /etc/helm-operator/pull-secret
+`,
+			expectWatches: []Watch{
+				{
+					GroupVersionKind:        schema.GroupVersionKind{Group: "mygroup", Version: "v1alpha1", Kind: "MyKind"},
+					ChartDir:                "oci://registry.example.com/charts/test-chart",
+					ChartVersion:            "1.2.3",
+					ChartDigest:             "sha256:0000000000000000000000000000000000000000000000000000000000000000",
+					ChartPullSecret:         "/etc/helm-operator/pull-secret",
+					WatchDependentResources: &trueVal,
+				},
+			},
+			expectErr: false,
+		},
+		{
+			name: "valid with chart repository",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: test-chart
+  chartRepo: https://charts.example.com
+  chartVersion: 1.2.3
+`,
+			expectWatches: []Watch{
+				{
+					GroupVersionKind:        schema.GroupVersionKind{Group: "mygroup", Version: "v1alpha1", Kind: "MyKind"},
+					ChartDir:                "test-chart",
+					ChartRepo:               "https://charts.example.com",
+					ChartVersion:            "1.2.3",
+					WatchDependentResources: &trueVal,
+				},
+			},
+			expectErr: false,
+		},
+		{
+			name: "invalid chart digest",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: oci://registry.example.com/charts/test-chart:1.2.3
+  chartDigest: 1234
+`,
+			expectErr: true,
+		},
+		{
+			name: "valid with values pipeline",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  values:
+    from:
+    - kind: ConfigMap
+      name: my-values
+    expressions:
+    - path: image.tag
+      cel: object.metadata.labels["tier"]
+    - path: fullnameOverride
+      template: "{{ .metadata.name }}-app"
+`,
+			expectWatches: []Watch{
+				{
+					GroupVersionKind:        schema.GroupVersionKind{Group: "mygroup", Version: "v1alpha1", Kind: "MyKind"},
+					ChartDir:                "../../../internal/plugins/helm/v1/chartutil/testdata/test-chart",
+					WatchDependentResources: &trueVal,
+					Values: values.Options{
+						From: []values.Reference{{Kind: values.KindConfigMap, Name: "my-values"}},
+						Expressions: []values.Expression{
+							{Path: "image.tag", CEL: `object.metadata.labels["tier"]`},
+							{Path: "fullnameOverride", Template: "{{ .metadata.name }}-app"},
+						},
+					},
+				},
+			},
+			expectErr: false,
+		},
+		{
+			name: "valid with max history",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  maxHistory: 5
+  manifestStorage: configMap
+`,
+			expectWatches: []Watch{
+				{
+					GroupVersionKind:        schema.GroupVersionKind{Group: "mygroup", Version: "v1alpha1", Kind: "MyKind"},
+					ChartDir:                "../../../internal/plugins/helm/v1/chartutil/testdata/test-chart",
+					WatchDependentResources: &trueVal,
+					MaxHistory:              &maxHistory,
+					ManifestStorage:         types.ManifestStorageConfigMap,
+				},
+			},
+			expectErr: false,
+		},
+		{
+			name: "invalid max history",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  maxHistory: -1
+`,
+			expectErr: true,
+		},
+		{
+			name: "invalid manifest storage",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  manifestStorage: etcd
+`,
+			expectErr: true,
+		},
+		{
+			name: "valid with maintenance window",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  maintenanceWindow:
+    schedule: "0 22 * * sat"
+    duration: 4h
+    timeZone: Europe/Paris
+`,
+			expectWatches: []Watch{
+				{
+					GroupVersionKind:        schema.GroupVersionKind{Group: "mygroup", Version: "v1alpha1", Kind: "MyKind"},
+					ChartDir:                "../../../internal/plugins/helm/v1/chartutil/testdata/test-chart",
+					WatchDependentResources: &trueVal,
+					MaintenanceWindow: &maintenance.Window{
+						Schedule: "0 22 * * sat",
+						Duration: metav1.Duration{Duration: 4 * time.Hour},
+						TimeZone: "Europe/Paris",
+					},
+				},
+			},
+			expectErr: false,
+		},
+		{
+			name: "invalid maintenance window",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  maintenanceWindow:
+    schedule: "0 22 * *"
+    duration: 4h
+`,
+			expectErr: true,
+		},
+		{
+			name: "valid with release name and namespace",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  releaseName: "{{ .Name }}-{{ .Kind | lower }}"
+  releaseNamespace: my-releases
+  releaseNamespaceFrom: spec.targetNamespace
+`,
+			expectWatches: []Watch{
+				{
+					GroupVersionKind:        schema.GroupVersionKind{Group: "mygroup", Version: "v1alpha1", Kind: "MyKind"},
+					ChartDir:                "../../../internal/plugins/helm/v1/chartutil/testdata/test-chart",
+					WatchDependentResources: &trueVal,
+					ReleaseName:             "{{ .Name }}-{{ .Kind | lower }}",
+					ReleaseNamespace:        "my-releases",
+					ReleaseNamespaceFrom:    "spec.targetNamespace",
+				},
+			},
+			expectErr: false,
+		},
+		{
+			name: "invalid release namespace",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  releaseNamespace: My_Releases
+`,
+			expectErr: true,
+		},
+		{
+			name: "invalid release name template",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  releaseName: "{{ .Name "
+`,
+			expectErr: true,
+		},
+		{
+			name: "invalid release name",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  releaseName: My_Release
+`,
+			expectErr: true,
+		},
+		{
+			name: "invalid release namespace template",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  releaseNamespace: "{{ .Namespace | nosuchfunc }}"
+`,
+			expectErr: true,
+		},
+		{
+			name: "invalid release namespace field path",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  releaseNamespaceFrom: spec..targetNamespace
+`,
+			expectErr: true,
+		},
+		{
+			name: "valid with release storage and adoption",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  releaseStorage: configmap
+  adoptReleases: true
+`,
+			expectWatches: []Watch{
+				{
+					GroupVersionKind:        schema.GroupVersionKind{Group: "mygroup", Version: "v1alpha1", Kind: "MyKind"},
+					ChartDir:                "../../../internal/plugins/helm/v1/chartutil/testdata/test-chart",
+					WatchDependentResources: &trueVal,
+					ReleaseStorage:          storage.BackendConfigMap,
+					AdoptReleases:           true,
+				},
+			},
+			expectErr: false,
+		},
+		{
+			name: "invalid release storage",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  releaseStorage: etcd
+`,
+			expectErr: true,
+		},
+		{
+			name: "valid with concurrency, rate limiter and priorities",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  maxConcurrentReconciles: 4
+  rateLimiter:
+    baseDelay: 1s
+    maxDelay: 5m
+    qps: 5
+    burst: 20
+  priorities:
+  - selector:
+      matchLabels:
+        tier: critical
+    priority: 100
+  - newResources: true
+    priority: 10
+`,
+			expectWatches: []Watch{
+				{
+					GroupVersionKind:        schema.GroupVersionKind{Group: "mygroup", Version: "v1alpha1", Kind: "MyKind"},
+					ChartDir:                "../../../internal/plugins/helm/v1/chartutil/testdata/test-chart",
+					WatchDependentResources: &trueVal,
+					MaxConcurrentReconciles: &four,
+					RateLimiter: &queue.RateLimiter{
+						BaseDelay: metav1.Duration{Duration: time.Second},
+						MaxDelay:  metav1.Duration{Duration: 5 * time.Minute},
+						QPS:       5,
+						Burst:     20,
+					},
+					Priorities: []queue.PriorityRule{
+						{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "critical"}}, Priority: 100},
+						{NewResources: true, Priority: 10},
+					},
+				},
+			},
+			expectErr: false,
+		},
+		{
+			name: "invalid max concurrent reconciles",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  maxConcurrentReconciles: 0
+`,
+			expectErr: true,
+		},
+		{
+			name: "invalid rate limiter",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  rateLimiter:
+    baseDelay: 1m
+    maxDelay: 1s
+`,
+			expectErr: true,
+		},
+		{
+			name: "invalid priority rule",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  priorities:
+  - priority: 100
+`,
+			expectErr: true,
+		},
+		{
+			name: "valid with post-renderer",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  postRenderer:
+    exec:
+      command: /usr/local/bin/relabel
+      args: [--team, platform]
+`,
+			expectWatches: []Watch{
+				{
+					GroupVersionKind:        schema.GroupVersionKind{Group: "mygroup", Version: "v1alpha1", Kind: "MyKind"},
+					ChartDir:                "../../../internal/plugins/helm/v1/chartutil/testdata/test-chart",
+					WatchDependentResources: &trueVal,
+					PostRenderer: &postrenderer.Options{
+						Exec: &postrenderer.Exec{Command: "/usr/local/bin/relabel", Args: []string{"--team", "platform"}},
+					},
+				},
+			},
+			expectErr: false,
+		},
+		{
+			name: "invalid post-renderer",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  postRenderer:
+    kustomize:
+      dir: overlays/platform
+    exec:
+      command: /usr/local/bin/relabel
+`,
+			expectErr: true,
+		},
+		{
+			name: "invalid values expression",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  values:
+    expressions:
+    - path: image.tag
+      cel: object.metadata.labels[
+`,
+			expectErr: true,
+		},
 		{
 			name: "invalid with override template expansion",
 			data: `---
@@ -0,0 +1,303 @@
+// Copyright 2026 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chartutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/chart"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+// maxRefDepth limits the expansion of nested $ref keywords, which may be
+// recursive.
+const maxRefDepth = 10
+
+// unsupportedSchemaKeywords are JSON schema keywords that are not allowed, or
+// have no equivalent, in CRD schemas.
+var unsupportedSchemaKeywords = []string{
+	"$schema", "$id", "id", "$comment", "$defs", "definitions", "examples", "readOnly", "writeOnly",
+	"dependencies", "dependentRequired", "dependentSchemas", "additionalItems", "patternProperties",
+	"propertyNames", "contains", "if", "then", "else", "contentEncoding", "contentMediaType",
+	// Combinators are dropped since their subschemas cannot set types in
+	// structural schemas, and defaults are dropped so that chart defaults are
+	// not persisted in custom resources.
+	"allOf", "anyOf", "oneOf", "not", "default",
+	// Required values may be set by the chart's values.yaml rather than the
+	// custom resource. Helm validates them against the merged values.
+	"required",
+}
+
+// supportedFormats are the formats validated by the Kubernetes API server.
+var supportedFormats = map[string]bool{
+	"bsonobjectid": true, "uri": true, "email": true, "hostname": true, "ipv4": true, "ipv6": true,
+	"cidr": true, "mac": true, "uuid": true, "uuid3": true, "uuid4": true, "uuid5": true,
+	"isbn": true, "isbn10": true, "isbn13": true, "creditcard": true, "ssn": true, "hexcolor": true,
+	"rgbcolor": true, "byte": true, "password": true, "date": true, "duration": true, "datetime": true,
+	"date-time": true, "int32": true, "int64": true, "float": true, "double": true,
+}
+
+// SpecSchema returns the OpenAPI v3 schema of the spec of custom resources
+// reconciled with chrt. The schema is converted from the chart's
+// values.schema.json if it has one, or inferred from the types of the
+// chart's default values otherwise.
+func SpecSchema(chrt *chart.Chart) (*apiextv1.JSONSchemaProps, error) {
+	if len(chrt.Schema) > 0 {
+		schema, err := ConvertValuesSchema(chrt.Schema)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert values.schema.json of chart %q: %w", chrt.Name(), err)
+		}
+		return schema, nil
+	}
+	return InferValuesSchema(chrt.Values), nil
+}
+
+// ConvertValuesSchema converts a values.schema.json JSON schema to a
+// structural OpenAPI v3 schema usable in a CRD. Keywords not supported in CRD
+// schemas are dropped, local references are expanded, and objects that allow
+// additional properties preserve unknown fields.
+func ConvertValuesSchema(data []byte) (*apiextv1.JSONSchemaProps, error) {
+	var root map[string]any
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	normalized, err := normalizeSchema(root, root, 0)
+	if err != nil {
+		return nil, err
+	}
+	b, err := json.Marshal(normalized)
+	if err != nil {
+		return nil, err
+	}
+	schema := &apiextv1.JSONSchemaProps{}
+	if err := json.Unmarshal(b, schema); err != nil {
+		return nil, err
+	}
+	if schema.Type == "" && !isPreserved(schema) {
+		schema.Type = "object"
+	}
+	return schema, nil
+}
+
+func normalizeSchema(schema, root map[string]any, depth int) (map[string]any, error) {
+	if ref, ok := schema["$ref"].(string); ok {
+		if depth >= maxRefDepth {
+			return map[string]any{"x-kubernetes-preserve-unknown-fields": true}, nil
+		}
+		resolved, err := resolveRef(ref, root)
+		if err != nil {
+			return nil, err
+		}
+		// Keywords next to $ref, such as a description, take precedence.
+		merged := make(map[string]any, len(resolved)+len(schema))
+		for k, v := range resolved {
+			merged[k] = v
+		}
+		for k, v := range schema {
+			if k != "$ref" {
+				merged[k] = v
+			}
+		}
+		return normalizeSchema(merged, root, depth+1)
+	}
+
+	out := make(map[string]any, len(schema))
+	for k, v := range schema {
+		out[k] = v
+	}
+	for _, k := range unsupportedSchemaKeywords {
+		delete(out, k)
+	}
+	if format, ok := out["format"].(string); ok && !supportedFormats[format] {
+		delete(out, "format")
+	}
+	if unique, ok := out["uniqueItems"].(bool); ok && unique {
+		// uniqueItems: true is not allowed in CRD schemas.
+		delete(out, "uniqueItems")
+	}
+	if c, ok := out["const"]; ok {
+		out["enum"] = []any{c}
+		delete(out, "const")
+	}
+	normalizeType(out)
+
+	if props, ok := out["properties"].(map[string]any); ok {
+		normalizedProps := make(map[string]any, len(props))
+		for name, prop := range props {
+			propSchema, ok := prop.(map[string]any)
+			if !ok {
+				// A boolean schema allows any value.
+				propSchema = map[string]any{}
+			}
+			p, err := normalizeSchema(propSchema, root, depth)
+			if err != nil {
+				return nil, err
+			}
+			normalizedProps[name] = p
+		}
+		out["properties"] = normalizedProps
+	}
+
+	switch items := out["items"].(type) {
+	case map[string]any:
+		i, err := normalizeSchema(items, root, depth)
+		if err != nil {
+			return nil, err
+		}
+		out["items"] = i
+	case []any:
+		// Tuples are not supported, so items of any type are allowed.
+		out["items"] = map[string]any{"x-kubernetes-preserve-unknown-fields": true}
+	case nil:
+		if out["type"] == "array" {
+			out["items"] = map[string]any{"x-kubernetes-preserve-unknown-fields": true}
+		}
+	default:
+		out["items"] = map[string]any{"x-kubernetes-preserve-unknown-fields": true}
+	}
+
+	additional, hasAdditional := out["additionalProperties"]
+	_, hasProps := out["properties"]
+	switch additional := additional.(type) {
+	case bool:
+		delete(out, "additionalProperties")
+		if additional && !hasProps {
+			out["x-kubernetes-preserve-unknown-fields"] = true
+		}
+	case map[string]any:
+		if hasProps {
+			// properties and additionalProperties are mutually exclusive.
+			delete(out, "additionalProperties")
+		} else {
+			a, err := normalizeSchema(additional, root, depth)
+			if err != nil {
+				return nil, err
+			}
+			out["additionalProperties"] = a
+		}
+	}
+	// JSON schema allows additional properties unless stated otherwise.
+	if out["type"] == "object" && (!hasAdditional || hasProps && additional != false) {
+		if _, ok := out["additionalProperties"]; !ok {
+			out["x-kubernetes-preserve-unknown-fields"] = true
+		}
+	}
+	if _, ok := out["type"]; !ok {
+		if _, ok := out["x-kubernetes-int-or-string"]; !ok {
+			out["x-kubernetes-preserve-unknown-fields"] = true
+		}
+	}
+	return out, nil
+}
+
+// normalizeType converts a list of types to a single type, which is all CRD
+// schemas support.
+func normalizeType(schema map[string]any) {
+	types, ok := schema["type"].([]any)
+	if !ok {
+		return
+	}
+	delete(schema, "type")
+	var nonNull []string
+	for _, t := range types {
+		if s, ok := t.(string); ok {
+			if s == "null" {
+				schema["nullable"] = true
+				continue
+			}
+			nonNull = append(nonNull, s)
+		}
+	}
+	sort.Strings(nonNull)
+	switch {
+	case len(nonNull) == 1:
+		schema["type"] = nonNull[0]
+	case len(nonNull) == 2 && nonNull[0] == "integer" && nonNull[1] == "string":
+		schema["x-kubernetes-int-or-string"] = true
+	}
+}
+
+func resolveRef(ref string, root map[string]any) (map[string]any, error) {
+	path, ok := strings.CutPrefix(ref, "#/")
+	if !ok {
+		return nil, fmt.Errorf("unsupported $ref %q: only local references are supported", ref)
+	}
+	var cur any = root
+	for _, part := range strings.Split(path, "/") {
+		part = strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("invalid $ref %q", ref)
+		}
+		if cur, ok = m[part]; !ok {
+			return nil, fmt.Errorf("invalid $ref %q: %q not found", ref, part)
+		}
+	}
+	resolved, ok := cur.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("invalid $ref %q: not a schema", ref)
+	}
+	return resolved, nil
+}
+
+func isPreserved(schema *apiextv1.JSONSchemaProps) bool {
+	return schema.XPreserveUnknownFields != nil && *schema.XPreserveUnknownFields
+}
+
+// InferValuesSchema infers an OpenAPI v3 schema from the types of values.
+// Since default values rarely list every supported value, inferred objects
+// preserve unknown fields, and empty objects, empty lists and null values
+// accept any value.
+func InferValuesSchema(values map[string]any) *apiextv1.JSONSchemaProps {
+	return inferSchema(values)
+}
+
+func inferSchema(v any) *apiextv1.JSONSchemaProps {
+	preserve := true
+	switch v := v.(type) {
+	case map[string]any:
+		schema := &apiextv1.JSONSchemaProps{Type: "object", XPreserveUnknownFields: &preserve}
+		if len(v) > 0 {
+			schema.Properties = make(map[string]apiextv1.JSONSchemaProps, len(v))
+			for k, val := range v {
+				schema.Properties[k] = *inferSchema(val)
+			}
+		}
+		return schema
+	case []any:
+		items := &apiextv1.JSONSchemaProps{XPreserveUnknownFields: &preserve}
+		if len(v) > 0 {
+			items = inferSchema(v[0])
+		}
+		return &apiextv1.JSONSchemaProps{Type: "array", Items: &apiextv1.JSONSchemaPropsOrArray{Schema: items}}
+	case string:
+		return &apiextv1.JSONSchemaProps{Type: "string"}
+	case bool:
+		return &apiextv1.JSONSchemaProps{Type: "boolean"}
+	case int, int32, int64:
+		return &apiextv1.JSONSchemaProps{Type: "integer"}
+	case float64:
+		if v == math.Trunc(v) {
+			return &apiextv1.JSONSchemaProps{Type: "integer"}
+		}
+		return &apiextv1.JSONSchemaProps{Type: "number"}
+	default:
+		return &apiextv1.JSONSchemaProps{XPreserveUnknownFields: &preserve}
+	}
+}
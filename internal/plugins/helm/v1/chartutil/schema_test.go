@@ -0,0 +1,179 @@
+// Copyright 2026 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chartutil_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chart"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/operator-framework/operator-sdk/internal/plugins/helm/v1/chartutil"
+)
+
+func TestConvertValuesSchema(t *testing.T) {
+	schema, err := chartutil.ConvertValuesSchema([]byte(`{
+  "$schema": "https://json-schema.org/draft-07/schema#",
+  "type": "object",
+  "additionalProperties": false,
+  "required": ["image"],
+  "definitions": {
+    "port": {"type": "integer", "minimum": 1, "maximum": 65535}
+  },
+  "properties": {
+    "replicaCount": {"type": "integer", "default": 1, "minimum": 0},
+    "image": {
+      "type": "object",
+      "required": ["repository"],
+      "properties": {
+        "repository": {"type": "string", "format": "docker-image"},
+        "pullPolicy": {"type": "string", "enum": ["Always", "IfNotPresent", "Never"]}
+      }
+    },
+    "service": {
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {
+        "port": {"$ref": "#/definitions/port", "description": "Service port"},
+        "targetPort": {"type": ["integer", "string"]}
+      }
+    },
+    "nodeSelector": {"type": "object", "additionalProperties": {"type": "string"}},
+    "podAnnotations": {"type": ["object", "null"], "additionalProperties": true},
+    "tolerations": {"type": "array", "uniqueItems": true},
+    "mode": {"const": "standalone"},
+    "extra": {"oneOf": [{"type": "string"}, {"type": "integer"}]}
+  }
+}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, &apiextv1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]apiextv1.JSONSchemaProps{
+			"replicaCount": {Type: "integer", Minimum: ptr.To(0.0)},
+			"image": {
+				Type:                   "object",
+				XPreserveUnknownFields: ptr.To(true),
+				Properties: map[string]apiextv1.JSONSchemaProps{
+					"repository": {Type: "string"},
+					"pullPolicy": {Type: "string", Enum: []apiextv1.JSON{
+						{Raw: []byte(`"Always"`)}, {Raw: []byte(`"IfNotPresent"`)}, {Raw: []byte(`"Never"`)},
+					}},
+				},
+			},
+			"service": {
+				Type: "object",
+				Properties: map[string]apiextv1.JSONSchemaProps{
+					"port":       {Type: "integer", Description: "Service port", Minimum: ptr.To(1.0), Maximum: ptr.To(65535.0)},
+					"targetPort": {XIntOrString: true},
+				},
+			},
+			"nodeSelector": {
+				Type:                 "object",
+				AdditionalProperties: &apiextv1.JSONSchemaPropsOrBool{Allows: true, Schema: &apiextv1.JSONSchemaProps{Type: "string"}},
+			},
+			"podAnnotations": {Type: "object", Nullable: true, XPreserveUnknownFields: ptr.To(true)},
+			"tolerations": {
+				Type:  "array",
+				Items: &apiextv1.JSONSchemaPropsOrArray{Schema: &apiextv1.JSONSchemaProps{XPreserveUnknownFields: ptr.To(true)}},
+			},
+			"mode":  {Enum: []apiextv1.JSON{{Raw: []byte(`"standalone"`)}}, XPreserveUnknownFields: ptr.To(true)},
+			"extra": {XPreserveUnknownFields: ptr.To(true)},
+		},
+	}, schema)
+}
+
+func TestConvertValuesSchemaErrors(t *testing.T) {
+	_, err := chartutil.ConvertValuesSchema([]byte(`{"properties": {"a": {"$ref": "https://example.com/schema.json"}}}`))
+	assert.ErrorContains(t, err, "only local references are supported")
+
+	_, err = chartutil.ConvertValuesSchema([]byte(`{"properties": {"a": {"$ref": "#/definitions/missing"}}}`))
+	assert.ErrorContains(t, err, `"definitions" not found`)
+
+	_, err = chartutil.ConvertValuesSchema([]byte(`{`))
+	assert.Error(t, err)
+}
+
+func TestConvertValuesSchemaRecursiveRef(t *testing.T) {
+	schema, err := chartutil.ConvertValuesSchema([]byte(`{
+  "type": "object",
+  "definitions": {
+    "node": {"type": "object", "additionalProperties": false, "properties": {"child": {"$ref": "#/definitions/node"}}}
+  },
+  "properties": {"root": {"$ref": "#/definitions/node"}}
+}`))
+	require.NoError(t, err)
+
+	depth := 0
+	for node := schema.Properties["root"]; len(node.Properties) > 0; node = node.Properties["child"] {
+		depth++
+	}
+	assert.Equal(t, 10, depth)
+}
+
+func TestSpecSchema(t *testing.T) {
+	schema, err := chartutil.SpecSchema(&chart.Chart{
+		Metadata: &chart.Metadata{Name: "test-chart"},
+		Values: map[string]any{
+			"replicaCount": float64(1),
+			"ratio":        0.5,
+			"image":        map[string]any{"repository": "nginx", "tag": ""},
+			"ports":        []any{float64(80)},
+			"tolerations":  []any{},
+			"resources":    map[string]any{},
+			"enabled":      true,
+			"nameOverride": nil,
+		},
+	})
+	require.NoError(t, err)
+
+	preserve := ptr.To(true)
+	assert.Equal(t, &apiextv1.JSONSchemaProps{
+		Type:                   "object",
+		XPreserveUnknownFields: preserve,
+		Properties: map[string]apiextv1.JSONSchemaProps{
+			"replicaCount": {Type: "integer"},
+			"ratio":        {Type: "number"},
+			"image": {
+				Type:                   "object",
+				XPreserveUnknownFields: preserve,
+				Properties: map[string]apiextv1.JSONSchemaProps{
+					"repository": {Type: "string"},
+					"tag":        {Type: "string"},
+				},
+			},
+			"ports":        {Type: "array", Items: &apiextv1.JSONSchemaPropsOrArray{Schema: &apiextv1.JSONSchemaProps{Type: "integer"}}},
+			"tolerations":  {Type: "array", Items: &apiextv1.JSONSchemaPropsOrArray{Schema: &apiextv1.JSONSchemaProps{XPreserveUnknownFields: preserve}}},
+			"resources":    {Type: "object", XPreserveUnknownFields: preserve},
+			"enabled":      {Type: "boolean"},
+			"nameOverride": {XPreserveUnknownFields: preserve},
+		},
+	}, schema)
+
+	// The values schema takes precedence over inference.
+	schema, err = chartutil.SpecSchema(&chart.Chart{
+		Metadata: &chart.Metadata{Name: "test-chart"},
+		Values:   map[string]any{"replicaCount": float64(1)},
+		Schema:   []byte(`{"type": "object", "additionalProperties": false, "properties": {"replicaCount": {"type": "string"}}}`),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &apiextv1.JSONSchemaProps{
+		Type:       "object",
+		Properties: map[string]apiextv1.JSONSchemaProps{"replicaCount": {Type: "string"}},
+	}, schema)
+}
@@ -91,7 +91,7 @@ func (s *apiScaffolder) Scaffold() error {
 
 	if err := scaffold.Execute(
 		&templates.WatchesUpdater{ChartPath: chartPath},
-		&crd.CRD{},
+		&crd.CRD{Chart: s.chrt},
 		&crd.Kustomization{},
 		&rbac.ManagerRoleUpdater{Chart: s.chrt},
 		&samples.CustomResource{ChartPath: chartPath, Chart: s.chrt},
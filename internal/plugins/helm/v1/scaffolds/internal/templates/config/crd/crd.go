@@ -17,9 +17,15 @@ package crd
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 
 	"github.com/kr/text"
+	"helm.sh/helm/v3/pkg/chart"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"sigs.k8s.io/kubebuilder/v4/pkg/machinery"
+	"sigs.k8s.io/yaml"
+
+	"github.com/operator-framework/operator-sdk/internal/plugins/helm/v1/chartutil"
 )
 
 var _ machinery.Template = &CRD{}
@@ -28,6 +34,12 @@ var _ machinery.Template = &CRD{}
 type CRD struct {
 	machinery.TemplateMixin
 	machinery.ResourceMixin
+
+	// Chart is the chart whose values define the schema of the spec.
+	Chart *chart.Chart
+
+	// SpecSchema is the rendered schema of the spec.
+	SpecSchema string
 }
 
 // SetTemplateDefaults implements machinery.Template
@@ -39,6 +51,17 @@ func (f *CRD) SetTemplateDefaults() error {
 
 	f.IfExistsAction = machinery.Error
 
+	// The schema is indented by 4 more spaces for v1 CRDs, see below.
+	indent := 10
+	if f.Resource.API.CRDVersion == "v1" {
+		indent = 12
+	}
+	specSchema, err := renderSpecSchema(f.Chart, f.Resource.Kind, indent)
+	if err != nil {
+		return err
+	}
+	f.SpecSchema = specSchema
+
 	f.TemplateBody = fmt.Sprintf(crdTemplate,
 		text.Indent(openAPIV3SchemaTemplate, "    "),
 		text.Indent(openAPIV3SchemaTemplate, "      "),
@@ -47,6 +70,27 @@ func (f *CRD) SetTemplateDefaults() error {
 	return nil
 }
 
+// renderSpecSchema renders the schema of the spec of custom resources of kind
+// reconciled with chrt as YAML, starting with a newline and indented by indent
+// spaces. If chrt is nil, the spec preserves unknown fields.
+func renderSpecSchema(chrt *chart.Chart, kind string, indent int) (string, error) {
+	preserve := true
+	schema := &apiextv1.JSONSchemaProps{Type: "object", XPreserveUnknownFields: &preserve}
+	if chrt != nil {
+		var err error
+		if schema, err = chartutil.SpecSchema(chrt); err != nil {
+			return "", err
+		}
+	}
+	schema.Description = fmt.Sprintf("Spec defines the desired state of %s", kind)
+
+	b, err := yaml.Marshal(schema)
+	if err != nil {
+		return "", err
+	}
+	return "\n" + strings.TrimRight(text.Indent(string(b), strings.Repeat(" ", indent)), "\n"), nil
+}
+
 const crdTemplate = `---
 apiVersion: apiextensions.k8s.io/{{ .Resource.API.CRDVersion }}
 kind: CustomResourceDefinition
@@ -95,10 +139,7 @@ const openAPIV3SchemaTemplate = `openAPIV3Schema:
       type: string
     metadata:
       type: object
-    spec:
-      description: Spec defines the desired state of {{ .Resource.Kind }}
-      type: object
-      x-kubernetes-preserve-unknown-fields: true
+    spec:{{ .SpecSchema }}
     status:
       description: Status defines the observed state of {{ .Resource.Kind }}
       type: object